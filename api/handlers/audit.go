@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	auditService services.AuditService
+}
+
+func NewAuditHandler(auditService services.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// List returns audit entries, newest first, filtered by actor_id, action,
+// target_type, target_id, since and until.
+func (h *AuditHandler) List(c *gin.Context) {
+	var query ListAuditQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(services.ErrValidationFailed.Wrap(err))
+		return
+	}
+	filter, err := services.ParseAuditQuery(c.Request.URL.Query())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	page, err := h.auditService.ListEntries(c.Request.Context(), services.AuditPageQuery{
+		Query:  filter,
+		Before: query.Before,
+		Limit:  query.Limit,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	pagination := map[string]interface{}{
+		"per_page": page.Limit,
+		"has_more": page.NextBefore != 0,
+	}
+	if page.NextBefore != 0 {
+		pagination["next_before"] = page.NextBefore
+
+		next := *c.Request.URL
+		q := next.Query()
+		q.Set("before", strconv.FormatUint(uint64(page.NextBefore), 10))
+		next.RawQuery = q.Encode()
+		c.Header("Link", "<"+next.RequestURI()+`>; rel="next"`)
+	}
+
+	utils.SuccessResponse(c, map[string]interface{}{
+		"entries":    page.Entries,
+		"pagination": pagination,
+	}, "Audit entries fetched successfully")
+}
+
+// Verify checks the audit log's hash chain
+func (h *AuditHandler) Verify(c *gin.Context) {
+	result, err := h.auditService.VerifyAuditChain(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	message := "Audit chain is intact"
+	if !result.Valid {
+		message = "Audit chain is broken"
+	}
+	utils.SuccessResponse(c, result, message)
+}
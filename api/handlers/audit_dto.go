@@ -0,0 +1,9 @@
+package handlers
+
+// ListAuditQuery holds the paging parameters of GET /api/audit; the filters
+// are parsed by services.ParseAuditQuery. Before is the next_before value of
+// the previous page.
+type ListAuditQuery struct {
+	Limit  int  `form:"limit,default=50" binding:"min=1"`
+	Before uint `form:"before"`
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type AuthHandler struct {
+	authService services.AuthService
+}
+
+func NewAuthHandler(authService services.AuthService) *AuthHandler {
+	return &AuthHandler{authService: authService}
+}
+
+// Login exchanges email and password for an access/refresh token pair
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(services.ErrValidationFailed.Wrap(err))
+		return
+	}
+
+	tokens, err := h.authService.Login(c.Request.Context(), req.Email, req.Password)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, tokens, "Logged in successfully")
+}
+
+// Refresh rotates a refresh token and returns a new token pair
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(services.ErrValidationFailed.Wrap(err))
+		return
+	}
+
+	tokens, err := h.authService.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, tokens, "Token refreshed successfully")
+}
+
+// Logout revokes a refresh token
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(services.ErrValidationFailed.Wrap(err))
+		return
+	}
+
+	if err := h.authService.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, nil, "Logged out successfully")
+}
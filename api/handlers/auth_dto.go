@@ -0,0 +1,34 @@
+package handlers
+
+// LoginRequest defines the credentials accepted by the login endpoint.
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// RefreshTokenRequest carries a refresh token for the refresh and logout endpoints.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// VerifyEmailQuery holds the query parameters of GET /api/verify-email.
+type VerifyEmailQuery struct {
+	Token string `form:"token" binding:"required"`
+}
+
+// ForgotPasswordRequest asks for a password reset email.
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest sets a new password with the token from a password reset email.
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// ChangePasswordRequest sets a new password given the current one.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
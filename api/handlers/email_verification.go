@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type EmailVerificationHandler struct {
+	verificationService services.EmailVerificationService
+}
+
+func NewEmailVerificationHandler(verificationService services.EmailVerificationService) *EmailVerificationHandler {
+	return &EmailVerificationHandler{verificationService: verificationService}
+}
+
+// Send queues a verification email to the user's current address
+func (h *EmailVerificationHandler) Send(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidUserID.Wrap(err))
+		return
+	}
+
+	if err := h.verificationService.SendVerification(c.Request.Context(), uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, nil, "Verification email queued")
+}
+
+// Verify marks an email address as verified using the token from a verification email
+func (h *EmailVerificationHandler) Verify(c *gin.Context) {
+	var query VerifyEmailQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(services.ErrValidationFailed.Wrap(err))
+		return
+	}
+
+	user, err := h.verificationService.VerifyEmail(c.Request.Context(), query.Token)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	setUserETag(c, user)
+	utils.SuccessResponse(c, user, "Email verified successfully")
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/apperror"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/gin-gonic/gin"
+)
+
+var errInvalidIfMatch = apperror.New(apperror.KindInvalid, "invalid_if_match",
+	`If-Match must be "*" or a single entity tag previously returned in ETag`)
+
+// userETag is a strong entity tag derived from the user's version.
+func userETag(user *models.User) string {
+	return `"` + strconv.FormatInt(user.Version, 10) + `"`
+}
+
+// setUserETag advertises the user's current version.
+func setUserETag(c *gin.Context, user *models.User) {
+	c.Header("ETag", userETag(user))
+}
+
+// ifMatchVersion returns the version required by the If-Match header, or 0
+// when the header is absent or "*" and any version is acceptable.
+func ifMatchVersion(c *gin.Context) (int64, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	tag, ok := strings.CutPrefix(header, `"`)
+	if !ok || !strings.HasSuffix(tag, `"`) {
+		return 0, errInvalidIfMatch // Weak tags never match If-Match, and lists are unsupported
+	}
+	version, err := strconv.ParseInt(strings.TrimSuffix(tag, `"`), 10, 64)
+	if err != nil || version < 1 {
+		return 0, errInvalidIfMatch
+	}
+	return version, nil
+}
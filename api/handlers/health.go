@@ -1,10 +1,47 @@
 package handlers
 
 import (
+	"net/http"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/health"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/requestid"
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
 
+// HealthCheck is kept for backward compatibility; it does not check
+// dependencies. Prefer HealthHandler.Live and HealthHandler.Ready.
 func HealthCheck(c *gin.Context) {
 	utils.SuccessResponse(c, nil, "Service is healthy")
 }
+
+type HealthHandler struct {
+	registry *health.Registry
+}
+
+func NewHealthHandler(registry *health.Registry) *HealthHandler {
+	return &HealthHandler{registry: registry}
+}
+
+// Live reports whether the process is up. It never checks dependencies,
+// so a database outage does not get healthy pods restarted.
+func (h *HealthHandler) Live(c *gin.Context) {
+	utils.SuccessResponse(c, gin.H{"status": health.StatusUp}, "Service is alive")
+}
+
+// Ready runs the registered dependency checks and returns 503 when a
+// critical one fails, so the orchestrator stops routing traffic here.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	report := h.registry.Run(c.Request.Context())
+
+	if report.Status == health.StatusDown {
+		c.JSON(http.StatusServiceUnavailable, utils.Response{
+			Success:   false,
+			Data:      report,
+			Message:   "Service is not ready",
+			RequestID: requestid.FromContext(c.Request.Context()),
+		})
+		return
+	}
+	utils.SuccessResponse(c, report, "Service is ready")
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type PasswordHandler struct {
+	passwordService services.PasswordService
+}
+
+func NewPasswordHandler(passwordService services.PasswordService) *PasswordHandler {
+	return &PasswordHandler{passwordService: passwordService}
+}
+
+// Forgot queues a password reset email. The response is the same whether
+// or not an account has the address.
+func (h *PasswordHandler) Forgot(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(services.ErrValidationFailed.Wrap(err))
+		return
+	}
+
+	if err := h.passwordService.ForgotPassword(c.Request.Context(), req.Email); err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, nil, "If an account with this email exists, a password reset email is on its way")
+}
+
+// Reset sets a new password using the token from a password reset email
+func (h *PasswordHandler) Reset(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(services.ErrValidationFailed.Wrap(err))
+		return
+	}
+
+	if err := h.passwordService.ResetPassword(c.Request.Context(), req.Token, req.Password); err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, nil, "Password reset successfully")
+}
+
+// Change sets a new password given the current one. Only users themselves
+// can change their password; others go through the reset flow.
+func (h *PasswordHandler) Change(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidUserID.Wrap(err))
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(services.ErrValidationFailed.Wrap(err))
+		return
+	}
+
+	user, err := h.passwordService.ChangePassword(c.Request.Context(), uint(id), req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	setUserETag(c, user)
+	utils.SuccessResponse(c, user, "Password changed successfully")
+}
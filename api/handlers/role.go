@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type RoleHandler struct {
+	roleService services.RoleService
+}
+
+func NewRoleHandler(roleService services.RoleService) *RoleHandler {
+	return &RoleHandler{roleService: roleService}
+}
+
+// List all roles with their permissions
+func (h *RoleHandler) List(c *gin.Context) {
+	roles, err := h.roleService.ListRoles(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, roles, "Roles fetched successfully")
+}
+
+// ListUserRoles returns the roles assigned to a user
+func (h *RoleHandler) ListUserRoles(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidUserID.Wrap(err))
+		return
+	}
+
+	roles, err := h.roleService.GetUserRoles(c.Request.Context(), uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, roles, "User roles fetched successfully")
+}
+
+// Assign grants a role to a user
+func (h *RoleHandler) Assign(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidUserID.Wrap(err))
+		return
+	}
+
+	var req AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(services.ErrValidationFailed.Wrap(err))
+		return
+	}
+
+	if err := h.roleService.AssignRole(c.Request.Context(), uint(id), req.Role); err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, nil, "Role assigned successfully")
+}
+
+// Revoke removes a role from a user
+func (h *RoleHandler) Revoke(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidUserID.Wrap(err))
+		return
+	}
+
+	if err := h.roleService.RevokeRole(c.Request.Context(), uint(id), c.Param("role")); err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, nil, "Role revoked successfully")
+}
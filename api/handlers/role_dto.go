@@ -0,0 +1,6 @@
+package handlers
+
+// AssignRoleRequest names the role to grant to a user.
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
@@ -4,12 +4,17 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/apperror"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/identity"
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
 
+var errInvalidUserID = apperror.New(apperror.KindInvalid, "invalid_user_id", "Invalid user ID format")
+
 type UserHandler struct {
 	userService services.UserService
 }
@@ -18,22 +23,55 @@ func NewUserHandler(userService services.UserService) *UserHandler {
 	return &UserHandler{userService: userService}
 }
 
-// List all users with pagination
+// List users with offset or cursor pagination, filtered and sorted as
+// described by services.ParseUserQuery
 func (h *UserHandler) List(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	h.list(c, nil)
+}
+
+// ListDeleted lists soft-deleted users with the same query parameters as List
+func (h *UserHandler) ListDeleted(c *gin.Context) {
+	h.list(c, func(q *repositories.UserQuery) { q.Deleted = repositories.DeletedOnly })
+}
+
+// RequestsDeletedUsers reports whether the deleted query parameter asks for
+// soft-deleted users. Values other than "exclude" are either that or a 400
+// from services.ParseUserQuery.
+func RequestsDeletedUsers(c *gin.Context) bool {
+	values := c.QueryArray("deleted")
+	return len(values) > 0 && values[len(values)-1] != string(repositories.DeletedExclude)
+}
+
+func (h *UserHandler) list(c *gin.Context, scope func(*repositories.UserQuery)) {
+	var query ListUsersQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(services.ErrValidationFailed.Wrap(err))
+		return
+	}
+	filter, err := services.ParseUserQuery(c.Request.URL.Query())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if scope != nil {
+		scope(&filter)
+	}
+	if query.After != nil {
+		h.listAfter(c, query, filter)
+		return
+	}
 
-	users, totalPages, totalItems, err := h.userService.ListUsers(c, page, limit)
+	users, totalPages, totalItems, err := h.userService.ListUsers(c.Request.Context(), filter, query.Page, query.Limit)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch users: "+err.Error())
+		c.Error(err)
 		return
 	}
 
 	response := map[string]interface{}{
 		"users": users,
 		"pagination": map[string]interface{}{
-			"current_page": page,
-			"per_page":     limit,
+			"current_page": query.Page,
+			"per_page":     min(query.Limit, services.MaxPageLimit),
 			"total_items":  totalItems,
 			"total_pages":  totalPages,
 		},
@@ -41,22 +79,75 @@ func (h *UserHandler) List(c *gin.Context) {
 	utils.SuccessResponse(c, response, "Users fetched successfully")
 }
 
+func (h *UserHandler) listAfter(c *gin.Context, query ListUsersQuery, filter repositories.UserQuery) {
+	page, err := h.userService.ListUsersAfter(c.Request.Context(), services.CursorQuery{
+		Query: filter,
+		After: *query.After,
+		Limit: query.Limit,
+		Count: services.CountMode(query.Count),
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	pagination := map[string]interface{}{
+		"per_page": page.Limit,
+		"has_more": page.HasMore,
+	}
+	if page.HasMore {
+		pagination["next_cursor"] = page.NextCursor
+
+		next := *c.Request.URL
+		q := next.Query()
+		q.Set("after", page.NextCursor)
+		next.RawQuery = q.Encode()
+		c.Header("Link", "<"+next.RequestURI()+`>; rel="next"`)
+	}
+	if page.Total != nil {
+		pagination["total_items"] = *page.Total
+		pagination["total_estimated"] = page.TotalEstimated
+	}
+
+	utils.SuccessResponse(c, map[string]interface{}{
+		"users":      page.Users,
+		"pagination": pagination,
+	}, "Users fetched successfully")
+}
+
 // Get user by ID
 func (h *UserHandler) Get(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID format")
+		c.Error(errInvalidUserID.Wrap(err))
+		return
+	}
+
+	user, err := h.userService.GetUserByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	setUserETag(c, user)
+	if c.GetHeader("If-None-Match") == userETag(user) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	utils.SuccessResponse(c, user, "User fetched successfully")
+}
+
+// Me returns the authenticated user
+func (h *UserHandler) Me(c *gin.Context) {
+	principal, ok := identity.FromContext(c.Request.Context())
+	if !ok {
+		c.Error(apperror.ErrUnauthenticated)
 		return
 	}
 
-	user, err := h.userService.GetUserByID(c, uint(id))
+	user, err := h.userService.GetUserByID(c.Request.Context(), principal.UserID)
 	if err != nil {
-		if err == services.ErrUserNotFound {
-			utils.ErrorResponse(c, http.StatusNotFound, err.Error())
-		} else {
-			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to fetch user: "+err.Error())
-		}
+		c.Error(err)
 		return
 	}
 	utils.SuccessResponse(c, user, "User fetched successfully")
@@ -66,43 +157,43 @@ func (h *UserHandler) Get(c *gin.Context) {
 func (h *UserHandler) Create(c *gin.Context) {
 	var req CreateUserRequest // Use DTO for request binding
 	if err := c.ShouldBindJSON(&req); err != nil {
-		// Use the custom ErrValidationFailed from the service layer for consistency, or define one in handlers
-		utils.ErrorResponse(c, http.StatusBadRequest, services.ErrValidationFailed.Error()+": "+err.Error())
+		c.Error(services.ErrValidationFailed.Wrap(err))
 		return
 	}
 
 	// Map DTO to domain model
 	user := models.User{
-		Name:  req.Name,
-		Email: req.Email,
+		Name:     req.Name,
+		Email:    req.Email,
+		Password: req.Password,
 	}
 
-	createdUser, err := h.userService.CreateUser(c, &user)
+	createdUser, err := h.userService.CreateUser(c.Request.Context(), &user)
 	if err != nil {
-		if err == services.ErrUserEmailExists {
-			utils.ErrorResponse(c, http.StatusConflict, err.Error())
-		} else if err == services.ErrValidationFailed { // Should be caught by ShouldBindJSON generally
-			utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
-		} else {
-			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create user: "+err.Error())
-		}
+		c.Error(err)
 		return
 	}
 	utils.SuccessResponse(c, createdUser, "User created successfully")
 }
 
-// Update user
+// Update replaces the user; omitted fields fail validation rather than being kept
 func (h *UserHandler) Update(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID format")
+		c.Error(errInvalidUserID.Wrap(err))
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	var req UpdateUserRequest // Use DTO for request binding
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, services.ErrValidationFailed.Error()+": "+err.Error())
+		c.Error(services.ErrValidationFailed.Wrap(err))
 		return
 	}
 
@@ -110,23 +201,17 @@ func (h *UserHandler) Update(c *gin.Context) {
 	// Note: The service layer currently handles which fields are updatable (Name, Email).
 	// This DTO helps ensure only these fields are considered from the request.
 	userUpdate := models.User{
-		Name:  req.Name,
-		Email: req.Email,
+		Name:    req.Name,
+		Email:   req.Email,
+		Version: version,
 	}
 
-	updatedUser, err := h.userService.UpdateUser(c, uint(id), &userUpdate)
+	updatedUser, err := h.userService.UpdateUser(c.Request.Context(), uint(id), &userUpdate)
 	if err != nil {
-		if err == services.ErrUserNotFound {
-			utils.ErrorResponse(c, http.StatusNotFound, err.Error())
-		} else if err == services.ErrEmailInUse {
-			utils.ErrorResponse(c, http.StatusConflict, err.Error())
-		} else if err == services.ErrValidationFailed { // Should be caught by ShouldBindJSON
-			utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
-		} else {
-			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to update user: "+err.Error())
-		}
+		c.Error(err)
 		return
 	}
+	setUserETag(c, updatedUser)
 	utils.SuccessResponse(c, updatedUser, "User updated successfully")
 }
 
@@ -135,18 +220,52 @@ func (h *UserHandler) Delete(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID format")
+		c.Error(errInvalidUserID.Wrap(err))
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	err = h.userService.DeleteUser(c, uint(id))
+	err = h.userService.DeleteUser(c.Request.Context(), uint(id), version)
 	if err != nil {
-		if err == services.ErrUserNotFound {
-			utils.ErrorResponse(c, http.StatusNotFound, err.Error())
-		} else {
-			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to delete user: "+err.Error())
-		}
+		c.Error(err)
 		return
 	}
 	utils.SuccessResponse(c, nil, "User deleted successfully")
 }
+
+// Restore a soft-deleted user
+func (h *UserHandler) Restore(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidUserID.Wrap(err))
+		return
+	}
+
+	user, err := h.userService.RestoreUser(c.Request.Context(), uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	setUserETag(c, user)
+	utils.SuccessResponse(c, user, "User restored successfully")
+}
+
+// Purge permanently removes a soft-deleted user
+func (h *UserHandler) Purge(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidUserID.Wrap(err))
+		return
+	}
+
+	if err := h.userService.PurgeUser(c.Request.Context(), uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, nil, "User purged successfully")
+}
@@ -5,15 +5,26 @@ package handlers
 type CreateUserRequest struct {
 	Name  string `json:"name" binding:"required,min=2,max=100"`
 	Email string `json:"email" binding:"required,email"`
-	// Add other fields like Password if required for creation
+	// Password is optional; users created without one cannot log in until
+	// they reset it. The password policy is applied by UserService.
+	Password string `json:"password"`
 }
 
-// UpdateUserRequest defines the structure for updating an existing user.
-// Fields are optional (e.g. using pointers or relying on PATCH semantics if implemented).
-// Here, we assume all fields are optional for PUT, and the service layer handles partial updates.
-// Alternatively, use binding tags like "omitempty" or make fields pointers.
+// UpdateUserRequest is the full representation of a user's updatable fields.
+// PUT replaces the user with it, and PATCH applies patches to it, so both
+// are validated by the same rules.
 type UpdateUserRequest struct {
-	Name  string `json:"name" binding:"omitempty,min=2,max=100"`
-	Email string `json:"email" binding:"omitempty,email"`
+	Name  string `json:"name" binding:"required,min=2,max=100"`
+	Email string `json:"email" binding:"required,email"`
 	// Add other updatable fields
 }
+
+// ListUsersQuery holds the query parameters of GET /api/users. Sending After,
+// even empty for the first page, selects cursor pagination; Page is then ignored.
+type ListUsersQuery struct {
+	Page  int     `form:"page,default=1" binding:"min=1"`
+	Limit int     `form:"limit,default=10" binding:"min=1"`
+	After *string `form:"after"`
+	// Count controls total_items in cursor mode: none (default), exact or estimate
+	Count string `form:"count" binding:"omitempty,oneof=none exact estimate"`
+}
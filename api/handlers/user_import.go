@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/middleware"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/apperror"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/logger"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	CSVContentType    = "text/csv"
+	NDJSONContentType = "application/x-ndjson"
+
+	maxImportBytes    = 64 << 20
+	maxNDJSONLineSize = 64 << 10
+	// exportFlushEvery is how many exported rows are buffered before flushing
+	exportFlushEvery = 500
+)
+
+var (
+	errInvalidCSVHeader = apperror.New(apperror.KindInvalid, "invalid_csv_header",
+		"CSV input must start with a header row naming at least the name and email columns")
+	errInvalidExportFormat = apperror.New(apperror.KindInvalid, "invalid_export_format", "format must be one of: csv ndjson")
+)
+
+// exportColumns are the CSV export columns, in order. Import ignores the
+// ones it does not use, so an export can be imported elsewhere.
+var exportColumns = []string{"id", "name", "email", "version", "created_at", "updated_at", "deleted_at"}
+
+// Import creates users from a CSV or NDJSON body. Each row is validated
+// like a CreateUserRequest and rows are inserted in batches; the response
+// reports every row that failed. ?transaction=all makes the import
+// all-or-nothing, and ?batch_size tunes the batch size.
+func (h *UserHandler) Import(c *gin.Context) {
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+
+	var rows iter.Seq[services.ImportRow]
+	switch c.ContentType() {
+	case CSVContentType:
+		var err error
+		if rows, err = csvImportRows(body); err != nil {
+			c.Error(err)
+			return
+		}
+	case NDJSONContentType, "application/ndjson":
+		rows = ndjsonImportRows(body)
+	default:
+		utils.ErrorResponse(c, http.StatusUnsupportedMediaType, "Import requires "+CSVContentType+" or "+NDJSONContentType)
+		return
+	}
+
+	opts := services.ImportOptions{Mode: services.ImportMode(c.Query("transaction"))}
+	if size := c.Query("batch_size"); size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil {
+			c.Error(services.ErrValidationFailed.WithFields(apperror.FieldError{
+				Field: "batch_size", Code: "type", Message: "must be an integer",
+			}))
+			return
+		}
+		opts.BatchSize = n
+	}
+
+	report, err := h.userService.ImportUsers(c.Request.Context(), rows, opts)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	message := "Users imported"
+	if report.RolledBack {
+		message = "Import rolled back; no users were created"
+	}
+	utils.SuccessResponse(c, report, message)
+}
+
+// importRow validates a decoded row with the CreateUserRequest rules.
+func importRow(line int, req CreateUserRequest) services.ImportRow {
+	row := services.ImportRow{Line: line, Name: req.Name, Email: req.Email, Password: req.Password}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		row.Errors = middleware.FieldErrors(err)
+	}
+	return row
+}
+
+// readFailure turns an error that ends the input into a final failed row.
+func readFailure(line int, err error) services.ImportRow {
+	fe := apperror.FieldError{Code: "read_failed", Message: "the input could not be read past this point"}
+	var maxBytes *http.MaxBytesError
+	if errors.As(err, &maxBytes) {
+		fe = apperror.FieldError{Code: "max_bytes", Message: "the input exceeds " + strconv.Itoa(maxImportBytes>>20) + " MiB and was cut off here"}
+	}
+	return services.ImportRow{Line: line, Errors: []apperror.FieldError{fe}}
+}
+
+func csvImportRows(r io.Reader) (iter.Seq[services.ImportRow], error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errInvalidCSVHeader.Wrap(err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	_, hasName := columns["name"]
+	_, hasEmail := columns["email"]
+	if !hasName || !hasEmail {
+		return nil, errInvalidCSVHeader
+	}
+
+	return func(yield func(services.ImportRow) bool) {
+		line := 1
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				return
+			}
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				row := services.ImportRow{Line: parseErr.StartLine, Errors: []apperror.FieldError{{
+					Code: "syntax", Message: parseErr.Err.Error(),
+				}}}
+				if !yield(row) {
+					return
+				}
+				continue
+			}
+			if err != nil {
+				yield(readFailure(line+1, err))
+				return
+			}
+			line, _ = reader.FieldPos(0)
+
+			field := func(name string) string {
+				if i, ok := columns[name]; ok && i < len(record) {
+					return strings.TrimSpace(record[i])
+				}
+				return ""
+			}
+			req := CreateUserRequest{Name: field("name"), Email: field("email"), Password: field("password")}
+			if !yield(importRow(line, req)) {
+				return
+			}
+		}
+	}, nil
+}
+
+func ndjsonImportRows(r io.Reader) iter.Seq[services.ImportRow] {
+	return func(yield func(services.ImportRow) bool) {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 4096), maxNDJSONLineSize)
+		line := 0
+		for scanner.Scan() {
+			line++
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" {
+				continue
+			}
+			var req CreateUserRequest
+			if err := json.Unmarshal([]byte(text), &req); err != nil {
+				row := services.ImportRow{Line: line, Errors: middleware.FieldErrors(err)}
+				if len(row.Errors) == 0 {
+					row.Errors = []apperror.FieldError{{Code: "syntax", Message: "is not a valid JSON object"}}
+				}
+				if !yield(row) {
+					return
+				}
+				continue
+			}
+			if !yield(importRow(line, req)) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			if errors.Is(err, bufio.ErrTooLong) {
+				yield(services.ImportRow{Line: line + 1, Errors: []apperror.FieldError{{
+					Code: "max", Message: "lines may be at most " + strconv.Itoa(maxNDJSONLineSize>>10) + " KiB; the input was not read further",
+				}}})
+				return
+			}
+			yield(readFailure(line+1, err))
+		}
+	}
+}
+
+// Export streams every user matching the List filters as CSV (default) or
+// NDJSON (?format=ndjson), one page at a time.
+func (h *UserHandler) Export(c *gin.Context) {
+	filter, err := services.ParseUserQuery(c.Request.URL.Query())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var write func(models.User) error
+	var flush func() error
+	switch format := c.DefaultQuery("format", "csv"); format {
+	case "csv":
+		w := csv.NewWriter(c.Writer)
+		write = func(u models.User) error { return w.Write(userCSVRecord(u)) }
+		flush = func() error { w.Flush(); return w.Error() }
+		c.Header("Content-Type", CSVContentType+"; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="users.csv"`)
+		c.Status(http.StatusOK)
+		if err := w.Write(exportColumns); err != nil {
+			c.Error(err)
+			return
+		}
+	case "ndjson":
+		enc := json.NewEncoder(c.Writer)
+		write = func(u models.User) error { return enc.Encode(u) }
+		flush = func() error { return nil }
+		c.Header("Content-Type", NDJSONContentType)
+		c.Header("Content-Disposition", `attachment; filename="users.ndjson"`)
+		c.Status(http.StatusOK)
+	default:
+		c.Error(errInvalidExportFormat)
+		return
+	}
+
+	exported := 0
+	err = h.userService.ExportUsers(c.Request.Context(), filter, func(u models.User) error {
+		if err := write(u); err != nil {
+			return err
+		}
+		if exported++; exported%exportFlushEvery == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err == nil {
+		return
+	}
+	if !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Disposition")
+		c.Error(err)
+		return
+	}
+	// The status is already sent; all that is left is to stop the stream
+	logger.FromContext(c.Request.Context()).Errorw("User export aborted", "exported", exported, "error", err)
+}
+
+func userCSVRecord(u models.User) []string {
+	deletedAt := ""
+	if u.DeletedAt.Valid {
+		deletedAt = u.DeletedAt.Time.UTC().Format(time.RFC3339Nano)
+	}
+	return []string{
+		strconv.FormatUint(uint64(u.ID), 10),
+		csvText(u.Name),
+		csvText(u.Email),
+		strconv.FormatInt(u.Version, 10),
+		u.CreatedAt.UTC().Format(time.RFC3339Nano),
+		u.UpdatedAt.UTC().Format(time.RFC3339Nano),
+		deletedAt,
+	}
+}
+
+// csvText keeps a user-supplied cell from being read as a formula when the
+// export is opened in a spreadsheet: cells that start with a formula
+// character get a leading apostrophe, which spreadsheets do not display.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
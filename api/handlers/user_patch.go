@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/apperror"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/utils"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	MergePatchContentType = "application/merge-patch+json" // RFC 7396
+	JSONPatchContentType  = "application/json-patch+json"  // RFC 6902
+
+	maxPatchBytes = 64 << 10
+)
+
+var (
+	errMalformedPatch  = apperror.New(apperror.KindInvalid, "malformed_patch", "Patch document is malformed")
+	errPatchTestFailed = apperror.New(apperror.KindConflict, "patch_test_failed", "A test operation in the patch failed")
+)
+
+// Patch applies a JSON Merge Patch or a JSON Patch to a user. The patch is
+// applied to the user's UpdateUserRequest representation, and the result is
+// validated exactly like a PUT body, so fields can be cleared deliberately
+// and read-only fields cannot be patched.
+func (h *UserHandler) Patch(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidUserID.Wrap(err))
+		return
+	}
+
+	contentType := c.ContentType()
+	if contentType != MergePatchContentType && contentType != JSONPatchContentType {
+		c.Header("Accept-Patch", MergePatchContentType+", "+JSONPatchContentType)
+		utils.ErrorResponse(c, http.StatusUnsupportedMediaType, "PATCH requires "+MergePatchContentType+" or "+JSONPatchContentType)
+		return
+	}
+	patch, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPatchBytes+1))
+	if err != nil {
+		c.Error(errMalformedPatch.Wrap(err))
+		return
+	}
+	if len(patch) > maxPatchBytes {
+		c.Error(errMalformedPatch.WithFields(apperror.FieldError{
+			Field: "patch", Code: "max", Message: "must be at most " + strconv.Itoa(maxPatchBytes) + " bytes",
+		}))
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	user, err := h.userService.GetUserByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	// Check early so a stale client gets 412 rather than a confusing patch error
+	if version != 0 && version != user.Version {
+		c.Error(services.ErrVersionMismatch)
+		return
+	}
+
+	req, err := applyUserPatch(contentType, user, patch)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// The patch was computed against this version, so the write must not
+	// land on top of a concurrent change
+	updatedUser, err := h.userService.UpdateUser(c.Request.Context(), uint(id), &models.User{
+		Name:    req.Name,
+		Email:   req.Email,
+		Version: user.Version,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	setUserETag(c, updatedUser)
+	utils.SuccessResponse(c, updatedUser, "User updated successfully")
+}
+
+func applyUserPatch(contentType string, user *models.User, patch []byte) (*UpdateUserRequest, error) {
+	current, err := json.Marshal(UpdateUserRequest{Name: user.Name, Email: user.Email})
+	if err != nil {
+		return nil, err
+	}
+
+	var patched []byte
+	switch contentType {
+	case MergePatchContentType:
+		if !json.Valid(patch) || !bytes.HasPrefix(bytes.TrimSpace(patch), []byte("{")) {
+			return nil, errMalformedPatch.WithFields(apperror.FieldError{
+				Field: "patch", Code: "type", Message: "must be a JSON object",
+			})
+		}
+		if patched, err = jsonpatch.MergePatch(current, patch); err != nil {
+			return nil, errMalformedPatch.Wrap(err)
+		}
+	case JSONPatchContentType:
+		ops, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return nil, errMalformedPatch.Wrap(err)
+		}
+		if patched, err = ops.Apply(current); err != nil {
+			if errors.Is(err, jsonpatch.ErrTestFailed) {
+				return nil, errPatchTestFailed.Wrap(err)
+			}
+			return nil, errMalformedPatch.Wrap(err).WithFields(apperror.FieldError{
+				Field: "patch", Code: "apply", Message: err.Error(),
+			})
+		}
+	}
+
+	var req UpdateUserRequest
+	dec := json.NewDecoder(bytes.NewReader(patched))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		// Patches may only touch fields of UpdateUserRequest
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return nil, services.ErrValidationFailed.Wrap(err).WithFields(apperror.FieldError{
+				Field: strings.Trim(field, `"`), Code: "unknown_field", Message: "cannot be patched",
+			})
+		}
+		return nil, services.ErrValidationFailed.Wrap(err)
+	}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return nil, services.ErrValidationFailed.Wrap(err)
+	}
+	return &req, nil
+}
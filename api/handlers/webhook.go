@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/apperror"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errInvalidWebhookID  = apperror.New(apperror.KindInvalid, "invalid_webhook_id", "Invalid webhook ID format")
+	errInvalidDeliveryID = apperror.New(apperror.KindInvalid, "invalid_delivery_id", "Invalid delivery ID format")
+)
+
+type WebhookHandler struct {
+	webhookService services.WebhookService
+}
+
+func NewWebhookHandler(webhookService services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+// Create a webhook subscription. The response is the only one that includes the secret.
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(services.ErrValidationFailed.Wrap(err))
+		return
+	}
+
+	sub, err := h.webhookService.CreateSubscription(c.Request.Context(), req.input())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, CreatedWebhookResponse{WebhookSubscription: sub, Secret: sub.Secret}, "Webhook created successfully")
+}
+
+// List all webhook subscriptions
+func (h *WebhookHandler) List(c *gin.Context) {
+	subs, err := h.webhookService.ListSubscriptions(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, subs, "Webhooks fetched successfully")
+}
+
+// Get a webhook subscription by ID
+func (h *WebhookHandler) Get(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	sub, err := h.webhookService.GetSubscription(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, sub, "Webhook fetched successfully")
+}
+
+// Update replaces a subscription's settings
+func (h *WebhookHandler) Update(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(services.ErrValidationFailed.Wrap(err))
+		return
+	}
+
+	sub, err := h.webhookService.UpdateSubscription(c.Request.Context(), id, req.input())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, sub, "Webhook updated successfully")
+}
+
+// Delete a subscription and its delivery log
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteSubscription(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, nil, "Webhook deleted successfully")
+}
+
+// ListDeliveries returns a subscription's delivery log, newest first,
+// optionally filtered by status
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	var query ListDeliveriesQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(services.ErrValidationFailed.Wrap(err))
+		return
+	}
+
+	page, err := h.webhookService.ListDeliveries(c.Request.Context(), services.WebhookDeliveryQuery{
+		SubscriptionID: id,
+		Status:         query.Status,
+		Before:         query.Before,
+		Limit:          query.Limit,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	pagination := map[string]interface{}{
+		"per_page": page.Limit,
+		"has_more": page.NextBefore != 0,
+	}
+	if page.NextBefore != 0 {
+		pagination["next_before"] = page.NextBefore
+
+		next := *c.Request.URL
+		q := next.Query()
+		q.Set("before", strconv.FormatUint(uint64(page.NextBefore), 10))
+		next.RawQuery = q.Encode()
+		c.Header("Link", "<"+next.RequestURI()+`>; rel="next"`)
+	}
+
+	utils.SuccessResponse(c, map[string]interface{}{
+		"deliveries": page.Deliveries,
+		"pagination": pagination,
+	}, "Webhook deliveries fetched successfully")
+}
+
+// Redeliver queues a delivery to be sent again
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+	deliveryID, err := strconv.ParseUint(c.Param("delivery_id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidDeliveryID.Wrap(err))
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(c.Request.Context(), id, uint(deliveryID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	utils.SuccessResponse(c, delivery, "Webhook delivery queued for redelivery")
+}
+
+// webhookID parses the :id path parameter, recording an error if it is invalid.
+func webhookID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidWebhookID.Wrap(err))
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+)
+
+// WebhookRequest is the full representation of a subscription's settings,
+// used by both POST and PUT. Omitting Secret generates one on create and
+// keeps the current one on update; Active defaults to true.
+type WebhookRequest struct {
+	URL         string   `json:"url" binding:"required,max=2048"`
+	EventTypes  []string `json:"event_types" binding:"required,min=1"`
+	Description string   `json:"description" binding:"max=255"`
+	Active      *bool    `json:"active"`
+	Secret      string   `json:"secret" binding:"omitempty,min=16,max=255"`
+}
+
+func (r WebhookRequest) input() services.WebhookInput {
+	active := r.Active == nil || *r.Active
+	return services.WebhookInput{
+		URL:         r.URL,
+		EventTypes:  r.EventTypes,
+		Description: r.Description,
+		Active:      active,
+		Secret:      r.Secret,
+	}
+}
+
+// CreatedWebhookResponse reveals the signing secret, which is only ever
+// returned when the subscription is created.
+type CreatedWebhookResponse struct {
+	*models.WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+// ListDeliveriesQuery holds the query parameters of the delivery log.
+// Before is the next_before value of the previous page.
+type ListDeliveriesQuery struct {
+	Limit  int    `form:"limit,default=50" binding:"min=1"`
+	Before uint   `form:"before"`
+	Status string `form:"status"`
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/apperror"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/identity"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// Auth requires a valid bearer access token and stores the authenticated
+// principal in the request context (see identity.FromContext).
+func Auth(tokens *auth.JWTManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		scheme, token, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			unauthorized(c, errMissingToken)
+			return
+		}
+
+		claims, err := tokens.ParseAccessToken(strings.TrimSpace(token))
+		if err != nil {
+			unauthorized(c, errInvalidAccessToken)
+			return
+		}
+		userID, err := claims.UserID()
+		if err != nil {
+			unauthorized(c, errInvalidAccessToken)
+			return
+		}
+
+		principal := &identity.Principal{UserID: userID, Email: claims.Email}
+		c.Request = c.Request.WithContext(identity.WithPrincipal(c.Request.Context(), principal))
+
+		c.Next()
+	}
+}
+
+var (
+	errMissingToken       = apperror.New(apperror.KindUnauthorized, "missing_token", "Missing or malformed authorization header")
+	errInvalidAccessToken = apperror.New(apperror.KindUnauthorized, "invalid_access_token", "Invalid or expired access token")
+)
+
+func unauthorized(c *gin.Context, err *apperror.Error) {
+	c.Header("WWW-Authenticate", `Bearer realm="api"`)
+	c.Error(err)
+	c.Abort()
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/identity"
+	"github.com/gin-gonic/gin"
+)
+
+// ClientIP stores the client IP in the request context (see
+// identity.ClientIPFromContext) so services can record it. The address is
+// gin's ClientIP: the connection's address, or the X-Forwarded-For client
+// when the connection comes from one of the engine's trusted proxies. Those
+// are set from TRUSTED_PROXIES, which trusts none by default.
+func ClientIP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(identity.WithClientIP(c.Request.Context(), c.ClientIP()))
+		c.Next()
+	}
+}
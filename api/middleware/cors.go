@@ -1,21 +1,205 @@
 package middleware
 
 import (
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/config"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/apperror"
 	"github.com/gin-gonic/gin"
 )
 
-func CORS() gin.HandlerFunc {
+var errCORSPreflightRejected = apperror.New(apperror.KindForbidden, "cors_preflight_rejected",
+	"Cross-origin request not allowed by the CORS policy")
+
+// safelistedHeaders may always be sent cross-origin (Fetch standard).
+var safelistedHeaders = []string{"accept", "accept-language", "content-language"}
+
+// CORSPolicy decides which cross-origin requests browsers may make. The
+// zero policy allows none.
+type CORSPolicy struct {
+	// AllowedOrigins holds exact origins such as "https://app.example.com",
+	// subdomain patterns such as "https://*.example.com", or "*" for any
+	// origin. "*" cannot be combined with AllowCredentials.
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response
+	MaxAge time.Duration
+}
+
+// NewCORSPolicy builds the default policy from the CORS_* settings.
+func NewCORSPolicy(cfg *config.Config) (CORSPolicy, error) {
+	p := CORSPolicy{
+		AllowedOrigins:   trimAll(cfg.CORSAllowedOrigins),
+		AllowedMethods:   trimAll(cfg.CORSAllowedMethods),
+		AllowedHeaders:   trimAll(cfg.CORSAllowedHeaders),
+		ExposedHeaders:   trimAll(cfg.CORSExposedHeaders),
+		AllowCredentials: cfg.CORSAllowCredentials,
+		MaxAge:           cfg.CORSMaxAge,
+	}
+	return p, p.Validate()
+}
+
+// Validate rejects policies browsers would refuse or that are ambiguous.
+func (p CORSPolicy) Validate() error {
+	for _, origin := range p.AllowedOrigins {
+		if origin == "*" {
+			if p.AllowCredentials {
+				return errors.New(`CORS: the "*" origin cannot be combined with credentials`)
+			}
+			continue
+		}
+		u, err := url.Parse(strings.Replace(origin, "*.", "wildcard.", 1))
+		if err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" || strings.Count(origin, "*") > 1 ||
+			(strings.Contains(origin, "*") && !strings.Contains(origin, "://*.")) {
+			return errors.New("CORS: invalid allowed origin " + strconv.Quote(origin))
+		}
+	}
+	return nil
+}
+
+// AllowsOrigin reports whether origin matches the allowlist.
+func (p CORSPolicy) AllowsOrigin(origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, allowed := range p.AllowedOrigins {
+		allowed = strings.ToLower(allowed)
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+		// "https://*.example.com" matches "https://a.example.com" and
+		// "https://a.b.example.com", but not "https://example.com"
+		if prefix, suffix, ok := strings.Cut(allowed, "*"); ok {
+			if strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) &&
+				len(origin) > len(prefix)+len(suffix) &&
+				!strings.ContainsAny(origin[len(prefix):len(origin)-len(suffix)], "/:@") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p CORSPolicy) allowsMethod(method string) bool {
+	return slices.Contains(p.AllowedMethods, method)
+}
+
+func (p CORSPolicy) allowsHeader(header string) bool {
+	header = strings.ToLower(header)
+	if slices.Contains(safelistedHeaders, header) {
+		return true
+	}
+	return slices.ContainsFunc(p.AllowedHeaders, func(h string) bool {
+		return strings.EqualFold(h, header)
+	})
+}
+
+// CORS applies policy to cross-origin requests. overrides maps path
+// prefixes to the policies of route groups that differ from the default;
+// the longest matching prefix wins. This is resolved from the path rather
+// than by group middleware because preflight requests never reach a route.
+//
+// Preflights are answered with 204 only if the origin, method and every
+// requested header are allowed, and with 403 otherwise. Actual requests
+// from disallowed origins are served without CORS headers, which makes the
+// browser withhold the response.
+func CORS(policy CORSPolicy, overrides map[string]CORSPolicy) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		p := policy
+		longest := -1
+		for prefix, override := range overrides {
+			if len(prefix) > longest && strings.HasPrefix(c.Request.URL.Path, prefix) {
+				p, longest = override, len(prefix)
+			}
+		}
+
+		h := c.Writer.Header()
+		h.Add("Vary", "Origin")
+		origin := c.GetHeader("Origin")
+		requestedMethod := c.GetHeader("Access-Control-Request-Method")
+		preflight := c.Request.Method == http.MethodOptions && requestedMethod != ""
+		if preflight {
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+		}
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		if !p.AllowsOrigin(origin) {
+			if preflight {
+				rejectPreflight(c)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if slices.Contains(p.AllowedOrigins, "*") {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if p.AllowCredentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		if !preflight {
+			if len(p.ExposedHeaders) > 0 {
+				h.Set("Access-Control-Expose-Headers", strings.Join(p.ExposedHeaders, ", "))
+			}
+			c.Next()
 			return
 		}
 
-		c.Next()
+		if !p.allowsMethod(requestedMethod) {
+			rejectPreflight(c)
+			return
+		}
+		requestedHeaders := trimAll(strings.Split(c.GetHeader("Access-Control-Request-Headers"), ","))
+		for _, header := range requestedHeaders {
+			if !p.allowsHeader(header) {
+				rejectPreflight(c)
+				return
+			}
+		}
+
+		h.Set("Access-Control-Allow-Methods", strings.Join(p.AllowedMethods, ", "))
+		if len(requestedHeaders) > 0 {
+			h.Set("Access-Control-Allow-Headers", strings.Join(requestedHeaders, ", "))
+		}
+		if p.MaxAge > 0 {
+			h.Set("Access-Control-Max-Age", strconv.Itoa(int(p.MaxAge.Seconds())))
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// rejectPreflight answers a disallowed preflight without CORS headers, so
+// the browser blocks the actual request.
+func rejectPreflight(c *gin.Context) {
+	h := c.Writer.Header()
+	h.Del("Access-Control-Allow-Origin")
+	h.Del("Access-Control-Allow-Credentials")
+	c.Error(errCORSPreflightRejected)
+	renderError(c)
+}
+
+// trimAll trims each value and drops empty ones.
+func trimAll(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
 	}
+	return out
 }
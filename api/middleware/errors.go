@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/apperror"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/logger"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var kindStatus = map[apperror.Kind]int{
+	apperror.KindInternal:             http.StatusInternalServerError,
+	apperror.KindInvalid:              http.StatusBadRequest,
+	apperror.KindUnauthorized:         http.StatusUnauthorized,
+	apperror.KindForbidden:            http.StatusForbidden,
+	apperror.KindNotFound:             http.StatusNotFound,
+	apperror.KindConflict:             http.StatusConflict,
+	apperror.KindPreconditionFailed:   http.StatusPreconditionFailed,
+	apperror.KindPreconditionRequired: http.StatusPreconditionRequired,
+	apperror.KindTooManyRequests:      http.StatusTooManyRequests,
+	apperror.KindUnprocessable:        http.StatusUnprocessableEntity,
+}
+
+var registerTagNameOnce sync.Once
+
+// Errors translates the last error a handler attached with c.Error into an
+// application/problem+json response. Typed domain errors are rendered with
+// their code and public message; validator errors become field-level
+// details; anything else is logged with the request ID and reported as a
+// generic internal error so raw messages never reach the client.
+func Errors() gin.HandlerFunc {
+	// Report validation errors with JSON field names rather than Go ones
+	registerTagNameOnce.Do(func() {
+		if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+			v.RegisterTagNameFunc(jsonFieldName)
+		}
+	})
+
+	return func(c *gin.Context) {
+		c.Next()
+		renderError(c)
+	}
+}
+
+// renderError writes the problem response for the last error attached to c,
+// unless a response has already been written. Middleware that needs to see
+// the final response before Errors runs may call it early.
+func renderError(c *gin.Context) {
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return
+	}
+	err := c.Errors.Last().Err
+
+	appErr := toAppError(err)
+	if appErr.Kind == apperror.KindInternal {
+		logger.FromContext(c.Request.Context()).Errorw("Request failed",
+			"error", err,
+			"code", appErr.Code,
+		)
+	}
+
+	problem := utils.Problem{
+		Status: kindStatus[appErr.Kind],
+		Detail: appErr.Message,
+		Code:   appErr.Code,
+	}
+	for _, f := range appErr.Fields {
+		problem.Errors = append(problem.Errors, utils.InvalidParam(f))
+	}
+	utils.ProblemResponse(c, problem)
+}
+
+// toAppError maps err onto the error catalog. Binding errors, raw or wrapped
+// in apperror.ErrValidation, are inspected for field-level details.
+func toAppError(err error) *apperror.Error {
+	appErr, isAppErr := apperror.As(err)
+	if isAppErr && !errors.Is(appErr, apperror.ErrValidation) {
+		return appErr
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]apperror.FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, apperror.FieldError{
+				Field:   fe.Field(),
+				Code:    fe.Tag(),
+				Message: validationMessage(fe),
+			})
+		}
+		return apperror.ErrValidation.Wrap(err).WithFields(fields...)
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return apperror.ErrValidation.Wrap(err).WithFields(apperror.FieldError{
+			Field:   typeErr.Field,
+			Code:    "type",
+			Message: "must be of type " + typeErr.Type.String(),
+		})
+	}
+	if errors.As(err, &syntaxErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return apperror.ErrMalformedBody.Wrap(err)
+	}
+
+	if isAppErr {
+		return appErr
+	}
+	return apperror.ErrInternal.Wrap(err)
+}
+
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		if isNumber(fe.Kind()) {
+			return "must be at least " + fe.Param()
+		}
+		return fmt.Sprintf("must be at least %s characters long", fe.Param())
+	case "max":
+		if isNumber(fe.Kind()) {
+			return "must be at most " + fe.Param()
+		}
+		return fmt.Sprintf("must be at most %s characters long", fe.Param())
+	case "oneof":
+		return "must be one of: " + fe.Param()
+	default:
+		return fmt.Sprintf("failed the %q rule", fe.Tag())
+	}
+}
+
+func isNumber(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64
+}
+
+// jsonFieldName names a field after its json tag, or its form tag for query DTOs.
+func jsonFieldName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" {
+		name, _, _ = strings.Cut(f.Tag.Get("form"), ",")
+	}
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return f.Name
+	}
+	return name
+}
+
+// FieldErrors returns the field-level details Errors would report for err,
+// for handlers that collect validation problems rather than fail on them.
+func FieldErrors(err error) []apperror.FieldError {
+	return toAppError(err).Fields
+}
@@ -0,0 +1,207 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/apperror"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/identity"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/idempotency"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/logger"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks responses replayed from the store
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	// maxFingerprintBytes bounds how much of a body is buffered to fingerprint it
+	maxFingerprintBytes = 1 << 20
+)
+
+var (
+	errInvalidIdempotencyKey = apperror.New(apperror.KindInvalid, "invalid_idempotency_key",
+		"Idempotency-Key must be 1 to 255 printable ASCII characters")
+	errIdempotencyBodyTooLarge = apperror.New(apperror.KindInvalid, "idempotency_body_too_large",
+		"Request bodies sent with an Idempotency-Key may not exceed 1 MiB")
+	errIdempotencyKeyReused = apperror.New(apperror.KindUnprocessable, "idempotency_key_reused",
+		"Idempotency-Key was already used with a different request")
+	errIdempotencyInProgress = apperror.New(apperror.KindConflict, "idempotency_request_in_progress",
+		"A request with this Idempotency-Key is still being processed")
+)
+
+// replayedHeaders are the response headers stored with an idempotent
+// response. Per-request headers such as the request ID are left out.
+var replayedHeaders = []string{"Content-Type", "Location", "ETag", "Link"}
+
+// Idempotency makes POST, PUT, PATCH and DELETE requests that carry an
+// Idempotency-Key safe to retry. The first request with a key runs
+// normally and its response is stored; retries with the same method, path
+// and body replay it, while reuse of the key for a different request fails
+// with 422 and a retry racing the original fails with 409. Server errors
+// are not stored, so the client may retry them. Keys are scoped to the
+// authenticated user, or to the client IP without one, so this must run
+// after Auth on protected routes.
+func Idempotency(store idempotency.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !isMutating(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if !validIdempotencyKey(key) {
+			c.Error(errInvalidIdempotencyKey)
+			c.Abort()
+			return
+		}
+
+		fingerprint, err := requestFingerprint(c)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		ctx := c.Request.Context()
+		storeKey := idempotencyScope(c) + ":" + key
+		lease, rec, err := store.Lock(ctx, storeKey, fingerprint)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		switch {
+		case lease != nil:
+		case rec.Fingerprint != fingerprint:
+			c.Error(errIdempotencyKeyReused)
+			c.Abort()
+			return
+		case !rec.Completed():
+			c.Header("Retry-After", "1")
+			c.Error(errIdempotencyInProgress)
+			c.Abort()
+			return
+		default:
+			replay(c, rec)
+			return
+		}
+
+		w := &capturingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		// Render a pending error now so the stored response includes it
+		renderError(c)
+
+		// Store even if the client has gone away, so its retry can be replayed
+		ctx = context.WithoutCancel(ctx)
+		status := c.Writer.Status()
+		if status >= http.StatusInternalServerError {
+			err = store.Release(ctx, lease)
+		} else {
+			header := http.Header{}
+			for _, name := range replayedHeaders {
+				if v := c.Writer.Header().Values(name); len(v) > 0 {
+					header[name] = v
+				}
+			}
+			err = store.Complete(ctx, lease, idempotency.Record{
+				Fingerprint: fingerprint,
+				Status:      status,
+				Header:      header,
+				Body:        w.body.Bytes(),
+			})
+		}
+		switch {
+		case errors.Is(err, idempotency.ErrLockLost):
+			// The request outlived the lock timeout and a retry took the key over
+			logger.FromContext(ctx).Warnw("Idempotency key was reclaimed before the response was saved", "status", status)
+		case err != nil:
+			logger.FromContext(ctx).Errorw("Failed to save idempotent response", "error", err)
+		}
+	}
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// idempotencyScope keeps callers from seeing each other's responses.
+// Anonymous requests, such as registration, are scoped to the client IP,
+// so guessing another client's key replays nothing.
+func idempotencyScope(c *gin.Context) string {
+	if p, ok := identity.FromContext(c.Request.Context()); ok {
+		return "user:" + strconv.FormatUint(uint64(p.UserID), 10)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// requestFingerprint hashes the method, URI and body, and restores the body
+// for the handler.
+func requestFingerprint(c *gin.Context) (string, error) {
+	h := sha256.New()
+	io.WriteString(h, c.Request.Method+" "+c.Request.URL.RequestURI()+"\n")
+	if c.Request.Body != nil {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxFingerprintBytes+1))
+		if err != nil {
+			return "", apperror.ErrMalformedBody.Wrap(err)
+		}
+		if len(body) > maxFingerprintBytes {
+			return "", errIdempotencyBodyTooLarge
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		h.Write(body)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func replay(c *gin.Context, rec *idempotency.Record) {
+	for name, values := range rec.Header {
+		c.Writer.Header()[name] = values
+	}
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Status(rec.Status)
+	if _, err := c.Writer.Write(rec.Body); err != nil && !errors.Is(err, http.ErrBodyNotAllowed) {
+		logger.FromContext(c.Request.Context()).Warnw("Failed to replay idempotent response", "error", err)
+	}
+	c.Abort()
+}
+
+// capturingWriter records the response body as it is written.
+type capturingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *capturingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *capturingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
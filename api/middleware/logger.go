@@ -18,7 +18,8 @@ func Logger(log *logger.Logger) gin.HandlerFunc {
 		latency := time.Since(start)
 		statusCode := c.Writer.Status()
 
-		log.Infow("HTTP Request",
+		// Prefer the request-scoped logger so the entry carries the request ID
+		logger.FromContextOr(c.Request.Context(), log).Infow("HTTP Request",
 			"status", statusCode,
 			"method", method,
 			"path", path,
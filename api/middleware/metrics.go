@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests that did not match any route, so random
+// 404 paths cannot blow up metric cardinality.
+const unmatchedRoute = "unmatched"
+
+// Metrics records request counts, latency and in-flight requests labelled
+// by the route template (c.FullPath()) rather than the raw URL path.
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := c.Request.Method
+
+		inFlight := m.HTTPInFlight.WithLabelValues(method, route)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		c.Next()
+
+		status := strconv.Itoa(c.Writer.Status())
+		m.HTTPRequests.WithLabelValues(method, route, status).Inc()
+		m.HTTPDuration.WithLabelValues(method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/apperror"
+	"github.com/gin-gonic/gin"
+)
+
+var errIfMatchRequired = apperror.New(apperror.KindPreconditionRequired, "if_match_required",
+	"This request must be conditional; send If-Match with the resource's ETag")
+
+// RequireIfMatch rejects requests without an If-Match header with 428, so
+// clients cannot overwrite changes they have not seen. Attach it to
+// mutating routes whose handlers honour If-Match.
+func RequireIfMatch() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("If-Match") == "" {
+			c.Error(errIfMatchRequired)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/logger"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestID reuses a well-formed incoming X-Request-ID or generates a new
+// one, echoes it in the response and stores it, together with a child of
+// log tagged with "request_id", in the request context. Register it first
+// so every later middleware and log line can see the ID.
+func RequestID(log *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.IsValid(id) {
+			id = requestid.New()
+		}
+		c.Header(requestid.Header, id)
+
+		ctx := requestid.WithContext(c.Request.Context(), id)
+		ctx = logger.NewContext(ctx, log.With("request_id", id))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
@@ -1,33 +1,193 @@
 package routes
 
 import (
+	"time"
+
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/handlers"
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/middleware"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/auth"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/health"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/idempotency"
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/logger"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/metrics"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/ratelimit"
 	"github.com/gin-gonic/gin"
-	// "gorm.io/gorm" // No longer passed directly to handlers
 )
 
-func Setup(r *gin.Engine, userService services.UserService, log *logger.Logger) {
-	// Middleware
-	r.Use(middleware.CORS())
-	r.Use(middleware.Logger(log))
+// Dependencies bundles everything the HTTP layer needs from main.
+type Dependencies struct {
+	UserService services.UserService
+	AuthService services.AuthService
+	RoleService services.RoleService
+	// AuditService reads the audit log that UserService writes
+	AuditService   services.AuditService
+	WebhookService services.WebhookService
+	Tokens         *auth.JWTManager
+	Health         *health.Registry
+	Metrics        *metrics.Metrics
+	Logger         *logger.Logger
+	// EmailVerification queues verification emails and checks their tokens
+	EmailVerification services.EmailVerificationService
+	// Passwords changes passwords and runs the password reset flow
+	Passwords services.PasswordService
+	// RequireIfMatch enforces conditional requests on user mutations
+	RequireIfMatch bool
+	// RateLimitStore backs RateLimits; nil disables rate limiting
+	RateLimitStore ratelimit.Store
+	RateLimits     RateLimitPolicies
+	// Idempotency stores responses for Idempotency-Key retries; nil ignores the header
+	Idempotency idempotency.Store
+	// CORS is the default cross-origin policy; see corsOverrides for exceptions
+	CORS middleware.CORSPolicy
+}
+
+// corsOverrides returns per-route-group CORS policies, keyed by path prefix.
+// Health probes are readable from any origin; the metrics endpoint is not
+// meant for browsers at all.
+func corsOverrides() map[string]middleware.CORSPolicy {
+	return map[string]middleware.CORSPolicy{
+		"/api/health": {
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{"GET"},
+			ExposedHeaders: []string{"X-Request-ID"},
+			MaxAge:         time.Hour,
+		},
+		"/metrics": {},
+	}
+}
+
+// RateLimitPolicies configures rate limits per route group. A zero policy
+// leaves its group unlimited.
+type RateLimitPolicies struct {
+	// Auth limits login, refresh, logout, password resets and email
+	// verification per client IP
+	Auth ratelimit.Policy
+	// Register limits open registration (POST /api/users) per client IP
+	Register ratelimit.Policy
+	// API limits authenticated routes per user
+	API ratelimit.Policy
+}
+
+func Setup(r *gin.Engine, deps Dependencies) {
+	// Middleware. RequestID runs first so every later log line carries the ID.
+	r.Use(middleware.RequestID(deps.Logger))
+	r.Use(middleware.ClientIP())
+	r.Use(middleware.Tracing())
+	r.Use(middleware.CORS(deps.CORS, corsOverrides()))
+	r.Use(middleware.Logger(deps.Logger))
+	r.Use(middleware.Metrics(deps.Metrics))
+	// Errors runs innermost so the logger and metrics see the translated status
+	r.Use(middleware.Errors())
 
-	// Health check
+	// Prometheus scrape endpoint
+	r.GET("/metrics", gin.WrapH(deps.Metrics.Handler()))
+
+	// Health checks
+	healthHandler := handlers.NewHealthHandler(deps.Health)
 	r.GET("/api/health", handlers.HealthCheck)
+	r.GET("/api/health/live", healthHandler.Live)
+	r.GET("/api/health/ready", healthHandler.Ready)
+
+	userHandler := handlers.NewUserHandler(deps.UserService)
+	authHandler := handlers.NewAuthHandler(deps.AuthService)
+	roleHandler := handlers.NewRoleHandler(deps.RoleService)
+	auditHandler := handlers.NewAuditHandler(deps.AuditService)
+	webhookHandler := handlers.NewWebhookHandler(deps.WebhookService)
+	verificationHandler := handlers.NewEmailVerificationHandler(deps.EmailVerification)
+	passwordHandler := handlers.NewPasswordHandler(deps.Passwords)
+	requireAuth := middleware.Auth(deps.Tokens)
+	can := func(permission string) gin.HandlerFunc {
+		return middleware.RequirePermission(deps.RoleService, permission)
+	}
+	selfOr := func(permission string) gin.HandlerFunc {
+		return middleware.RequireSelfOrPermission(deps.RoleService, permission)
+	}
+	// Listing soft-deleted users needs the same permission as the trash
+	canSeeDeleted := middleware.RequirePermissionWhen(deps.RoleService, models.PermUsersDelete, handlers.RequestsDeletedUsers)
+	conditional := func(c *gin.Context) { c.Next() }
+	if deps.RequireIfMatch {
+		conditional = middleware.RequireIfMatch()
+	}
+	limit := func(name string, policy ratelimit.Policy, key middleware.RateLimitKey) gin.HandlerFunc {
+		if deps.RateLimitStore == nil {
+			return func(c *gin.Context) { c.Next() }
+		}
+		return middleware.RateLimit(deps.RateLimitStore, name, policy, key)
+	}
+	idempotent := func(c *gin.Context) { c.Next() }
+	if deps.Idempotency != nil {
+		idempotent = middleware.Idempotency(deps.Idempotency)
+	}
 
-	// User routes
-	userHandler := handlers.NewUserHandler(userService) // Pass userService
 	api := r.Group("/api")
 	{
+		// Auth routes
+		authRoutes := api.Group("/auth", limit("auth", deps.RateLimits.Auth, middleware.ByIP))
+		{
+			authRoutes.POST("/login", authHandler.Login)
+			authRoutes.POST("/refresh", authHandler.Refresh)
+			authRoutes.POST("/logout", authHandler.Logout)
+			authRoutes.POST("/password/forgot", passwordHandler.Forgot)
+			authRoutes.POST("/password/reset", passwordHandler.Reset)
+		}
+
+		// Email verification links are opened from an inbox, without a token
+		api.GET("/verify-email", limit("auth", deps.RateLimits.Auth, middleware.ByIP), verificationHandler.Verify)
+
+		// User routes. Creating a user is open registration; everything else requires a token.
 		users := api.Group("/users")
 		{
-			users.GET("", userHandler.List)
-			users.GET("/:id", userHandler.Get)
-			users.POST("", userHandler.Create)
-			users.PUT("/:id", userHandler.Update)
-			users.DELETE("/:id", userHandler.Delete)
+			users.POST("", limit("register", deps.RateLimits.Register, middleware.ByIP), idempotent, userHandler.Create)
+
+			authenticated := users.Group("", requireAuth, limit("api", deps.RateLimits.API, middleware.ByUser), idempotent)
+			authenticated.GET("", can(models.PermUsersRead), canSeeDeleted, userHandler.List)
+			authenticated.GET("/me", userHandler.Me)
+			authenticated.GET("/export", can(models.PermUsersRead), canSeeDeleted, userHandler.Export)
+			authenticated.GET("/:id", selfOr(models.PermUsersRead), userHandler.Get)
+			authenticated.PUT("/:id", selfOr(models.PermUsersWrite), conditional, userHandler.Update)
+			authenticated.PATCH("/:id", selfOr(models.PermUsersWrite), conditional, userHandler.Patch)
+			authenticated.DELETE("/:id", can(models.PermUsersDelete), conditional, userHandler.Delete)
+			authenticated.POST("/:id/verify-email/send", selfOr(models.PermUsersWrite), verificationHandler.Send)
+			authenticated.PUT("/:id/password", middleware.RequireSelf(), passwordHandler.Change)
+
+			// Trash: soft-deleted users
+			authenticated.GET("/deleted", can(models.PermUsersDelete), userHandler.ListDeleted)
+			authenticated.POST("/deleted/:id/restore", can(models.PermUsersDelete), userHandler.Restore)
+			authenticated.DELETE("/deleted/:id", can(models.PermUsersPurge), userHandler.Purge)
+
+			// Role assignments
+			authenticated.GET("/:id/roles", selfOr(models.PermRolesRead), roleHandler.ListUserRoles)
+			authenticated.POST("/:id/roles", can(models.PermRolesAssign), roleHandler.Assign)
+			authenticated.DELETE("/:id/roles/:role", can(models.PermRolesAssign), roleHandler.Revoke)
+
+			// Imports stream bodies far larger than an idempotency fingerprint
+			// buffers, so they are not idempotent
+			users.POST("/import", requireAuth, limit("api", deps.RateLimits.API, middleware.ByUser),
+				can(models.PermUsersWrite), userHandler.Import)
+		}
+
+		// Role routes
+		api.GET("/roles", requireAuth, limit("api", deps.RateLimits.API, middleware.ByUser), can(models.PermRolesRead), roleHandler.List)
+
+		// Audit log
+		audit := api.Group("/audit", requireAuth, limit("api", deps.RateLimits.API, middleware.ByUser), can(models.PermAuditRead))
+		{
+			audit.GET("", auditHandler.List)
+			audit.GET("/verify", auditHandler.Verify)
+		}
+
+		// Webhook subscriptions and their delivery log
+		webhooks := api.Group("/webhooks", requireAuth, limit("api", deps.RateLimits.API, middleware.ByUser), idempotent)
+		{
+			webhooks.POST("", can(models.PermWebhooksWrite), webhookHandler.Create)
+			webhooks.GET("", can(models.PermWebhooksRead), webhookHandler.List)
+			webhooks.GET("/:id", can(models.PermWebhooksRead), webhookHandler.Get)
+			webhooks.PUT("/:id", can(models.PermWebhooksWrite), webhookHandler.Update)
+			webhooks.DELETE("/:id", can(models.PermWebhooksWrite), webhookHandler.Delete)
+			webhooks.GET("/:id/deliveries", can(models.PermWebhooksRead), webhookHandler.ListDeliveries)
+			webhooks.POST("/:id/deliveries/:delivery_id/redeliver", can(models.PermWebhooksWrite), webhookHandler.Redeliver)
 		}
 	}
 }
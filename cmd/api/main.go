@@ -1,15 +1,34 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/middleware"
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/routes"
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/config"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/events"
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/auth"
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/database"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/health"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/idempotency"
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/logger"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/metrics"
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/persistence" // New import
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/publishers"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/ratelimit"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/server"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/tracing"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/webhooks"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/jobs/runner"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 func main() {
@@ -21,7 +40,30 @@ func main() {
 
 	// Initialize logger
 	l := logger.NewLogger(cfg.LogLevel)
-	defer l.Sync() // Ensure logs are flushed
+	defer l.Sync() // Ensure logs are flushed; runs last, after the server and database have shut down
+
+	if cfg.JWTAccessSecret == "" || cfg.JWTRefreshSecret == "" || cfg.EmailVerificationSecret == "" || cfg.PasswordResetSecret == "" {
+		l.Fatal("JWT_ACCESS_SECRET, JWT_REFRESH_SECRET, EMAIL_VERIFICATION_SECRET and PASSWORD_RESET_SECRET must be set")
+	}
+	hasher, err := auth.NewPasswordHasher(cfg)
+	if err != nil {
+		l.Fatal("Invalid password hashing configuration: " + err.Error())
+	}
+	webhookAddresses, err := webhooks.NewAddressPolicy(cfg.WebhookAllowedNetworks)
+	if err != nil {
+		l.Fatal("Invalid WEBHOOK_ALLOWED_NETWORKS: " + err.Error())
+	}
+	if cfg.UserRetentionPeriod > 0 {
+		// The purge is a periodic job; the API only serves requests
+		l.Warnw("USER_RETENTION_PERIOD is set, but deleted users are purged only while the worker (cmd/worker) runs",
+			"retention", cfg.UserRetentionPeriod)
+	}
+
+	// Initialize tracing before anything that may create spans
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg)
+	if err != nil {
+		l.Fatal("Failed to set up tracing: " + err.Error())
+	}
 
 	// Initialize database
 	db, err := database.NewPostgresDB(cfg)
@@ -30,22 +72,231 @@ func main() {
 		l.Fatal("Failed to connect to database: " + err.Error())
 	}
 
+	// Refuse to serve traffic against an outdated schema
+	if err := database.CheckMigrations(context.Background(), db); err != nil {
+		l.Fatal(err.Error())
+	}
+
+	// Instrument the database: query latency via a GORM plugin, pool stats via sql.DB
+	appMetrics := metrics.New()
+	if err := db.Use(appMetrics.GormPlugin()); err != nil {
+		l.Fatal("Failed to install metrics plugin: " + err.Error())
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		l.Fatal("Failed to access database pool: " + err.Error())
+	}
+	if err := appMetrics.RegisterDBStats(sqlDB, cfg.DBName); err != nil {
+		l.Fatal("Failed to register database metrics: " + err.Error())
+	}
+	// Background job queue depths; the worker reports the jobs it runs
+	if err := appMetrics.Register(runner.NewDepthCollector(persistence.NewGormJobStore(db), cfg.HealthCheckTimeout)); err != nil {
+		l.Fatal("Failed to register job metrics: " + err.Error())
+	}
+
+	// Cancelled on SIGINT/SIGTERM; stops background work and the server
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Initialize Repositories
 	userRepository := persistence.NewGormUserRepository(db)
+	refreshTokenRepository := persistence.NewGormRefreshTokenRepository(db)
+	roleRepository := persistence.NewGormRoleRepository(db)
+	auditRepository := persistence.NewGormAuditRepository(db)
+	webhookRepository := persistence.NewGormWebhookRepository(db)
 
 	// Initialize Services
-	userService := services.NewUserService(userRepository)
+	tokens := auth.NewJWTManager(cfg)
+	passwords := services.Passwords{Hasher: hasher, Policy: services.PasswordPolicy{MinLength: cfg.PasswordMinLength}}
+	userService := services.NewUserService(userRepository, passwords)
+	authService := services.NewAuthService(userRepository, refreshTokenRepository, tokens, hasher)
+	roleService := services.NewRoleService(roleRepository, userRepository)
+	auditService := services.NewAuditService(auditRepository)
+	webhookService := services.NewWebhookService(webhookRepository, webhookAddresses)
+	// The worker sends the emails, so the API needs no mailer
+	verificationService := services.NewEmailVerificationService(userRepository, tokens, services.EmailVerificationOptions{})
+	passwordService := services.NewPasswordService(userRepository, tokens, services.PasswordServiceOptions{Passwords: passwords})
+
+	// Publish domain events from the outbox. In-process subscribers see
+	// them only if OUTBOX_PUBLISHERS includes "inprocess".
+	eventBus := events.NewBus()
+	eventBus.Subscribe(events.AllEvents, webhookService.Enqueue)
+	publisher, eventFile, err := eventPublisher(cfg, eventBus)
+	if err != nil {
+		l.Fatal("Invalid outbox configuration: " + err.Error())
+	}
+	relayDone := make(chan struct{})
+	if publisher != nil {
+		relay := services.NewOutboxRelay(persistence.NewGormOutboxRepository(db), publisher, services.OutboxRelayOptions{
+			BatchSize:    cfg.OutboxBatchSize,
+			PollInterval: cfg.OutboxPollInterval,
+			Lease:        cfg.OutboxLease,
+			Retention:    cfg.OutboxRetention,
+		})
+		go func() {
+			defer close(relayDone)
+			relay.Run(logger.NewContext(ctx, l))
+		}()
+	} else {
+		close(relayDone)
+	}
+
+	// Send webhook deliveries
+	dispatcher := services.NewWebhookDispatcher(webhookRepository, webhooks.NewSender(cfg.WebhookTimeout, webhookAddresses), services.WebhookDispatcherOptions{
+		BatchSize:    cfg.WebhookBatchSize,
+		PollInterval: cfg.WebhookPollInterval,
+		Lease:        cfg.WebhookLease,
+		MaxAttempts:  cfg.WebhookMaxAttempts,
+		Retention:    cfg.WebhookRetention,
+	})
+	dispatcherDone := make(chan struct{})
+	go func() {
+		defer close(dispatcherDone)
+		dispatcher.Run(logger.NewContext(ctx, l))
+	}()
+
+	// Readiness checks
+	healthRegistry := health.NewRegistry()
+	healthRegistry.Register(health.PostgresCheck(db, cfg.HealthCheckTimeout))
+
+	// Rate limiting
+	rateLimitStore, rateLimits, err := rateLimiting(cfg, db)
+	if err != nil {
+		l.Fatal("Invalid rate limit configuration: " + err.Error())
+	}
+
+	corsPolicy, err := middleware.NewCORSPolicy(cfg)
+	if err != nil {
+		l.Fatal("Invalid CORS configuration: " + err.Error())
+	}
 
 	// Initialize Gin router
 	r := gin.Default()
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		l.Fatal("Invalid TRUSTED_PROXIES: " + err.Error())
+	}
+
+	// Setup routes
+	routes.Setup(r, routes.Dependencies{
+		UserService:    userService,
+		AuthService:    authService,
+		RoleService:    roleService,
+		AuditService:   auditService,
+		WebhookService: webhookService,
+		Tokens:         tokens,
+		Health:         healthRegistry,
+		Metrics:        appMetrics,
+		Logger:         l,
+
+		EmailVerification: verificationService,
+		Passwords:         passwordService,
+		RequireIfMatch:    cfg.RequireIfMatch,
+		RateLimitStore:    rateLimitStore,
+		RateLimits:        rateLimits,
+		Idempotency:       idempotency.NewPostgresStore(db, cfg.IdempotencyTTL, cfg.IdempotencyLockTimeout),
+		CORS:              corsPolicy,
+	})
 
-	// Setup routes - pass userService
-	routes.Setup(r, userService, l) // Pass userService instead of db
+	// Start server and block until SIGINT/SIGTERM, then drain in-flight
+	// requests and close dependencies in reverse order of initialization.
+	go func() {
+		<-ctx.Done()
+		healthRegistry.MarkShuttingDown() // Fail readiness while draining
+	}()
 
-	// Start server
-	l.Info("Starting server on port " + cfg.Port)
-	if err := r.Run(":" + cfg.Port); err != nil {
+	srv := server.New(cfg, r, l)
+	srv.OnShutdown("tracing", shutdownTracing) // Registered first so it flushes last
+	srv.OnShutdown("database", func(ctx context.Context) error {
+		return sqlDB.Close()
+	})
+	if eventFile != nil {
+		srv.OnShutdown("event file", func(ctx context.Context) error {
+			return eventFile.Close()
+		})
+	}
+	// Let the relay and the dispatcher finish their batches before the database closes
+	srv.OnShutdown("outbox relay", func(ctx context.Context) error {
+		select {
+		case <-relayDone:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	srv.OnShutdown("webhook dispatcher", func(ctx context.Context) error {
+		select {
+		case <-dispatcherDone:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+
+	if err := srv.Run(ctx); err != nil {
 		// Using l.Fatal with a simple error message as per existing style
-		l.Fatal("Failed to start server: " + err.Error())
+		l.Fatal("Server stopped with error: " + err.Error())
+	}
+	l.Info("Server stopped")
+}
+
+// eventPublisher builds the publisher the outbox relay delivers to from
+// OUTBOX_PUBLISHERS. It returns a nil publisher if none is configured, and
+// the NDJSON file, if any, for closing on shutdown.
+func eventPublisher(cfg *config.Config, bus *events.Bus) (events.Publisher, *os.File, error) {
+	var list []events.Publisher
+	var file *os.File
+	for _, name := range cfg.OutboxPublishers {
+		switch strings.TrimSpace(name) {
+		case "":
+		case "inprocess":
+			list = append(list, bus)
+		case "ndjson":
+			p, f, err := publishers.OpenNDJSONFile(cfg.OutboxNDJSONPath)
+			if err != nil {
+				return nil, nil, err
+			}
+			list, file = append(list, p), f
+		default:
+			return nil, nil, fmt.Errorf("unknown OUTBOX_PUBLISHERS entry %q", name)
+		}
+	}
+	switch len(list) {
+	case 0:
+		return nil, file, nil
+	case 1:
+		return list[0], file, nil
+	}
+	return events.Fanout(list...), file, nil
+}
+
+// rateLimiting builds the rate limit store and per-group policies from config.
+func rateLimiting(cfg *config.Config, db *gorm.DB) (ratelimit.Store, routes.RateLimitPolicies, error) {
+	var policies routes.RateLimitPolicies
+	var store ratelimit.Store
+	switch cfg.RateLimitStore {
+	case "none":
+		return nil, policies, nil
+	case "memory":
+		store = ratelimit.NewMemoryStore()
+	case "postgres":
+		store = ratelimit.NewPostgresStore(db)
+	default:
+		return nil, policies, fmt.Errorf("unknown RATE_LIMIT_STORE %q", cfg.RateLimitStore)
+	}
+
+	for _, p := range []struct {
+		spec   string
+		policy *ratelimit.Policy
+	}{
+		{cfg.RateLimitAuth, &policies.Auth},
+		{cfg.RateLimitRegister, &policies.Register},
+		{cfg.RateLimitAPI, &policies.API},
+	} {
+		policy, err := ratelimit.ParsePolicy(p.spec)
+		if err != nil {
+			return nil, policies, err
+		}
+		*p.policy = policy
 	}
+	return store, policies, nil
 }
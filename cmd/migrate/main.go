@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/config"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/database"
+)
+
+const usage = `Usage: migrate [-dir migrations] <command> [args]
+
+Commands:
+  up [N]        apply all pending migrations, or only the next N
+  down [N]      roll back the last N applied migrations (default 1)
+  status        list migrations and whether they are applied
+  create NAME   create a new up/down migration pair in -dir
+`
+
+var namePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+func main() {
+	dir := flag.String("dir", "migrations", "migrations directory (used by create)")
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if args[0] == "create" {
+		if len(args) != 2 {
+			log.Fatal("create requires a migration name")
+		}
+		if err := create(*dir, args[1]); err != nil {
+			log.Fatalf("Failed to create migration: %v", err)
+		}
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	db, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	migrator, err := database.NewMigratorForDB(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx, countArg(args, 0))
+		for _, m := range applied {
+			fmt.Printf("applied  %d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	case "down":
+		reverted, err := migrator.Down(ctx, countArg(args, 1))
+		for _, m := range reverted {
+			fmt.Printf("reverted %d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%d_%-40s %s\n", s.Version, s.Name, state)
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// countArg parses the optional numeric argument of up/down.
+func countArg(args []string, def int) int {
+	if len(args) < 2 {
+		return def
+	}
+	n, err := strconv.Atoi(args[1])
+	if err != nil || n < 1 {
+		log.Fatalf("Invalid count %q", args[1])
+	}
+	return n
+}
+
+// create writes an empty migration pair numbered after the highest existing version.
+func create(dir, name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("name must match %s", namePattern)
+	}
+	existing, err := database.LoadMigrations(os.DirFS(dir))
+	if err != nil {
+		return err
+	}
+	next := int64(1)
+	if len(existing) > 0 {
+		next = existing[len(existing)-1].Version + 1
+	}
+
+	base := fmt.Sprintf("%06d_%s", next, name)
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(dir, base+"."+direction+".sql")
+		if err := os.WriteFile(path, []byte("-- "+base+" ("+direction+")\n"), 0o644); err != nil {
+			return err
+		}
+		fmt.Println("created", path)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/config"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/auth"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/database"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/logger"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/mailer"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/metrics"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/persistence"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/server"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/tracing"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/jobs"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/jobs/runner"
+)
+
+// The worker runs background jobs from the jobs table. Any number of
+// workers may run next to the API servers; each serves its metrics on
+// WORKER_PORT.
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	l := logger.NewLogger(cfg.LogLevel)
+	defer l.Sync()
+
+	if cfg.EmailVerificationSecret == "" || cfg.PasswordResetSecret == "" {
+		l.Fatal("EMAIL_VERIFICATION_SECRET and PASSWORD_RESET_SECRET must be set")
+	}
+	hasher, err := auth.NewPasswordHasher(cfg)
+	if err != nil {
+		l.Fatal("Invalid password hashing configuration: " + err.Error())
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg)
+	if err != nil {
+		l.Fatal("Failed to set up tracing: " + err.Error())
+	}
+
+	db, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		l.Fatal("Failed to connect to database: " + err.Error())
+	}
+	if err := database.CheckMigrations(context.Background(), db); err != nil {
+		l.Fatal(err.Error())
+	}
+
+	appMetrics := metrics.New()
+	if err := db.Use(appMetrics.GormPlugin()); err != nil {
+		l.Fatal("Failed to install metrics plugin: " + err.Error())
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		l.Fatal("Failed to access database pool: " + err.Error())
+	}
+	if err := appMetrics.RegisterDBStats(sqlDB, cfg.DBName); err != nil {
+		l.Fatal("Failed to register database metrics: " + err.Error())
+	}
+
+	// Cancelled on SIGINT/SIGTERM; stops claiming jobs and the metrics server
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	mail, err := newMailer(cfg)
+	if err != nil {
+		l.Fatal("Invalid mailer configuration: " + err.Error())
+	}
+
+	// Register a handler for every job kind
+	userRepository := persistence.NewGormUserRepository(db)
+	tokens := auth.NewJWTManager(cfg)
+	passwords := services.Passwords{Hasher: hasher, Policy: services.PasswordPolicy{MinLength: cfg.PasswordMinLength}}
+	userService := services.NewUserService(userRepository, passwords)
+	verificationService := services.NewEmailVerificationService(userRepository, tokens, services.EmailVerificationOptions{
+		Mailer:    mail,
+		VerifyURL: strings.TrimSuffix(cfg.AppBaseURL, "/") + "/api/verify-email",
+	})
+	passwordService := services.NewPasswordService(userRepository, tokens, services.PasswordServiceOptions{
+		Passwords: passwords,
+		Mailer:    mail,
+		ResetURL:  cfg.PasswordResetURL,
+	})
+	registry := jobs.NewRegistry()
+	services.RegisterUserJobs(registry, userService, verificationService, passwordService)
+
+	var periodic []runner.Periodic
+	if cfg.UserRetentionPeriod > 0 {
+		periodic = append(periodic, runner.Periodic{
+			Args:     services.PurgeDeletedUsersArgs{RetentionPeriod: cfg.UserRetentionPeriod},
+			Interval: cfg.UserPurgeInterval,
+		})
+	}
+
+	worker := runner.NewWorker(persistence.NewGormJobStore(db), registry, runner.WorkerOptions{
+		Queues:       cfg.JobQueues,
+		Concurrency:  cfg.JobConcurrency,
+		PollInterval: cfg.JobPollInterval,
+		Lease:        cfg.JobLease,
+		Retention:    cfg.JobRetention,
+		// Leave the rest of the shutdown budget to the hooks that follow
+		ShutdownTimeout: cfg.ShutdownTimeout / 2,
+		Periodic:        periodic,
+	}, appMetrics)
+	workerDone := make(chan struct{})
+	go func() {
+		defer close(workerDone)
+		worker.Run(logger.NewContext(ctx, l))
+	}()
+	l.Infow("Worker started", "queues", cfg.JobQueues, "concurrency", cfg.JobConcurrency, "kinds", registry.Kinds())
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", appMetrics.Handler())
+
+	serverCfg := *cfg
+	serverCfg.Port = cfg.WorkerPort
+	srv := server.New(&serverCfg, mux, l)
+	srv.OnShutdown("tracing", shutdownTracing)
+	srv.OnShutdown("database", func(ctx context.Context) error {
+		return sqlDB.Close()
+	})
+	// Let running jobs finish before the database closes
+	srv.OnShutdown("worker", func(ctx context.Context) error {
+		select {
+		case <-workerDone:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+
+	if err := srv.Run(ctx); err != nil {
+		l.Fatal("Worker stopped with error: " + err.Error())
+	}
+	l.Info("Worker stopped")
+}
+
+// newMailer builds the mailer configured by MAILER.
+func newMailer(cfg *config.Config) (mailer.Mailer, error) {
+	switch cfg.Mailer {
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("SMTP_HOST must be set")
+		}
+		return mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:       cfg.SMTPHost,
+			Port:       cfg.SMTPPort,
+			Username:   cfg.SMTPUsername,
+			Password:   cfg.SMTPPassword,
+			From:       cfg.MailFrom,
+			RequireTLS: cfg.SMTPRequireTLS,
+		}), nil
+	case "file":
+		return mailer.NewFileMailer(cfg.MailerFileDir, cfg.MailFrom)
+	}
+	return nil, fmt.Errorf("unknown MAILER %q", cfg.Mailer)
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/spf13/viper"
 )
 
@@ -20,12 +22,222 @@ type Config struct {
 	DBPass      string `mapstructure:"DB_PASSWORD"`
 	DBName      string `mapstructure:"DB_NAME"`
 	LogLevel    string `mapstructure:"LOG_LEVEL"`
+
+	// HTTP server timeouts
+	ServerReadTimeout       time.Duration `mapstructure:"SERVER_READ_TIMEOUT"`
+	ServerReadHeaderTimeout time.Duration `mapstructure:"SERVER_READ_HEADER_TIMEOUT"`
+	ServerWriteTimeout      time.Duration `mapstructure:"SERVER_WRITE_TIMEOUT"`
+	ServerIdleTimeout       time.Duration `mapstructure:"SERVER_IDLE_TIMEOUT"`
+	// ShutdownTimeout bounds how long in-flight requests may drain after SIGTERM
+	ShutdownTimeout time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+
+	// HealthCheckTimeout bounds each dependency check of the readiness probe
+	HealthCheckTimeout time.Duration `mapstructure:"HEALTH_CHECK_TIMEOUT"`
+
+	// Tracing. TracingExporter is one of "none", "stdout" or "otlp"; the OTLP
+	// exporter sends traces over HTTP to OTLPEndpoint (e.g. http://collector:4318).
+	TracingExporter    string  `mapstructure:"TRACING_EXPORTER"`
+	TracingServiceName string  `mapstructure:"TRACING_SERVICE_NAME"`
+	TracingSampleRatio float64 `mapstructure:"TRACING_SAMPLE_RATIO"`
+	OTLPEndpoint       string  `mapstructure:"OTLP_ENDPOINT"`
+
+	// JWT authentication
+	JWTAccessSecret  string        `mapstructure:"JWT_ACCESS_SECRET"`
+	JWTRefreshSecret string        `mapstructure:"JWT_REFRESH_SECRET"`
+	JWTIssuer        string        `mapstructure:"JWT_ISSUER"`
+	JWTAccessTTL     time.Duration `mapstructure:"JWT_ACCESS_TTL"`
+	JWTRefreshTTL    time.Duration `mapstructure:"JWT_REFRESH_TTL"`
+
+	// Email verification. Links sent to users point at
+	// AppBaseURL/api/verify-email and carry a single-use token signed with
+	// EmailVerificationSecret that expires after EmailVerificationTTL.
+	AppBaseURL              string        `mapstructure:"APP_BASE_URL"`
+	EmailVerificationSecret string        `mapstructure:"EMAIL_VERIFICATION_SECRET"`
+	EmailVerificationTTL    time.Duration `mapstructure:"EMAIL_VERIFICATION_TTL"`
+
+	// Passwords. New passwords are hashed with PasswordHashAlgorithm
+	// ("argon2id" or "bcrypt"); stored hashes made with other settings are
+	// replaced on the next successful login. PasswordArgon2Memory is in KiB.
+	// At most PasswordHashConcurrency hashes are computed at once, which
+	// bounds their memory to PasswordArgon2Memory times that.
+	PasswordHashAlgorithm     string `mapstructure:"PASSWORD_HASH_ALGORITHM"`
+	PasswordBcryptCost        int    `mapstructure:"PASSWORD_BCRYPT_COST"`
+	PasswordArgon2Memory      uint32 `mapstructure:"PASSWORD_ARGON2_MEMORY"`
+	PasswordArgon2Iterations  uint32 `mapstructure:"PASSWORD_ARGON2_ITERATIONS"`
+	PasswordArgon2Parallelism uint8  `mapstructure:"PASSWORD_ARGON2_PARALLELISM"`
+	PasswordHashConcurrency   int    `mapstructure:"PASSWORD_HASH_CONCURRENCY"`
+	PasswordMinLength         int    `mapstructure:"PASSWORD_MIN_LENGTH"`
+
+	// Password reset. Emails link to PasswordResetURL, a page that posts
+	// the token and a new password to /api/auth/password/reset.
+	PasswordResetURL    string        `mapstructure:"PASSWORD_RESET_URL"`
+	PasswordResetSecret string        `mapstructure:"PASSWORD_RESET_SECRET"`
+	PasswordResetTTL    time.Duration `mapstructure:"PASSWORD_RESET_TTL"`
+
+	// Mail. Mailer is "smtp" or "file" (messages written to MailerFileDir
+	// as .eml files, for development).
+	Mailer        string `mapstructure:"MAILER"`
+	MailFrom      string `mapstructure:"MAIL_FROM"`
+	MailerFileDir string `mapstructure:"MAILER_FILE_DIR"`
+	SMTPHost      string `mapstructure:"SMTP_HOST"`
+	SMTPPort      string `mapstructure:"SMTP_PORT"`
+	SMTPUsername  string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword  string `mapstructure:"SMTP_PASSWORD"`
+	// SMTPRequireTLS refuses to send mail when the server does not offer
+	// STARTTLS. Turn it off only for local relays such as MailHog.
+	SMTPRequireTLS bool `mapstructure:"SMTP_REQUIRE_TLS"`
+
+	// RequireIfMatch makes PUT, PATCH and DELETE on users fail with 428
+	// unless they carry If-Match, instead of falling back to last-write-wins
+	RequireIfMatch bool `mapstructure:"REQUIRE_IF_MATCH"`
+
+	// Soft-deleted users are purged once deleted for longer than
+	// UserRetentionPeriod by a worker job enqueued every UserPurgeInterval;
+	// zero keeps them forever
+	UserRetentionPeriod time.Duration `mapstructure:"USER_RETENTION_PERIOD"`
+	UserPurgeInterval   time.Duration `mapstructure:"USER_PURGE_INTERVAL"`
+
+	// Rate limiting. RateLimitStore is "memory" (per replica), "postgres"
+	// (shared by all replicas) or "none". Policies are "<limit>/<period>",
+	// optionally with ",burst=<n>"; empty or "0" leaves a group unlimited.
+	RateLimitStore    string `mapstructure:"RATE_LIMIT_STORE"`
+	RateLimitAuth     string `mapstructure:"RATE_LIMIT_AUTH"`
+	RateLimitRegister string `mapstructure:"RATE_LIMIT_REGISTER"`
+	RateLimitAPI      string `mapstructure:"RATE_LIMIT_API"`
+
+	// TrustedProxies are the IPs or CIDRs of the reverse proxies in front
+	// of the API. Only their X-Forwarded-For headers are believed when
+	// resolving the client IP used for rate limits and the audit log; by
+	// default none are, and the client IP is the connection's address.
+	TrustedProxies []string `mapstructure:"TRUSTED_PROXIES"`
+
+	// Responses to Idempotency-Key requests are kept for IdempotencyTTL. A
+	// request still unfinished after IdempotencyLockTimeout is presumed lost
+	// and its key may be retried.
+	IdempotencyTTL         time.Duration `mapstructure:"IDEMPOTENCY_TTL"`
+	IdempotencyLockTimeout time.Duration `mapstructure:"IDEMPOTENCY_LOCK_TIMEOUT"`
+
+	// Outbox relay. OutboxPublishers lists where events go: "inprocess"
+	// (subscribers of the in-process bus) and/or "ndjson" (appended to
+	// OutboxNDJSONPath); empty disables the relay. Published events are
+	// deleted after OutboxRetention, zero keeps them. A claimed batch is
+	// leased for OutboxLease and claimed again if it is not published by then.
+	OutboxPublishers   []string      `mapstructure:"OUTBOX_PUBLISHERS"`
+	OutboxNDJSONPath   string        `mapstructure:"OUTBOX_NDJSON_PATH"`
+	OutboxBatchSize    int           `mapstructure:"OUTBOX_BATCH_SIZE"`
+	OutboxPollInterval time.Duration `mapstructure:"OUTBOX_POLL_INTERVAL"`
+	OutboxLease        time.Duration `mapstructure:"OUTBOX_LEASE"`
+	OutboxRetention    time.Duration `mapstructure:"OUTBOX_RETENTION"`
+
+	// Webhooks. Deliveries failing WebhookMaxAttempts times are dead; each
+	// request times out after WebhookTimeout. Succeeded and dead deliveries
+	// are deleted after WebhookRetention, zero keeps them. Webhooks go only
+	// to public addresses and to WebhookAllowedNetworks (CIDRs or IPs). A
+	// claimed batch is leased for WebhookLease, which should exceed
+	// WebhookBatchSize times WebhookTimeout.
+	WebhookBatchSize       int           `mapstructure:"WEBHOOK_BATCH_SIZE"`
+	WebhookPollInterval    time.Duration `mapstructure:"WEBHOOK_POLL_INTERVAL"`
+	WebhookTimeout         time.Duration `mapstructure:"WEBHOOK_TIMEOUT"`
+	WebhookLease           time.Duration `mapstructure:"WEBHOOK_LEASE"`
+	WebhookMaxAttempts     int           `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookRetention       time.Duration `mapstructure:"WEBHOOK_RETENTION"`
+	WebhookAllowedNetworks []string      `mapstructure:"WEBHOOK_ALLOWED_NETWORKS"`
+
+	// Background jobs. The worker (cmd/worker) serves its metrics on
+	// WorkerPort and consumes JobQueues, running up to JobConcurrency jobs at
+	// once. A claimed job is leased for JobLease and retried elsewhere if its
+	// worker stops renewing the lease. Finished jobs are deleted after
+	// JobRetention, zero keeps them.
+	WorkerPort      string        `mapstructure:"WORKER_PORT"`
+	JobQueues       []string      `mapstructure:"JOB_QUEUES"`
+	JobConcurrency  int           `mapstructure:"JOB_CONCURRENCY"`
+	JobPollInterval time.Duration `mapstructure:"JOB_POLL_INTERVAL"`
+	JobLease        time.Duration `mapstructure:"JOB_LEASE"`
+	JobRetention    time.Duration `mapstructure:"JOB_RETENTION"`
+
+	// CORS. Lists are comma-separated; origins may be exact, "*" or
+	// subdomain patterns such as https://*.example.com
+	CORSAllowedOrigins   []string      `mapstructure:"CORS_ALLOWED_ORIGINS"`
+	CORSAllowedMethods   []string      `mapstructure:"CORS_ALLOWED_METHODS"`
+	CORSAllowedHeaders   []string      `mapstructure:"CORS_ALLOWED_HEADERS"`
+	CORSExposedHeaders   []string      `mapstructure:"CORS_EXPOSED_HEADERS"`
+	CORSAllowCredentials bool          `mapstructure:"CORS_ALLOW_CREDENTIALS"`
+	CORSMaxAge           time.Duration `mapstructure:"CORS_MAX_AGE"`
+}
+
+// setDefaults registers fallback values for optional settings so that
+// older env files keep working as new options are introduced.
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("SERVER_READ_TIMEOUT", "15s")
+	v.SetDefault("SERVER_READ_HEADER_TIMEOUT", "5s")
+	v.SetDefault("SERVER_WRITE_TIMEOUT", "30s")
+	v.SetDefault("SERVER_IDLE_TIMEOUT", "120s")
+	v.SetDefault("SHUTDOWN_TIMEOUT", "20s")
+	v.SetDefault("HEALTH_CHECK_TIMEOUT", "2s")
+	v.SetDefault("TRACING_EXPORTER", "none")
+	v.SetDefault("TRACING_SERVICE_NAME", "lean-backend-boilerplate")
+	v.SetDefault("TRACING_SAMPLE_RATIO", 1.0)
+	v.SetDefault("JWT_ISSUER", "lean-backend-boilerplate")
+	v.SetDefault("JWT_ACCESS_TTL", "15m")
+	v.SetDefault("JWT_REFRESH_TTL", "168h")
+	v.SetDefault("APP_BASE_URL", "http://localhost:8080")
+	v.SetDefault("EMAIL_VERIFICATION_TTL", "24h")
+	v.SetDefault("PASSWORD_HASH_ALGORITHM", "argon2id")
+	v.SetDefault("PASSWORD_BCRYPT_COST", 12)
+	v.SetDefault("PASSWORD_ARGON2_MEMORY", 65536)
+	v.SetDefault("PASSWORD_ARGON2_ITERATIONS", 3)
+	v.SetDefault("PASSWORD_ARGON2_PARALLELISM", 2)
+	v.SetDefault("PASSWORD_HASH_CONCURRENCY", 4)
+	v.SetDefault("PASSWORD_MIN_LENGTH", 12)
+	v.SetDefault("PASSWORD_RESET_URL", "http://localhost:8080/reset-password")
+	v.SetDefault("PASSWORD_RESET_TTL", "1h")
+	v.SetDefault("MAILER", "file")
+	v.SetDefault("MAIL_FROM", "Lean Backend <no-reply@localhost>")
+	v.SetDefault("MAILER_FILE_DIR", "mail")
+	v.SetDefault("SMTP_PORT", "587")
+	v.SetDefault("SMTP_REQUIRE_TLS", true)
+	v.SetDefault("REQUIRE_IF_MATCH", false)
+	v.SetDefault("USER_RETENTION_PERIOD", "0s")
+	v.SetDefault("USER_PURGE_INTERVAL", "1h")
+	v.SetDefault("RATE_LIMIT_STORE", "memory")
+	v.SetDefault("RATE_LIMIT_AUTH", "10/1m")
+	v.SetDefault("RATE_LIMIT_REGISTER", "5/1h")
+	v.SetDefault("RATE_LIMIT_API", "600/1m")
+	v.SetDefault("TRUSTED_PROXIES", "")
+	v.SetDefault("IDEMPOTENCY_TTL", "24h")
+	v.SetDefault("IDEMPOTENCY_LOCK_TIMEOUT", "1m")
+	v.SetDefault("OUTBOX_PUBLISHERS", "inprocess")
+	v.SetDefault("OUTBOX_NDJSON_PATH", "events.ndjson")
+	v.SetDefault("OUTBOX_BATCH_SIZE", 100)
+	v.SetDefault("OUTBOX_POLL_INTERVAL", "1s")
+	v.SetDefault("OUTBOX_LEASE", "1m")
+	v.SetDefault("OUTBOX_RETENTION", "168h")
+	v.SetDefault("WEBHOOK_BATCH_SIZE", 50)
+	v.SetDefault("WEBHOOK_POLL_INTERVAL", "1s")
+	v.SetDefault("WEBHOOK_TIMEOUT", "10s")
+	v.SetDefault("WEBHOOK_LEASE", "10m")
+	v.SetDefault("WEBHOOK_MAX_ATTEMPTS", 10)
+	v.SetDefault("WEBHOOK_RETENTION", "720h")
+	v.SetDefault("WEBHOOK_ALLOWED_NETWORKS", "")
+	v.SetDefault("WORKER_PORT", "9091")
+	v.SetDefault("JOB_QUEUES", "default")
+	v.SetDefault("JOB_CONCURRENCY", 10)
+	v.SetDefault("JOB_POLL_INTERVAL", "1s")
+	v.SetDefault("JOB_LEASE", "1m")
+	v.SetDefault("JOB_RETENTION", "168h")
+	v.SetDefault("CORS_ALLOWED_ORIGINS", "*")
+	v.SetDefault("CORS_ALLOWED_METHODS", "GET,POST,PUT,PATCH,DELETE")
+	v.SetDefault("CORS_ALLOWED_HEADERS", "Authorization,Content-Type,X-Request-ID,If-Match,If-None-Match,Idempotency-Key,X-API-Key")
+	v.SetDefault("CORS_EXPOSED_HEADERS", "X-Request-ID,ETag,Link,Location,Retry-After,RateLimit-Policy,RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset,Idempotent-Replayed")
+	v.SetDefault("CORS_ALLOW_CREDENTIALS", false)
+	v.SetDefault("CORS_MAX_AGE", "10m")
 }
 
 func LoadFromFile(file string) (*Config, error) {
 	v := viper.New()
 	v.SetConfigFile(file)
 	v.AutomaticEnv()
+	setDefaults(v)
 
 	if err := v.ReadInConfig(); err != nil {
 		return nil, err
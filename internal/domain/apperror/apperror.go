@@ -0,0 +1,92 @@
+// Package apperror defines the typed errors the domain layer returns. Each
+// error carries a stable machine-readable code, a Kind the transport layer
+// maps to a status, and a message that is safe to show to clients. The
+// underlying cause, if any, is kept for logging only.
+package apperror
+
+import "errors"
+
+// Kind classifies an error independently of any transport.
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindInvalid
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindPreconditionFailed
+	KindPreconditionRequired
+	KindTooManyRequests
+	KindUnprocessable
+)
+
+// FieldError describes a problem with a single input field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type Error struct {
+	Kind Kind
+	// Code is stable and machine-readable, e.g. "user_not_found"
+	Code string
+	// Message is safe to return to clients
+	Message string
+	Fields  []FieldError
+	cause   error
+}
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether target is an *Error with the same code, so wrapped
+// copies still match their catalog entry with errors.Is.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Wrap returns a copy of e that records cause for logging.
+func (e *Error) Wrap(cause error) *Error {
+	cp := *e
+	cp.cause = cause
+	return &cp
+}
+
+// WithFields returns a copy of e carrying field-level details.
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	cp := *e
+	cp.Fields = append(append([]FieldError(nil), e.Fields...), fields...)
+	return &cp
+}
+
+// As returns the *Error in err's chain, if any.
+func As(err error) (*Error, bool) {
+	var e *Error
+	ok := errors.As(err, &e)
+	return e, ok
+}
+
+// Generic catalog entries. Feature-specific errors live next to the code that returns them.
+var (
+	ErrInternal        = New(KindInternal, "internal_error", "An internal error occurred")
+	ErrValidation      = New(KindInvalid, "validation_failed", "validation failed")
+	ErrMalformedBody   = New(KindInvalid, "malformed_body", "Request body is not valid JSON")
+	ErrUnauthenticated = New(KindUnauthorized, "unauthenticated", "Authentication required")
+	ErrForbidden       = New(KindForbidden, "forbidden", "Permission denied")
+)
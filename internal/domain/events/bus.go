@@ -0,0 +1,62 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Handler reacts to one event.
+type Handler func(ctx context.Context, env Envelope) error
+
+// AllEvents subscribes a handler to every event type.
+const AllEvents = "*"
+
+// Bus is an in-process Publisher that calls the handlers subscribed to each
+// event synchronously, in subscription order.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{handlers: map[string][]Handler{}}
+}
+
+// Subscribe registers h for events of eventType, or of every type with AllEvents.
+func (b *Bus) Subscribe(eventType string, h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], h)
+}
+
+// Publish calls every matching handler, even after one fails, and returns
+// their errors joined. A failed event is published again later, so the
+// handlers that succeeded see it twice.
+func (b *Bus) Publish(ctx context.Context, env Envelope) error {
+	b.mu.RLock()
+	handlers := append(append([]Handler{}, b.handlers[env.Type]...), b.handlers[AllEvents]...)
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, h := range handlers {
+		if err := h(ctx, env); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Fanout returns a Publisher that publishes every event to each of
+// publishers in turn and fails if any of them does.
+func Fanout(publishers ...Publisher) Publisher {
+	return PublisherFunc(func(ctx context.Context, env Envelope) error {
+		var errs []error
+		for _, p := range publishers {
+			if err := p.Publish(ctx, env); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
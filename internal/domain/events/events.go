@@ -0,0 +1,116 @@
+// Package events defines the domain events other systems can react to and
+// the Publisher interface they are delivered through. Events are written to
+// an outbox in the same transaction as the change they describe and
+// published afterwards by services.OutboxRelay.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Event types, named "<aggregate>.<past tense verb>".
+const (
+	TypeUserCreated       = "user.created"
+	TypeUserEmailChanged  = "user.email_changed"
+	TypeUserEmailVerified = "user.email_verified"
+	TypeUserDeleted       = "user.deleted"
+)
+
+const AggregateUser = "user"
+
+// Types lists every event type.
+func Types() []string {
+	return []string{TypeUserCreated, TypeUserEmailChanged, TypeUserEmailVerified, TypeUserDeleted}
+}
+
+// Event is a fact about a change to an aggregate.
+type Event interface {
+	EventType() string
+	AggregateID() uint
+}
+
+type UserCreated struct {
+	UserID uint   `json:"user_id"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+}
+
+func (UserCreated) EventType() string   { return TypeUserCreated }
+func (e UserCreated) AggregateID() uint { return e.UserID }
+
+type UserEmailChanged struct {
+	UserID   uint   `json:"user_id"`
+	OldEmail string `json:"old_email"`
+	NewEmail string `json:"new_email"`
+}
+
+func (UserEmailChanged) EventType() string   { return TypeUserEmailChanged }
+func (e UserEmailChanged) AggregateID() uint { return e.UserID }
+
+// UserEmailVerified is raised when a user proves control of their email address.
+type UserEmailVerified struct {
+	UserID uint   `json:"user_id"`
+	Email  string `json:"email"`
+}
+
+func (UserEmailVerified) EventType() string   { return TypeUserEmailVerified }
+func (e UserEmailVerified) AggregateID() uint { return e.UserID }
+
+// UserDeleted is raised when a user is soft-deleted.
+type UserDeleted struct {
+	UserID uint   `json:"user_id"`
+	Email  string `json:"email"`
+}
+
+func (UserDeleted) EventType() string   { return TypeUserDeleted }
+func (e UserDeleted) AggregateID() uint { return e.UserID }
+
+// Envelope is an event as publishers receive it. ID is unique per event
+// and stable across redeliveries.
+type Envelope struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   uint            `json:"aggregate_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	RequestID     string          `json:"request_id,omitempty"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// Decode unmarshals the payload into a pointer to the event type named by
+// env.Type, such as *UserCreated.
+func Decode(env Envelope) (Event, error) {
+	var e Event
+	switch env.Type {
+	case TypeUserCreated:
+		e = &UserCreated{}
+	case TypeUserEmailChanged:
+		e = &UserEmailChanged{}
+	case TypeUserEmailVerified:
+		e = &UserEmailVerified{}
+	case TypeUserDeleted:
+		e = &UserDeleted{}
+	default:
+		return nil, fmt.Errorf("unknown event type %q", env.Type)
+	}
+	if err := json.Unmarshal(env.Payload, e); err != nil {
+		return nil, fmt.Errorf("decode %s event %s: %w", env.Type, env.ID, err)
+	}
+	return e, nil
+}
+
+// Publisher delivers events. Delivery is at least once: an event may be
+// published again after a failure or crash, so consumers must tolerate
+// duplicates, for example by remembering Envelope.ID. Events of the same
+// aggregate are usually, but not always, published in order.
+type Publisher interface {
+	Publish(ctx context.Context, env Envelope) error
+}
+
+// PublisherFunc adapts a function to Publisher.
+type PublisherFunc func(ctx context.Context, env Envelope) error
+
+func (f PublisherFunc) Publish(ctx context.Context, env Envelope) error { return f(ctx, env) }
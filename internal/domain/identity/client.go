@@ -0,0 +1,17 @@
+package identity
+
+import "context"
+
+type clientIPKey struct{}
+
+// WithClientIP returns a copy of ctx carrying the IP address of the client
+// that sent the request.
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIPFromContext returns the client IP stored in ctx, or "" outside a request.
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
@@ -0,0 +1,22 @@
+package identity
+
+import "context"
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	UserID uint
+	Email  string
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the given principal.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored in ctx, if any.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
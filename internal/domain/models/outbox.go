@@ -0,0 +1,27 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxMessage is a domain event waiting to be published, stored in the
+// same transaction as the change it describes. EventID and AvailableAt
+// default to a random UUID and the insert time.
+type OutboxMessage struct {
+	ID            uint            `gorm:"primarykey"`
+	EventID       string          `gorm:"type:uuid;default:gen_random_uuid()"`
+	EventType     string          `gorm:"not null"`
+	AggregateType string          `gorm:"not null"`
+	AggregateID   uint            `gorm:"not null"`
+	Payload       json.RawMessage `gorm:"type:jsonb;serializer:json"`
+	RequestID     string
+	CreatedAt     time.Time
+	// AvailableAt delays the next publish attempt after a failure
+	AvailableAt time.Time `gorm:"default:now()"`
+	PublishedAt *time.Time
+	Attempts    int
+	LastError   string
+}
+
+func (OutboxMessage) TableName() string { return "outbox_events" }
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// PasswordResetToken records an issued password reset token so it can be
+// used only once. As with RefreshToken, only the token ID (the JWT "jti"
+// claim) is stored.
+type PasswordResetToken struct {
+	ID      uint   `json:"id" gorm:"primarykey"`
+	UserID  uint   `json:"user_id" gorm:"index;not null"`
+	TokenID string `json:"-" gorm:"uniqueIndex;size:64;not null"`
+	// Email is the address the token was sent to
+	Email     string     `json:"email" gorm:"not null"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// RefreshToken records an issued refresh token so it can be revoked
+// before it expires. Only the token ID (the JWT "jti" claim) is stored,
+// never the signed token itself.
+type RefreshToken struct {
+	ID        uint       `json:"id" gorm:"primarykey"`
+	UserID    uint       `json:"user_id" gorm:"index;not null"`
+	TokenID   string     `json:"-" gorm:"uniqueIndex;size:64;not null"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Permission names follow the "<resource>:<action>" convention.
+// New permissions must also be inserted by a migration.
+const (
+	PermUsersRead   = "users:read"
+	PermUsersWrite  = "users:write"
+	PermUsersDelete = "users:delete"
+	PermUsersPurge  = "users:purge"
+	PermRolesRead   = "roles:read"
+	PermRolesAssign = "roles:assign"
+	PermAuditRead   = "audit:read"
+
+	PermWebhooksRead  = "webhooks:read"
+	PermWebhooksWrite = "webhooks:write"
+)
+
+// Built-in role names. The roles and the permissions they grant are
+// seeded by the migrations in migrations/.
+const (
+	RoleAdmin  = "admin"
+	RoleViewer = "viewer"
+)
+
+type Role struct {
+	ID          uint         `json:"id" gorm:"primarykey"`
+	Name        string       `json:"name" gorm:"uniqueIndex;size:64;not null"`
+	Permissions []Permission `json:"permissions,omitempty" gorm:"many2many:role_permissions"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+type Permission struct {
+	ID   uint   `json:"id" gorm:"primarykey"`
+	Name string `json:"name" gorm:"uniqueIndex;size:64;not null"`
+}
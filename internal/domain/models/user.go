@@ -7,10 +7,20 @@ import (
 )
 
 type User struct {
-	ID        uint           `json:"id" gorm:"primarykey"`
-	Name      string         `json:"name" binding:"required,min=2,max=100"`
-	Email     string         `json:"email" binding:"required,email" gorm:"unique"`
+	ID           uint   `json:"id" gorm:"primarykey"`
+	Name         string `json:"name" binding:"required,min=2,max=100"`
+	Email        string `json:"email" binding:"required,email" gorm:"uniqueIndex:idx_users_email_live,where:deleted_at IS NULL"`
+	PasswordHash string `json:"-"`
+	// Password is a new plaintext password for UserService to hash into
+	// PasswordHash; it is never stored or serialized
+	Password string `json:"-" gorm:"-"`
+	// EmailVerifiedAt is when the user proved control of Email; it is reset
+	// whenever Email changes
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	Roles           []Role     `json:"roles,omitempty" gorm:"many2many:user_roles"`
+	// Version increments on every update and backs optimistic concurrency (ETag/If-Match)
+	Version   int64          `json:"version" gorm:"not null;default:1"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 }
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// WebhookAllEvents subscribes a webhook to every event type.
+const WebhookAllEvents = "*"
+
+// Webhook delivery states. A pending delivery is retried with backoff until
+// it succeeds or runs out of attempts and becomes dead.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryDead      = "dead"
+)
+
+// WebhookSubscription asks for events of EventTypes to be POSTed to URL,
+// signed with Secret. Secret is only ever returned when it is created.
+type WebhookSubscription struct {
+	ID          uint      `json:"id" gorm:"primarykey"`
+	URL         string    `json:"url" gorm:"not null"`
+	EventTypes  []string  `json:"event_types" gorm:"type:jsonb;serializer:json;not null"`
+	Description string    `json:"description"`
+	Secret      string    `json:"-" gorm:"not null"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Wants reports whether the subscription receives events of eventType.
+func (s *WebhookSubscription) Wants(eventType string) bool {
+	return slices.Contains(s.EventTypes, eventType) || slices.Contains(s.EventTypes, WebhookAllEvents)
+}
+
+// WebhookDelivery is one event sent, or to be sent, to one subscription.
+// Payload is the request body, sent unchanged on every attempt.
+type WebhookDelivery struct {
+	ID             uint            `json:"id" gorm:"primarykey"`
+	SubscriptionID uint            `json:"subscription_id" gorm:"not null"`
+	EventID        string          `json:"event_id" gorm:"type:uuid;not null"`
+	EventType      string          `json:"event_type" gorm:"not null"`
+	Payload        json.RawMessage `json:"payload" gorm:"type:jsonb;serializer:json"`
+	Status         string          `json:"status" gorm:"not null"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	// ResponseStatus is the HTTP status of the last attempt; zero if no
+	// response was received
+	ResponseStatus int       `json:"response_status,omitempty"`
+	LastError      string    `json:"last_error,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	Subscription *WebhookSubscription `json:"-"`
+}
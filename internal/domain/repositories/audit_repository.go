@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+)
+
+// AuditQuery selects audit entries. Zero fields do not filter.
+type AuditQuery struct {
+	ActorID    *uint
+	Action     string
+	TargetType string
+	TargetID   *uint
+	// Since and Until bound CreatedAt, inclusively
+	Since time.Time
+	Until time.Time
+}
+
+// AuditRepository stores the append-only audit log.
+type AuditRepository interface {
+	// Append seals entries onto the end of the hash chain, in order, and
+	// stores them, setting ID, CreatedAt, PrevHash and Hash. Appends are
+	// serialised, so callers in a transaction hold the chain until commit.
+	Append(ctx context.Context, entries ...*models.AuditEntry) error
+	// List returns up to limit entries matching q with IDs below before,
+	// newest first. A zero before starts from the newest entry.
+	List(ctx context.Context, q AuditQuery, before uint, limit int) ([]models.AuditEntry, error)
+	// Chain returns up to limit entries with IDs above after, oldest first,
+	// for verifying the hash chain.
+	Chain(ctx context.Context, after uint, limit int) ([]models.AuditEntry, error)
+}
@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+)
+
+// EmailVerificationRepository records issued email verification tokens.
+type EmailVerificationRepository interface {
+	Create(ctx context.Context, token *models.EmailVerificationToken) error
+	// Use marks an unused, unexpired token as used and returns it. It
+	// returns nil if there is no such token, which lets callers detect
+	// reuse and races.
+	Use(ctx context.Context, tokenID string) (*models.EmailVerificationToken, error)
+}
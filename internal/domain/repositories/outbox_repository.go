@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+)
+
+type OutboxRepository interface {
+	// Add stores messages for the relay to publish.
+	Add(ctx context.Context, msgs ...*models.OutboxMessage) error
+	// ClaimDue leases up to limit unpublished messages whose AvailableAt
+	// has passed, oldest first, and then calls fn with them. Messages
+	// claimed by a concurrent caller are skipped. Leasing moves AvailableAt
+	// lease into the future, so the messages are claimed again if the
+	// caller dies. Changes fn makes to PublishedAt, Attempts, LastError and
+	// AvailableAt are saved after fn returns unless the lease ran out and
+	// the message was claimed again; if fn fails, nothing is saved.
+	// ClaimDue reports how many were claimed.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration, fn func(msgs []models.OutboxMessage) error) (int, error)
+	// DeletePublishedBefore removes messages published before cutoff.
+	DeletePublishedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
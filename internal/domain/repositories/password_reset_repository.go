@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+)
+
+// PasswordResetRepository records issued password reset tokens.
+type PasswordResetRepository interface {
+	Create(ctx context.Context, token *models.PasswordResetToken) error
+	// Use marks an unused, unexpired token as used and returns it. It
+	// returns nil if there is no such token, which lets callers detect
+	// reuse and races.
+	Use(ctx context.Context, tokenID string) (*models.PasswordResetToken, error)
+	// UseAllForUser marks every unused token of the user as used, so links
+	// sent before a password change stop working.
+	UseAllForUser(ctx context.Context, userID uint) error
+}
@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+)
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	GetByTokenID(ctx context.Context, tokenID string) (*models.RefreshToken, error)
+	// Revoke marks a token as revoked. It reports false if the token was
+	// unknown or already revoked, which lets callers detect races.
+	Revoke(ctx context.Context, tokenID string) (bool, error)
+	RevokeAllForUser(ctx context.Context, userID uint) error
+}
@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+)
+
+type RoleRepository interface {
+	List(ctx context.Context) ([]models.Role, error)
+	GetByName(ctx context.Context, name string) (*models.Role, error)
+	ListForUser(ctx context.Context, userID uint) ([]models.Role, error)
+	Assign(ctx context.Context, userID uint, role *models.Role) error
+	Unassign(ctx context.Context, userID uint, role *models.Role) error
+	UserHasPermission(ctx context.Context, userID uint, permission string) (bool, error)
+}
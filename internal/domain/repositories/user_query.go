@@ -0,0 +1,56 @@
+package repositories
+
+// UserField names a user attribute that can be filtered or sorted on.
+// Implementations must map fields to columns through a whitelist.
+type UserField string
+
+const (
+	UserFieldID        UserField = "id"
+	UserFieldName      UserField = "name"
+	UserFieldEmail     UserField = "email"
+	UserFieldCreatedAt UserField = "created_at"
+)
+
+type FilterOp string
+
+const (
+	OpEq     FilterOp = "eq"
+	OpPrefix FilterOp = "prefix"
+	OpIn     FilterOp = "in"
+	OpGt     FilterOp = "gt"
+	OpGte    FilterOp = "gte"
+	OpLt     FilterOp = "lt"
+	OpLte    FilterOp = "lte"
+)
+
+// UserFilter restricts Field with Op. Values hold already-typed operands
+// (string or time.Time); every op except OpIn takes exactly one.
+type UserFilter struct {
+	Field  UserField
+	Op     FilterOp
+	Values []any
+}
+
+type SortKey struct {
+	Field UserField
+	Desc  bool
+}
+
+// DeletedScope selects how soft-deleted users are treated.
+type DeletedScope string
+
+const (
+	DeletedExclude DeletedScope = "exclude"
+	DeletedInclude DeletedScope = "include"
+	DeletedOnly    DeletedScope = "only"
+)
+
+// UserQuery is a typed filter and sort specification for listing users.
+// The zero value lists live users in ID order.
+type UserQuery struct {
+	Filters []UserFilter
+	// Search matches a case-insensitive substring of name or email
+	Search  string
+	Sort    []SortKey
+	Deleted DeletedScope
+}
@@ -1,15 +1,79 @@
 package repositories
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
-	"github.com/gin-gonic/gin"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/jobs"
 )
 
+// ErrVersionConflict is returned when a conditional write finds the row at a
+// different version than the caller read.
+var ErrVersionConflict = errors.New("version conflict")
+
+// ErrEmailTaken is returned when a write would give a live user the email
+// of another live user.
+var ErrEmailTaken = errors.New("email taken by a live user")
+
 type UserRepository interface {
-	List(c *gin.Context, page, limit int) ([]models.User, int64, error)
-	GetByID(c *gin.Context, id uint) (*models.User, error)
-	GetByEmail(c *gin.Context, email string) (*models.User, error)
-	Create(c *gin.Context, user *models.User) error
-	Update(c *gin.Context, user *models.User) error
-	Delete(c *gin.Context, id uint) error
+	List(ctx context.Context, q UserQuery, page, limit int) ([]models.User, int64, error)
+	// ListAfter returns up to limit users matching q that sort after the
+	// keyset position after, which holds one value per key of q.Sort.
+	// A nil after starts from the beginning. q.Sort must end with a unique key.
+	ListAfter(ctx context.Context, q UserQuery, after []any, limit int) ([]models.User, error)
+	Count(ctx context.Context, q UserQuery) (int64, error)
+	// EstimateCount returns the planner's row estimate, which avoids a full scan.
+	EstimateCount(ctx context.Context, q UserQuery) (int64, error)
+	GetByID(ctx context.Context, id uint) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	Create(ctx context.Context, user *models.User) error
+	// Update writes user only if the stored version still equals user.Version,
+	// then increments user.Version. Otherwise it returns ErrVersionConflict.
+	Update(ctx context.Context, user *models.User) error
+	// ReplacePasswordHash swaps the user's password hash for an equivalent
+	// one if it still equals old. The version is not bumped because the
+	// hash is not part of the user's representation.
+	ReplacePasswordHash(ctx context.Context, id uint, old, hash string) error
+	// Delete soft-deletes the user if it is still at the given version.
+	// Otherwise it returns ErrVersionConflict.
+	Delete(ctx context.Context, id uint, version int64) error
+
+	// GetDeletedByID returns a soft-deleted user, or nil if there is none with that ID.
+	GetDeletedByID(ctx context.Context, id uint) (*models.User, error)
+	// Restore clears the deletion mark of a soft-deleted user and bumps its
+	// version. It returns ErrEmailTaken if a live user has the same email.
+	Restore(ctx context.Context, user *models.User) error
+	// Purge permanently removes a soft-deleted user and data that belongs only to it.
+	Purge(ctx context.Context, id uint) error
+	// PurgeDeletedBefore permanently removes up to limit users soft-deleted
+	// before cutoff and returns them as they were before removal.
+	PurgeDeletedBefore(ctx context.Context, cutoff time.Time, limit int) ([]models.User, error)
+	// CreateBatch inserts users in bulk and sets their IDs.
+	CreateBatch(ctx context.Context, users []models.User) error
+	// ExistingEmails returns those of emails that belong to live users.
+	ExistingEmails(ctx context.Context, emails []string) ([]string, error)
+	// Transaction runs fn with a repository whose operations share one
+	// transaction, committed if fn returns nil and rolled back otherwise.
+	Transaction(ctx context.Context, fn func(repo UserRepository) error) error
+	// Audit returns the audit log on the same connection or transaction as
+	// this repository, so entries commit together with the changes they record.
+	Audit() AuditRepository
+	// Outbox returns the event outbox on the same connection or transaction
+	// as this repository, so events are stored only if the change commits.
+	Outbox() OutboxRepository
+	// Jobs enqueues background jobs on the same connection or transaction
+	// as this repository, so a job exists only if the change commits.
+	Jobs() jobs.Inserter
+	// EmailVerifications returns the verification tokens on the same
+	// connection or transaction as this repository.
+	EmailVerifications() EmailVerificationRepository
+	// PasswordResets returns the password reset tokens on the same
+	// connection or transaction as this repository.
+	PasswordResets() PasswordResetRepository
+	// RefreshTokens returns the refresh tokens on the same connection or
+	// transaction as this repository, so sessions end together with the
+	// change that ends them.
+	RefreshTokens() RefreshTokenRepository
 }
@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+)
+
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, sub *models.WebhookSubscription) error
+	// GetSubscription returns nil if there is no subscription with id.
+	GetSubscription(ctx context.Context, id uint) (*models.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	// ListSubscribers returns the active subscriptions that want eventType.
+	ListSubscribers(ctx context.Context, eventType string) ([]models.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, sub *models.WebhookSubscription) error
+	// DeleteSubscription removes a subscription and its deliveries. It
+	// reports whether the subscription existed.
+	DeleteSubscription(ctx context.Context, id uint) (bool, error)
+
+	// AddDeliveries stores deliveries to be sent. A delivery of an event
+	// the subscription already has is ignored, so enqueueing is idempotent.
+	AddDeliveries(ctx context.Context, deliveries ...*models.WebhookDelivery) error
+	// ClaimDueDeliveries leases up to limit pending deliveries of active
+	// subscriptions whose NextAttemptAt has passed, oldest first, and then
+	// calls fn with them, with Subscription loaded. Deliveries claimed by a
+	// concurrent caller are skipped. Leasing moves NextAttemptAt lease into
+	// the future, so the deliveries are claimed again if the caller dies.
+	// Changes fn makes to Status, Attempts, NextAttemptAt, LastAttemptAt,
+	// ResponseStatus and LastError are saved after fn returns unless the
+	// lease ran out and the delivery was claimed again; if fn fails,
+	// nothing is saved.
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration, fn func(deliveries []models.WebhookDelivery) error) (int, error)
+	// GetDelivery returns nil if the subscription has no delivery with id.
+	GetDelivery(ctx context.Context, subscriptionID, id uint) (*models.WebhookDelivery, error)
+	// ListDeliveries returns the subscription's deliveries with IDs below
+	// before (any, if zero) and the given status (any, if empty), newest first.
+	ListDeliveries(ctx context.Context, subscriptionID uint, status string, before uint, limit int) ([]models.WebhookDelivery, error)
+	// UpdateDelivery saves the same fields ClaimDueDeliveries does.
+	UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	// DeleteFinishedBefore removes succeeded and dead deliveries last
+	// updated before cutoff.
+	DeleteFinishedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
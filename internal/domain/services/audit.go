@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/identity"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/requestid"
+)
+
+// redacted stands in for secret values in audit diffs; the entry still
+// shows that they changed.
+const redacted = "[redacted]"
+
+// userAuditFields lists the audited user fields in a stable order.
+var userAuditFields = []string{"name", "email", "email_verified_at", "password", "deleted_at"}
+
+// newAuditEntry describes a change made on behalf of the caller in ctx.
+func newAuditEntry(ctx context.Context, action string, targetID uint, changes models.AuditChanges) *models.AuditEntry {
+	entry := &models.AuditEntry{
+		Action:     action,
+		TargetType: models.AuditTargetUser,
+		TargetID:   &targetID,
+		Changes:    changes,
+		RequestID:  requestid.FromContext(ctx),
+		IP:         identity.ClientIPFromContext(ctx),
+	}
+	switch principal, ok := identity.FromContext(ctx); {
+	case ok:
+		entry.ActorType, entry.ActorID = models.AuditActorUser, &principal.UserID
+	case entry.IP != "":
+		entry.ActorType = models.AuditActorAnonymous
+	default:
+		entry.ActorType = models.AuditActorSystem
+	}
+	return entry
+}
+
+// userChanges returns the audited fields that differ between before and
+// after; either may be nil for a user that does not exist on that side.
+// Password hashes are compared but never recorded.
+func userChanges(before, after *models.User) models.AuditChanges {
+	b, a := userAuditValues(before), userAuditValues(after)
+	changes := models.AuditChanges{}
+	for _, field := range userAuditFields {
+		if b[field] == a[field] {
+			continue
+		}
+		change := models.AuditChange{Before: b[field], After: a[field]}
+		if field == "password" {
+			change = models.AuditChange{Before: redact(b[field]), After: redact(a[field])}
+		}
+		changes[field] = change
+	}
+	return changes
+}
+
+func userAuditValues(u *models.User) map[string]any {
+	values := map[string]any{}
+	if u == nil {
+		return values
+	}
+	values["name"] = u.Name
+	values["email"] = u.Email
+	if u.EmailVerifiedAt != nil {
+		values["email_verified_at"] = u.EmailVerifiedAt.UTC().Format(time.RFC3339Nano)
+	}
+	if u.PasswordHash != "" {
+		values["password"] = u.PasswordHash
+	}
+	if u.DeletedAt.Valid {
+		values["deleted_at"] = u.DeletedAt.Time.UTC().Format(time.RFC3339Nano)
+	}
+	return values
+}
+
+func redact(v any) any {
+	if v == nil {
+		return nil
+	}
+	return redacted
+}
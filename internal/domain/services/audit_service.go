@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/apperror"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/logger"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/tracing"
+)
+
+// auditVerifyPageSize is how many entries VerifyAuditChain reads at a time.
+const auditVerifyPageSize = 1000
+
+// AuditService exposes the audit log written by UserService.
+type AuditService interface {
+	// ListEntries returns the page of entries matching q.Query with IDs
+	// below q.Before, newest first.
+	ListEntries(ctx context.Context, q AuditPageQuery) (*AuditPage, error)
+	// VerifyAuditChain recomputes every entry's hash and checks the links
+	// between them. Removing the newest entries leaves a valid, shorter
+	// chain; compare Head with a previously recorded value to detect that.
+	VerifyAuditChain(ctx context.Context) (*AuditVerification, error)
+}
+
+type AuditPageQuery struct {
+	Query  repositories.AuditQuery
+	Before uint
+	Limit  int
+}
+
+// AuditPage is one page of audit entries. NextBefore is zero on the last page.
+type AuditPage struct {
+	Entries    []models.AuditEntry
+	Limit      int
+	NextBefore uint
+}
+
+// AuditVerification is the result of checking the hash chain. BrokenAt is
+// the first entry that does not match its hash or does not link to the
+// entry before it.
+type AuditVerification struct {
+	Valid    bool   `json:"valid"`
+	Checked  int64  `json:"checked"`
+	Head     string `json:"head,omitempty"`
+	BrokenAt *uint  `json:"broken_at,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+type auditServiceImpl struct {
+	auditRepo repositories.AuditRepository
+}
+
+func NewAuditService(auditRepo repositories.AuditRepository) AuditService {
+	return &auditServiceImpl{auditRepo: auditRepo}
+}
+
+// ParseAuditQuery builds an AuditQuery from the actor_id, action,
+// target_type, target_id, since and until query parameters. Times are
+// RFC 3339 or YYYY-MM-DD. All problems are reported together as field
+// errors on ErrValidationFailed.
+func ParseAuditQuery(params url.Values) (repositories.AuditQuery, error) {
+	q := repositories.AuditQuery{Action: params.Get("action"), TargetType: params.Get("target_type")}
+	var problems []apperror.FieldError
+	id := func(field string) *uint {
+		value := params.Get(field)
+		if value == "" {
+			return nil
+		}
+		n, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			problems = append(problems, apperror.FieldError{Field: field, Code: "type", Message: "must be a positive integer"})
+			return nil
+		}
+		id := uint(n)
+		return &id
+	}
+	instant := func(field string) time.Time {
+		value := params.Get(field)
+		if value == "" {
+			return time.Time{}
+		}
+		t, err := parseTime(value)
+		if err != nil {
+			problems = append(problems, apperror.FieldError{Field: field, Code: "type", Message: err.Error()})
+		}
+		return t
+	}
+	q.ActorID = id("actor_id")
+	q.TargetID = id("target_id")
+	q.Since = instant("since")
+	q.Until = instant("until")
+	if len(problems) > 0 {
+		return q, ErrValidationFailed.WithFields(problems...)
+	}
+	return q, nil
+}
+
+func (s *auditServiceImpl) ListEntries(ctx context.Context, q AuditPageQuery) (_ *AuditPage, err error) {
+	ctx, end := tracing.Start(ctx, "AuditService.ListEntries")
+	defer end(&err)
+	limit, err := checkLimit(q.Limit)
+	if err != nil {
+		return nil, err
+	}
+	// Fetch one extra row to learn whether another page follows
+	entries, err := s.auditRepo.List(ctx, q.Query, q.Before, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	page := &AuditPage{Entries: entries, Limit: limit}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		page.NextBefore = page.Entries[limit-1].ID
+	}
+	return page, nil
+}
+
+func (s *auditServiceImpl) VerifyAuditChain(ctx context.Context) (_ *AuditVerification, err error) {
+	ctx, end := tracing.Start(ctx, "AuditService.VerifyAuditChain")
+	defer end(&err)
+	result := &AuditVerification{Valid: true}
+	var after uint
+	for {
+		entries, err := s.auditRepo.Chain(ctx, after, auditVerifyPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if reason := checkLink(e, result.Head); reason != "" {
+				id := e.ID
+				result.Valid, result.BrokenAt, result.Reason = false, &id, reason
+				logger.FromContext(ctx).Errorw("Audit chain broken", "entry_id", id, "reason", reason)
+				return result, nil
+			}
+			result.Checked++
+			result.Head = e.Hash
+			after = e.ID
+		}
+		if len(entries) < auditVerifyPageSize {
+			return result, nil
+		}
+	}
+}
+
+// checkLink describes what is wrong with e given the hash of the entry
+// before it, or returns "" if e is intact.
+func checkLink(e models.AuditEntry, prevHash string) string {
+	if e.PrevHash != prevHash {
+		return "prev_hash does not match the previous entry; an entry was removed or reordered"
+	}
+	hash, err := e.ComputeHash()
+	if err != nil {
+		return fmt.Sprintf("entry cannot be hashed: %v", err)
+	}
+	if hash != e.Hash {
+		return "hash does not match the entry's contents; the entry was modified"
+	}
+	return ""
+}
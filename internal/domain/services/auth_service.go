@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/apperror"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/auth"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/logger"
+)
+
+var (
+	ErrInvalidCredentials = apperror.New(apperror.KindUnauthorized, "invalid_credentials", "invalid email or password")
+	ErrInvalidToken       = apperror.New(apperror.KindUnauthorized, "invalid_token", "invalid or expired token")
+)
+
+// TokenPair is returned on login and refresh.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"` // access token lifetime in seconds
+}
+
+type AuthService interface {
+	Login(ctx context.Context, email, password string) (*TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*TokenPair, error)
+	Logout(ctx context.Context, refreshToken string) error
+}
+
+type authServiceImpl struct {
+	userRepo  repositories.UserRepository
+	tokenRepo repositories.RefreshTokenRepository
+	tokens    *auth.JWTManager
+	passwords *auth.PasswordHasher
+}
+
+func NewAuthService(userRepo repositories.UserRepository, tokenRepo repositories.RefreshTokenRepository, tokens *auth.JWTManager, passwords *auth.PasswordHasher) AuthService {
+	return &authServiceImpl{userRepo: userRepo, tokenRepo: tokenRepo, tokens: tokens, passwords: passwords}
+}
+
+// Login checks the password and, if the stored hash was made with older
+// hashing settings, replaces it with one made with the current settings.
+func (s *authServiceImpl) Login(ctx context.Context, email, password string) (*TokenPair, error) {
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	var ok, rehash bool
+	if user != nil && user.PasswordHash != "" {
+		if ok, rehash, err = s.passwords.Verify(ctx, user.PasswordHash, password); err != nil {
+			return nil, err
+		}
+	} else {
+		// Hash anyway, so unknown emails take as long as wrong passwords
+		s.passwords.VerifyNothing(ctx, password)
+	}
+	if !ok {
+		logger.FromContext(ctx).Infow("Login failed", "email", email)
+		return nil, ErrInvalidCredentials
+	}
+	if rehash {
+		s.rehash(ctx, user, password)
+	}
+	return s.issueTokens(ctx, user)
+}
+
+// rehash upgrades the user's password hash. Failing to is logged but does
+// not fail the login; the next one tries again.
+func (s *authServiceImpl) rehash(ctx context.Context, user *models.User, password string) {
+	hash, err := s.passwords.Hash(ctx, password)
+	if err == nil {
+		err = s.userRepo.ReplacePasswordHash(ctx, user.ID, user.PasswordHash, hash)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Warnw("Failed to rehash password", "user_id", user.ID, "error", err)
+		return
+	}
+	logger.FromContext(ctx).Infow("Password rehashed", "user_id", user.ID)
+}
+
+// Refresh rotates a refresh token: the presented token is revoked and a new
+// pair is issued. Presenting an already revoked token is treated as theft
+// and revokes every outstanding refresh token of that user.
+func (s *authServiceImpl) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	claims, err := s.tokens.ParseRefreshToken(refreshToken)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	stored, err := s.tokenRepo.GetByTokenID(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return nil, ErrInvalidToken
+	}
+	if stored.RevokedAt != nil {
+		logger.FromContext(ctx).Warnw("Revoked refresh token reused; revoking all sessions",
+			"user_id", stored.UserID)
+		if err := s.tokenRepo.RevokeAllForUser(ctx, stored.UserID); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidToken
+	}
+
+	revoked, err := s.tokenRepo.Revoke(ctx, stored.TokenID)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked { // Lost a race against a concurrent refresh or logout
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, stored.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidToken
+	}
+	return s.issueTokens(ctx, user)
+}
+
+// Logout revokes the given refresh token. Revoking an already revoked token is not an error.
+func (s *authServiceImpl) Logout(ctx context.Context, refreshToken string) error {
+	claims, err := s.tokens.ParseRefreshToken(refreshToken)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	_, err = s.tokenRepo.Revoke(ctx, claims.ID)
+	return err
+}
+
+func (s *authServiceImpl) issueTokens(ctx context.Context, user *models.User) (*TokenPair, error) {
+	accessToken, _, err := s.tokens.GenerateAccessToken(user)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, refreshClaims, err := s.tokens.GenerateRefreshToken(user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tokenRepo.Create(ctx, &models.RefreshToken{
+		UserID:    user.ID,
+		TokenID:   refreshClaims.ID,
+		ExpiresAt: refreshClaims.ExpiresAt.Time,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.tokens.AccessTTL().Seconds()),
+	}, nil
+}
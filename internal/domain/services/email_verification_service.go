@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/apperror"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/auth"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/logger"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/mailer"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/tracing"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/jobs"
+)
+
+var (
+	ErrEmailAlreadyVerified     = apperror.New(apperror.KindConflict, "email_already_verified", "email address is already verified")
+	ErrInvalidVerificationToken = apperror.New(apperror.KindInvalid, "invalid_verification_token", "invalid, expired or already used verification token")
+)
+
+const verificationEmailText = `Hi %s,
+
+Please confirm your email address by opening this link:
+
+%s
+
+The link can be used once and expires at %s. If you did not sign up, you can ignore this email.
+`
+
+// EmailVerificationService proves that users control their email address.
+// Verification emails are sent by the worker: SendVerification, CreateUser
+// and email changes in UpdateUser only queue them.
+type EmailVerificationService interface {
+	// SendVerification queues a verification email to the user's current address.
+	SendVerification(ctx context.Context, userID uint) error
+	// VerifyEmail uses up a verification token and marks the address it was
+	// sent to as verified, provided it is still the user's address.
+	VerifyEmail(ctx context.Context, token string) (*models.User, error)
+	// DeliverVerification issues a token and mails the link. It is the
+	// handler of SendVerificationEmailArgs jobs.
+	DeliverVerification(ctx context.Context, args SendVerificationEmailArgs) error
+}
+
+type EmailVerificationOptions struct {
+	// Mailer sends the emails; only the worker needs one
+	Mailer mailer.Mailer
+	// VerifyURL is where links point; the token is added as the token query parameter
+	VerifyURL string
+}
+
+type emailVerificationServiceImpl struct {
+	userRepo repositories.UserRepository
+	tokens   *auth.JWTManager
+	opts     EmailVerificationOptions
+}
+
+func NewEmailVerificationService(userRepo repositories.UserRepository, tokens *auth.JWTManager, opts EmailVerificationOptions) EmailVerificationService {
+	return &emailVerificationServiceImpl{userRepo: userRepo, tokens: tokens, opts: opts}
+}
+
+func (s *emailVerificationServiceImpl) SendVerification(ctx context.Context, userID uint) (err error) {
+	ctx, end := tracing.Start(ctx, "EmailVerificationService.SendVerification", userIDAttr(userID))
+	defer end(&err)
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+	return enqueueVerificationEmail(ctx, s.userRepo, user)
+}
+
+func (s *emailVerificationServiceImpl) VerifyEmail(ctx context.Context, token string) (_ *models.User, err error) {
+	ctx, end := tracing.Start(ctx, "EmailVerificationService.VerifyEmail")
+	defer end(&err)
+	claims, err := s.tokens.ParseEmailVerificationToken(token)
+	if err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+	userID, err := claims.UserID()
+	if err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	var user *models.User
+	err = s.userRepo.Transaction(ctx, func(repo repositories.UserRepository) error {
+		stored, err := repo.EmailVerifications().Use(ctx, claims.ID)
+		if err != nil {
+			return err
+		}
+		if stored == nil || stored.UserID != userID {
+			return ErrInvalidVerificationToken
+		}
+		user, err = repo.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		// The address changed since the token was sent
+		if user == nil || user.Email != stored.Email {
+			return ErrInvalidVerificationToken
+		}
+		if user.EmailVerifiedAt != nil {
+			return nil
+		}
+		before := *user
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		if err := repo.Update(ctx, user); err != nil {
+			return err
+		}
+		return recordUserChanges(ctx, repo, models.AuditUserEmailVerified, userChange{&before, user})
+	})
+	if err != nil {
+		if errors.Is(err, repositories.ErrVersionConflict) { // The user changed concurrently
+			return nil, ErrVersionMismatch
+		}
+		return nil, err
+	}
+	logger.FromContext(ctx).Infow("Email verified", "user_id", user.ID)
+	return user, nil
+}
+
+func (s *emailVerificationServiceImpl) DeliverVerification(ctx context.Context, args SendVerificationEmailArgs) (err error) {
+	ctx, end := tracing.Start(ctx, "EmailVerificationService.DeliverVerification", userIDAttr(args.UserID))
+	defer end(&err)
+	if s.opts.Mailer == nil {
+		return jobs.Permanent(errors.New("no mailer configured"))
+	}
+	user, err := s.userRepo.GetByID(ctx, args.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil || user.Email != args.Email || user.EmailVerifiedAt != nil {
+		logger.FromContext(ctx).Infow("Skipping outdated verification email", "user_id", args.UserID)
+		return nil
+	}
+
+	token, claims, err := s.tokens.GenerateEmailVerificationToken(user)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.EmailVerifications().Create(ctx, &models.EmailVerificationToken{
+		UserID:    user.ID,
+		TokenID:   claims.ID,
+		Email:     user.Email,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}); err != nil {
+		return err
+	}
+	link := s.opts.VerifyURL + "?token=" + url.QueryEscape(token)
+	return s.opts.Mailer.Send(ctx, mailer.Message{
+		To:      []string{user.Email},
+		Subject: "Verify your email address",
+		Text:    fmt.Sprintf(verificationEmailText, user.Name, link, claims.ExpiresAt.UTC().Format(time.RFC1123)),
+	})
+}
+
+// enqueueVerificationEmail queues a verification email to the user's
+// current address through repo, so inside a transaction it is sent only if
+// the transaction commits. An email already waiting to be sent to the same
+// address is not queued again.
+func enqueueVerificationEmail(ctx context.Context, repo repositories.UserRepository, user *models.User) error {
+	_, err := jobs.Enqueue(ctx, repo.Jobs(), SendVerificationEmailArgs{UserID: user.ID, Email: user.Email}, jobs.Options{
+		UniqueKey: fmt.Sprintf("verify_email:%d:%s", user.ID, user.Email),
+	})
+	return err
+}
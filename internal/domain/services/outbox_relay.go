@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/events"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/logger"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/tracing"
+)
+
+const (
+	// maxOutboxRetryDelay caps the exponential backoff between attempts
+	maxOutboxRetryDelay = time.Hour
+	// maxOutboxErrorLength bounds the publisher error stored with a message
+	maxOutboxErrorLength = 1000
+	// outboxCleanupInterval is how often Run deletes old published messages
+	outboxCleanupInterval = time.Hour
+)
+
+type OutboxRelayOptions struct {
+	// BatchSize is how many messages one RunOnce claims
+	BatchSize int
+	// PollInterval is how long Run waits after finding fewer than BatchSize messages
+	PollInterval time.Duration
+	// Lease is how long a claimed batch is reserved for this relay. A batch
+	// still publishing when it runs out may be published again elsewhere.
+	Lease time.Duration
+	// Retention is how long published messages are kept; zero keeps them forever
+	Retention time.Duration
+}
+
+// OutboxRelay publishes the events stored in the outbox. Any number of
+// relays may run against the same database: each claims a disjoint batch.
+// A message whose publish fails is retried with exponential backoff until
+// it succeeds; Attempts and LastError show why it is stuck.
+type OutboxRelay struct {
+	outbox    repositories.OutboxRepository
+	publisher events.Publisher
+	opts      OutboxRelayOptions
+	now       func() time.Time
+}
+
+func NewOutboxRelay(outbox repositories.OutboxRepository, publisher events.Publisher, opts OutboxRelayOptions) *OutboxRelay {
+	return &OutboxRelay{outbox: outbox, publisher: publisher, opts: opts, now: time.Now}
+}
+
+// RunOnce publishes one batch of due messages and reports how many it
+// claimed, whether or not they were published.
+func (r *OutboxRelay) RunOnce(ctx context.Context) (_ int, err error) {
+	ctx, end := tracing.Start(ctx, "OutboxRelay.RunOnce")
+	defer end(&err)
+	return r.outbox.ClaimDue(ctx, r.opts.BatchSize, r.opts.Lease, func(msgs []models.OutboxMessage) error {
+		for i := range msgs {
+			r.publish(ctx, &msgs[i])
+		}
+		return nil
+	})
+}
+
+func (r *OutboxRelay) publish(ctx context.Context, msg *models.OutboxMessage) {
+	err := r.publisher.Publish(ctx, events.Envelope{
+		ID:            msg.EventID,
+		Type:          msg.EventType,
+		AggregateType: msg.AggregateType,
+		AggregateID:   msg.AggregateID,
+		OccurredAt:    msg.CreatedAt,
+		RequestID:     msg.RequestID,
+		Payload:       msg.Payload,
+	})
+	now := r.now()
+	msg.Attempts++
+	if err == nil {
+		msg.PublishedAt, msg.LastError = &now, ""
+		return
+	}
+	msg.LastError = err.Error()
+	if len(msg.LastError) > maxOutboxErrorLength {
+		msg.LastError = msg.LastError[:maxOutboxErrorLength]
+	}
+	msg.AvailableAt = now.Add(outboxRetryDelay(msg.Attempts))
+	logger.FromContext(ctx).Warnw("Publishing event failed",
+		"event_id", msg.EventID, "event_type", msg.EventType, "attempts", msg.Attempts,
+		"retry_at", msg.AvailableAt, "error", err)
+}
+
+// outboxRetryDelay doubles from one second with each failed attempt.
+func outboxRetryDelay(attempts int) time.Duration {
+	if attempts > 12 { // 2^12s already exceeds the cap
+		return maxOutboxRetryDelay
+	}
+	return min(time.Second<<(attempts-1), maxOutboxRetryDelay)
+}
+
+// Run publishes until ctx is cancelled. It drains full batches back to back
+// and otherwise polls every PollInterval. Old published messages are
+// deleted once an hour.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	log := logger.FromContext(ctx)
+	lastCleanup := time.Time{}
+	for {
+		n, err := r.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Errorw("Outbox relay failed", "error", err)
+		}
+		if r.opts.Retention > 0 && r.now().Sub(lastCleanup) >= outboxCleanupInterval {
+			lastCleanup = r.now()
+			if _, err := r.outbox.DeletePublishedBefore(ctx, lastCleanup.Add(-r.opts.Retention)); err != nil && ctx.Err() == nil {
+				log.Errorw("Outbox cleanup failed", "error", err)
+			}
+		}
+		if err == nil && n == r.opts.BatchSize {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.opts.PollInterval):
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/apperror"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+)
+
+const (
+	DefaultPageLimit = 10
+	// MaxPageLimit caps the page size regardless of what the caller asks for
+	MaxPageLimit = 100
+)
+
+var ErrInvalidCursor = apperror.New(apperror.KindInvalid, "invalid_cursor", "Invalid pagination cursor")
+
+// CountMode selects how a cursor page reports the total number of users.
+type CountMode string
+
+const (
+	CountNone     CountMode = "none"
+	CountExact    CountMode = "exact"
+	CountEstimate CountMode = "estimate"
+)
+
+// CursorQuery requests the page of users matching Query that follows the
+// After cursor. An empty After starts from the beginning.
+type CursorQuery struct {
+	Query repositories.UserQuery
+	After string
+	Limit int
+	Count CountMode
+}
+
+// CursorPage is one page of a keyset listing. NextCursor is empty on the last page.
+type CursorPage struct {
+	Users          []models.User
+	Limit          int
+	NextCursor     string
+	HasMore        bool
+	Total          *int64
+	TotalEstimated bool
+}
+
+// userCursor is the keyset position, encoded opaquely for clients. It holds
+// the sort values of the last row seen and the sort they belong to, so a
+// cursor cannot be replayed against a different ordering.
+type userCursor struct {
+	Sort string            `json:"s"`
+	Keys []json.RawMessage `json:"k"`
+}
+
+func sortSignature(sort []repositories.SortKey) string {
+	parts := make([]string, len(sort))
+	for i, key := range sort {
+		parts[i] = string(key.Field)
+		if key.Desc {
+			parts[i] = "-" + parts[i]
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func encodeCursor(sort []repositories.SortKey, last models.User) string {
+	cur := userCursor{Sort: sortSignature(sort)}
+	for _, v := range keysetValues(sort, last) {
+		raw, _ := json.Marshal(v)
+		cur.Keys = append(cur.Keys, raw)
+	}
+	raw, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// keysetValues returns the sort key values of u, as ListAfter expects them.
+func keysetValues(sort []repositories.SortKey, u models.User) []any {
+	values := make([]any, len(sort))
+	for i, key := range sort {
+		switch key.Field {
+		case repositories.UserFieldID:
+			values[i] = u.ID
+		case repositories.UserFieldName:
+			values[i] = u.Name
+		case repositories.UserFieldEmail:
+			values[i] = u.Email
+		case repositories.UserFieldCreatedAt:
+			values[i] = u.CreatedAt
+		}
+	}
+	return values
+}
+
+// decodeCursor returns the keyset position in s, or nil for an empty cursor.
+func decodeCursor(s string, sort []repositories.SortKey) ([]any, error) {
+	if s == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor.Wrap(err)
+	}
+	var cur userCursor
+	if err := json.Unmarshal(raw, &cur); err != nil {
+		return nil, ErrInvalidCursor.Wrap(err)
+	}
+	if cur.Sort != sortSignature(sort) || len(cur.Keys) != len(sort) {
+		return nil, ErrInvalidCursor.Wrap(errors.New("cursor belongs to a different sort"))
+	}
+
+	after := make([]any, len(sort))
+	for i, key := range sort {
+		var err error
+		switch key.Field {
+		case repositories.UserFieldID:
+			var id uint
+			err = json.Unmarshal(cur.Keys[i], &id)
+			after[i] = id
+		case repositories.UserFieldCreatedAt:
+			var t time.Time
+			err = json.Unmarshal(cur.Keys[i], &t)
+			after[i] = t
+		default:
+			var str string
+			err = json.Unmarshal(cur.Keys[i], &str)
+			after[i] = str
+		}
+		if err != nil {
+			return nil, ErrInvalidCursor.Wrap(err)
+		}
+	}
+	return after, nil
+}
+
+// checkLimit rejects non-positive limits and caps large ones at MaxPageLimit.
+func checkLimit(limit int) (int, error) {
+	if limit < 1 {
+		return 0, ErrValidationFailed.WithFields(apperror.FieldError{
+			Field: "limit", Code: "min", Message: "must be at least 1",
+		})
+	}
+	if limit > MaxPageLimit {
+		return MaxPageLimit, nil
+	}
+	return limit, nil
+}
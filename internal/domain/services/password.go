@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/apperror"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/auth"
+)
+
+var ErrWeakPassword = apperror.New(apperror.KindInvalid, "weak_password", "password does not meet the password policy")
+
+// DefaultPasswordMinLength is used when PasswordPolicy.MinLength is zero.
+const DefaultPasswordMinLength = 12
+
+// commonPasswords are rejected regardless of case and of digits or
+// punctuation appended to them.
+var commonPasswords = map[string]bool{
+	"password": true, "passw0rd": true, "qwerty": true, "qwertyuiop": true, "asdfghjkl": true,
+	"letmein": true, "iloveyou": true, "welcome": true, "admin": true, "administrator": true,
+	"monkey": true, "dragon": true, "football": true, "baseball": true, "sunshine": true,
+	"princess": true, "trustno1": true, "changeme": true, "secret": true, "abc123": true,
+}
+
+// PasswordPolicy decides which passwords users may choose. Following NIST
+// SP 800-63B it asks for length and rejects guessable passwords instead of
+// requiring character classes.
+type PasswordPolicy struct {
+	// MinLength is in characters and defaults to DefaultPasswordMinLength
+	MinLength int
+}
+
+// Validate returns the policy's objections to password for user, reported
+// against field; none means the password is acceptable.
+func (p PasswordPolicy) Validate(field, password string, user *models.User) []apperror.FieldError {
+	var problems []apperror.FieldError
+	fail := func(code, message string) {
+		problems = append(problems, apperror.FieldError{Field: field, Code: code, Message: message})
+	}
+	minLength := p.MinLength
+	if minLength == 0 {
+		minLength = DefaultPasswordMinLength
+	}
+	if utf8.RuneCountInString(password) < minLength {
+		fail("too_short", "must be at least "+strconv.Itoa(minLength)+" characters")
+		return problems
+	}
+
+	lower := strings.ToLower(password)
+	first, _ := utf8.DecodeRuneInString(lower)
+	if commonPasswords[strings.TrimRightFunc(lower, func(r rune) bool { return !unicode.IsLetter(r) })] ||
+		strings.Trim(lower, string(first)) == "" || isSequence(lower) {
+		fail("too_common", "is too easy to guess")
+	}
+	if user != nil {
+		local, _, _ := strings.Cut(strings.ToLower(user.Email), "@")
+		personal := append(strings.Fields(strings.ToLower(user.Name)), local)
+		for _, word := range personal {
+			if utf8.RuneCountInString(word) >= 4 && strings.Contains(lower, word) {
+				fail("personal_info", "must not contain your name or email address")
+				break
+			}
+		}
+	}
+	return problems
+}
+
+// isSequence reports whether s is a run of consecutive characters such as
+// "123456789012" or "abcdefghijkl", ascending or descending.
+func isSequence(s string) bool {
+	runes := []rune(s)
+	for _, step := range []rune{1, -1} {
+		sequence := true
+		for i := 1; i < len(runes) && sequence; i++ {
+			// Digits wrap around, as in 7890123
+			sequence = runes[i]-runes[i-1] == step ||
+				(unicode.IsDigit(runes[i]) && unicode.IsDigit(runes[i-1]) && (runes[i]-runes[i-1]+10)%10 == (step+10)%10)
+		}
+		if sequence {
+			return true
+		}
+	}
+	return false
+}
+
+// Passwords validates and hashes the passwords users choose.
+type Passwords struct {
+	Hasher *auth.PasswordHasher
+	Policy PasswordPolicy
+}
+
+// check returns the objections of the policy and the hasher to password.
+func (p Passwords) check(field, password string, user *models.User) []apperror.FieldError {
+	problems := p.Policy.Validate(field, password, user)
+	if p.Hasher != nil && len(password) > p.Hasher.MaxLength() {
+		problems = append(problems, apperror.FieldError{
+			Field: field, Code: "too_long", Message: "must be at most " + strconv.Itoa(p.Hasher.MaxLength()) + " bytes",
+		})
+	}
+	return problems
+}
+
+// hash checks password and returns its hash.
+func (p Passwords) hash(ctx context.Context, field, password string, user *models.User) (string, error) {
+	if problems := p.check(field, password, user); len(problems) > 0 {
+		return "", ErrWeakPassword.WithFields(problems...)
+	}
+	if p.Hasher == nil {
+		return "", errors.New("no password hasher configured")
+	}
+	return p.Hasher.Hash(ctx, password)
+}
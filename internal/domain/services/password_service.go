@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/apperror"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/auth"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/logger"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/mailer"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/tracing"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/jobs"
+)
+
+var (
+	ErrIncorrectPassword = apperror.New(apperror.KindInvalid, "incorrect_password", "current password is incorrect")
+	ErrInvalidResetToken = apperror.New(apperror.KindInvalid, "invalid_reset_token", "invalid, expired or already used password reset token")
+)
+
+const passwordResetEmailText = `Hi %s,
+
+Someone asked to reset the password of your account. To choose a new password, open this link:
+
+%s
+
+The link can be used once and expires at %s. If you did not ask for this, you can ignore this email; your password stays the same.
+`
+
+// PasswordService changes and resets passwords. Setting a password either
+// way signs the user out everywhere by revoking their refresh tokens and
+// voids the password reset links sent before.
+type PasswordService interface {
+	// ChangePassword sets a new password if current is the user's password.
+	ChangePassword(ctx context.Context, userID uint, current, password string) (*models.User, error)
+	// ForgotPassword queues a password reset email to the user with the
+	// given address. It succeeds whether or not there is one, so callers
+	// cannot find out which addresses have accounts.
+	ForgotPassword(ctx context.Context, email string) error
+	// ResetPassword uses up a reset token and sets a new password.
+	ResetPassword(ctx context.Context, token, password string) error
+	// DeliverPasswordReset issues a token and mails the link. It is the
+	// handler of SendPasswordResetEmailArgs jobs.
+	DeliverPasswordReset(ctx context.Context, args SendPasswordResetEmailArgs) error
+}
+
+type PasswordServiceOptions struct {
+	Passwords Passwords
+	// Mailer sends reset emails; only the worker needs one
+	Mailer mailer.Mailer
+	// ResetURL is where links point; the token is added as the token query parameter
+	ResetURL string
+}
+
+type passwordServiceImpl struct {
+	userRepo repositories.UserRepository
+	tokens   *auth.JWTManager
+	opts     PasswordServiceOptions
+}
+
+func NewPasswordService(userRepo repositories.UserRepository, tokens *auth.JWTManager, opts PasswordServiceOptions) PasswordService {
+	return &passwordServiceImpl{userRepo: userRepo, tokens: tokens, opts: opts}
+}
+
+func (s *passwordServiceImpl) ChangePassword(ctx context.Context, userID uint, current, password string) (_ *models.User, err error) {
+	ctx, end := tracing.Start(ctx, "PasswordService.ChangePassword", userIDAttr(userID))
+	defer end(&err)
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	// Users without a password have to set one through a reset
+	ok, _, err := s.opts.Passwords.Hasher.Verify(ctx, user.PasswordHash, current)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		logger.FromContext(ctx).Infow("Password change with incorrect password", "user_id", userID)
+		return nil, ErrIncorrectPassword.WithFields(apperror.FieldError{
+			Field: "current_password", Code: "incorrect", Message: "does not match the user's password",
+		})
+	}
+	// Hash before the transaction; hashing is slow on purpose
+	hash, err := s.opts.Passwords.hash(ctx, "new_password", password, user)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.userRepo.Transaction(ctx, func(repo repositories.UserRepository) error {
+		return setPassword(ctx, repo, user, hash, models.AuditUserPasswordChanged)
+	})
+	if err != nil {
+		if errors.Is(err, repositories.ErrVersionConflict) { // The user changed since it was read
+			return nil, ErrVersionMismatch
+		}
+		return nil, err
+	}
+	logger.FromContext(ctx).Infow("Password changed", "user_id", userID)
+	return user, nil
+}
+
+func (s *passwordServiceImpl) ForgotPassword(ctx context.Context, email string) (err error) {
+	ctx, end := tracing.Start(ctx, "PasswordService.ForgotPassword")
+	defer end(&err)
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		logger.FromContext(ctx).Infow("Password reset requested for unknown email")
+		return nil
+	}
+	// An email already waiting to be sent is not queued again
+	_, err = jobs.Enqueue(ctx, s.userRepo.Jobs(), SendPasswordResetEmailArgs{UserID: user.ID, Email: user.Email}, jobs.Options{
+		UniqueKey: fmt.Sprintf("password_reset:%d", user.ID),
+	})
+	return err
+}
+
+func (s *passwordServiceImpl) ResetPassword(ctx context.Context, token, password string) (err error) {
+	ctx, end := tracing.Start(ctx, "PasswordService.ResetPassword")
+	defer end(&err)
+	claims, err := s.tokens.ParsePasswordResetToken(token)
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+	userID, err := claims.UserID()
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrInvalidResetToken
+	}
+	hash, err := s.opts.Passwords.hash(ctx, "password", password, user)
+	if err != nil {
+		return err
+	}
+
+	err = s.userRepo.Transaction(ctx, func(repo repositories.UserRepository) error {
+		stored, err := repo.PasswordResets().Use(ctx, claims.ID)
+		if err != nil {
+			return err
+		}
+		if stored == nil || stored.UserID != userID {
+			return ErrInvalidResetToken
+		}
+		// The link was sent to an address the user no longer has
+		if user.Email != stored.Email {
+			return ErrInvalidResetToken
+		}
+		return setPassword(ctx, repo, user, hash, models.AuditUserPasswordReset)
+	})
+	if err != nil {
+		if errors.Is(err, repositories.ErrVersionConflict) { // The user changed concurrently
+			return ErrVersionMismatch
+		}
+		return err
+	}
+	logger.FromContext(ctx).Infow("Password reset", "user_id", userID)
+	return nil
+}
+
+// setPassword stores hash as the user's password through repo, voids the
+// user's outstanding reset tokens and revokes their refresh tokens. Access
+// tokens issued before stay valid until they expire.
+func setPassword(ctx context.Context, repo repositories.UserRepository, user *models.User, hash, action string) error {
+	before := *user
+	user.PasswordHash = hash
+	if err := repo.Update(ctx, user); err != nil {
+		return err
+	}
+	if err := repo.PasswordResets().UseAllForUser(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := repo.RefreshTokens().RevokeAllForUser(ctx, user.ID); err != nil {
+		return err
+	}
+	return recordUserChanges(ctx, repo, action, userChange{&before, user})
+}
+
+func (s *passwordServiceImpl) DeliverPasswordReset(ctx context.Context, args SendPasswordResetEmailArgs) (err error) {
+	ctx, end := tracing.Start(ctx, "PasswordService.DeliverPasswordReset", userIDAttr(args.UserID))
+	defer end(&err)
+	if s.opts.Mailer == nil {
+		return jobs.Permanent(errors.New("no mailer configured"))
+	}
+	user, err := s.userRepo.GetByID(ctx, args.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil || user.Email != args.Email {
+		logger.FromContext(ctx).Infow("Skipping outdated password reset email", "user_id", args.UserID)
+		return nil
+	}
+
+	token, claims, err := s.tokens.GeneratePasswordResetToken(user)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.PasswordResets().Create(ctx, &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenID:   claims.ID,
+		Email:     user.Email,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}); err != nil {
+		return err
+	}
+	link := s.opts.ResetURL + "?token=" + url.QueryEscape(token)
+	return s.opts.Mailer.Send(ctx, mailer.Message{
+		To:      []string{user.Email},
+		Subject: "Reset your password",
+		Text:    fmt.Sprintf(passwordResetEmailText, user.Name, link, claims.ExpiresAt.UTC().Format(time.RFC1123)),
+	})
+}
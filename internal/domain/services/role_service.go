@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/apperror"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+)
+
+var ErrRoleNotFound = apperror.New(apperror.KindNotFound, "role_not_found", "role not found")
+
+type RoleService interface {
+	ListRoles(ctx context.Context) ([]models.Role, error)
+	GetUserRoles(ctx context.Context, userID uint) ([]models.Role, error)
+	AssignRole(ctx context.Context, userID uint, roleName string) error
+	RevokeRole(ctx context.Context, userID uint, roleName string) error
+	HasPermission(ctx context.Context, userID uint, permission string) (bool, error)
+}
+
+type roleServiceImpl struct {
+	roleRepo repositories.RoleRepository
+	userRepo repositories.UserRepository
+}
+
+func NewRoleService(roleRepo repositories.RoleRepository, userRepo repositories.UserRepository) RoleService {
+	return &roleServiceImpl{roleRepo: roleRepo, userRepo: userRepo}
+}
+
+func (s *roleServiceImpl) ListRoles(ctx context.Context) ([]models.Role, error) {
+	return s.roleRepo.List(ctx)
+}
+
+func (s *roleServiceImpl) GetUserRoles(ctx context.Context, userID uint) ([]models.Role, error) {
+	if err := s.ensureUserExists(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.roleRepo.ListForUser(ctx, userID)
+}
+
+// AssignRole grants a role to a user. Assigning a role the user already has is a no-op.
+func (s *roleServiceImpl) AssignRole(ctx context.Context, userID uint, roleName string) error {
+	role, err := s.resolve(ctx, userID, roleName)
+	if err != nil {
+		return err
+	}
+	return s.roleRepo.Assign(ctx, userID, role)
+}
+
+func (s *roleServiceImpl) RevokeRole(ctx context.Context, userID uint, roleName string) error {
+	role, err := s.resolve(ctx, userID, roleName)
+	if err != nil {
+		return err
+	}
+	return s.roleRepo.Unassign(ctx, userID, role)
+}
+
+func (s *roleServiceImpl) HasPermission(ctx context.Context, userID uint, permission string) (bool, error) {
+	return s.roleRepo.UserHasPermission(ctx, userID, permission)
+}
+
+func (s *roleServiceImpl) resolve(ctx context.Context, userID uint, roleName string) (*models.Role, error) {
+	if err := s.ensureUserExists(ctx, userID); err != nil {
+		return nil, err
+	}
+	role, err := s.roleRepo.GetByName(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, ErrRoleNotFound
+	}
+	return role, nil
+}
+
+func (s *roleServiceImpl) ensureUserExists(ctx context.Context, userID uint) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	return nil
+}
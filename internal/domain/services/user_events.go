@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/events"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/requestid"
+)
+
+// userChange is a user before and after one change. Either side is nil
+// when the user does not exist on that side.
+type userChange struct {
+	before, after *models.User
+}
+
+func (c userChange) userID() uint {
+	if c.after != nil {
+		return c.after.ID
+	}
+	return c.before.ID
+}
+
+// recordUserChanges writes the audit entries and domain events for changes
+// through repo, inside the transaction that makes the changes, so they are
+// stored if and only if the changes commit.
+func recordUserChanges(ctx context.Context, repo repositories.UserRepository, action string, changes ...userChange) error {
+	entries := make([]*models.AuditEntry, len(changes))
+	var messages []*models.OutboxMessage
+	for i, c := range changes {
+		entries[i] = newAuditEntry(ctx, action, c.userID(), userChanges(c.before, c.after))
+		for _, e := range userEvents(c.before, c.after) {
+			msg, err := newOutboxMessage(ctx, e)
+			if err != nil {
+				return err
+			}
+			messages = append(messages, msg)
+		}
+	}
+	if err := repo.Audit().Append(ctx, entries...); err != nil {
+		return err
+	}
+	return repo.Outbox().Add(ctx, messages...)
+}
+
+// userEvents returns the domain events a change to a user raises.
+// Restoring and purging soft-deleted users raise none.
+func userEvents(before, after *models.User) []events.Event {
+	live := func(u *models.User) bool { return u != nil && !u.DeletedAt.Valid }
+	switch {
+	case before == nil && after != nil:
+		return []events.Event{events.UserCreated{UserID: after.ID, Name: after.Name, Email: after.Email}}
+	case live(before) && !live(after):
+		return []events.Event{events.UserDeleted{UserID: before.ID, Email: before.Email}}
+	case live(before) && live(after) && before.Email != after.Email:
+		return []events.Event{events.UserEmailChanged{UserID: after.ID, OldEmail: before.Email, NewEmail: after.Email}}
+	case live(before) && live(after) && before.EmailVerifiedAt == nil && after.EmailVerifiedAt != nil:
+		return []events.Event{events.UserEmailVerified{UserID: after.ID, Email: after.Email}}
+	}
+	return nil
+}
+
+func newOutboxMessage(ctx context.Context, e events.Event) (*models.OutboxMessage, error) {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return &models.OutboxMessage{
+		EventType:     e.EventType(),
+		AggregateType: events.AggregateUser,
+		AggregateID:   e.AggregateID(),
+		Payload:       payload,
+		RequestID:     requestid.FromContext(ctx),
+	}, nil
+}
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/apperror"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/logger"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/tracing"
+)
+
+const (
+	DefaultImportBatchSize = 1000
+	MaxImportBatchSize     = 5000
+	// MaxImportRows bounds a single import; later rows are not read
+	MaxImportRows = 100_000
+	// maxImportErrors bounds the per-row error report
+	maxImportErrors = 1000
+
+	exportPageSize = 500
+)
+
+var ErrInvalidImportMode = apperror.New(apperror.KindInvalid, "invalid_import_mode", "transaction must be one of: batch all")
+
+// ImportMode selects how an import is split into transactions.
+type ImportMode string
+
+const (
+	// ImportModeBatch commits each batch on its own and skips invalid rows
+	ImportModeBatch ImportMode = "batch"
+	// ImportModeAll imports every row in one transaction, or nothing if any row fails
+	ImportModeAll ImportMode = "all"
+)
+
+// ImportRow is one decoded input row. Errors holds problems found while
+// decoding or validating it; such rows are reported and never inserted.
+type ImportRow struct {
+	Line     int
+	Name     string
+	Email    string
+	Password string
+	Errors   []apperror.FieldError
+}
+
+type ImportOptions struct {
+	Mode ImportMode
+	// BatchSize defaults to DefaultImportBatchSize
+	BatchSize int
+}
+
+type ImportRowError struct {
+	Line   int                   `json:"line"`
+	Email  string                `json:"email,omitempty"`
+	Errors []apperror.FieldError `json:"errors"`
+}
+
+// ImportReport summarises an import. Imported counts committed rows only.
+type ImportReport struct {
+	Mode       ImportMode       `json:"transaction"`
+	Rows       int              `json:"rows"`
+	Imported   int              `json:"imported"`
+	Failed     int              `json:"failed"`
+	RolledBack bool             `json:"rolled_back"`
+	Errors     []ImportRowError `json:"errors"`
+	// ErrorsTruncated is set when more rows failed than are listed
+	ErrorsTruncated bool `json:"errors_truncated"`
+}
+
+func (r *ImportReport) fail(line int, email string, fields ...apperror.FieldError) {
+	r.Failed++
+	if len(r.Errors) >= maxImportErrors {
+		r.ErrorsTruncated = true
+		return
+	}
+	r.Errors = append(r.Errors, ImportRowError{Line: line, Email: email, Errors: fields})
+}
+
+func (s *userServiceImpl) ImportUsers(ctx context.Context, rows iter.Seq[ImportRow], opts ImportOptions) (_ *ImportReport, err error) {
+	ctx, end := tracing.Start(ctx, "UserService.ImportUsers")
+	defer end(&err)
+	if opts.Mode == "" {
+		opts.Mode = ImportModeBatch
+	}
+	if opts.Mode != ImportModeBatch && opts.Mode != ImportModeAll {
+		return nil, ErrInvalidImportMode
+	}
+	if opts.BatchSize == 0 {
+		opts.BatchSize = DefaultImportBatchSize
+	}
+	if opts.BatchSize < 1 || opts.BatchSize > MaxImportBatchSize {
+		return nil, ErrValidationFailed.WithFields(apperror.FieldError{
+			Field: "batch_size", Code: "range", Message: "must be between 1 and " + strconv.Itoa(MaxImportBatchSize),
+		})
+	}
+
+	report := &ImportReport{Mode: opts.Mode, Errors: []ImportRowError{}}
+	if opts.Mode == ImportModeAll {
+		err = s.userRepo.Transaction(ctx, func(repo repositories.UserRepository) error {
+			if err := importRows(ctx, repo, s.passwords, rows, opts.BatchSize, report); err != nil {
+				return err
+			}
+			if report.Failed > 0 {
+				return errImportRolledBack
+			}
+			return nil
+		})
+		if errors.Is(err, errImportRolledBack) {
+			report.RolledBack, report.Imported, err = true, 0, nil
+		}
+	} else {
+		err = importRows(ctx, s.userRepo, s.passwords, rows, opts.BatchSize, report)
+	}
+	if err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx).Infow("Users imported",
+		"mode", opts.Mode, "rows", report.Rows, "imported", report.Imported, "failed", report.Failed)
+	return report, nil
+}
+
+// errImportRolledBack aborts an all-or-nothing import that had failed rows.
+var errImportRolledBack = errors.New("import rolled back")
+
+// importRows validates rows and inserts the valid ones in batches. In batch
+// mode a batch that fails to insert is reported row by row and the import
+// continues; in all mode inserting stops at the first failure, but rows
+// are still read so the report is complete.
+func importRows(ctx context.Context, repo repositories.UserRepository, passwords Passwords, rows iter.Seq[ImportRow], batchSize int, report *ImportReport) error {
+	seen := map[string]int{} // email -> first line
+	batch := make([]ImportRow, 0, batchSize)
+	all := report.Mode == ImportModeAll
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		defer func() { batch = batch[:0] }()
+		users, err := prepareBatch(ctx, repo, passwords, batch, report)
+		if err != nil || len(users) == 0 || (all && report.Failed > 0) {
+			return err
+		}
+		// The batch, its audit entries and its events commit together
+		err = repo.Transaction(ctx, func(repo repositories.UserRepository) error {
+			if err := repo.CreateBatch(ctx, users); err != nil {
+				return err
+			}
+			changes := make([]userChange, len(users))
+			for i := range users {
+				changes[i] = userChange{after: &users[i]}
+			}
+			return recordUserChanges(ctx, repo, models.AuditUserImported, changes...)
+		})
+		if err != nil {
+			if all || ctx.Err() != nil {
+				return err
+			}
+			logger.FromContext(ctx).Warnw("Import batch failed", "first_line", batch[0].Line, "error", err)
+			for _, u := range users {
+				report.fail(seen[u.Email], u.Email, apperror.FieldError{
+					Code: "insert_failed", Message: "the batch containing this row could not be inserted",
+				})
+			}
+			return nil
+		}
+		report.Imported += len(users)
+		return nil
+	}
+
+	for row := range rows {
+		if report.Rows == MaxImportRows {
+			report.fail(row.Line, "", apperror.FieldError{
+				Code: "max_rows", Message: "imports are limited to " + strconv.Itoa(MaxImportRows) + " rows; this and later rows were not read",
+			})
+			break
+		}
+		report.Rows++
+		if len(row.Errors) > 0 {
+			report.fail(row.Line, row.Email, row.Errors...)
+			continue
+		}
+		if first, dup := seen[row.Email]; dup {
+			report.fail(row.Line, row.Email, apperror.FieldError{
+				Field: "email", Code: "duplicate", Message: "duplicates line " + strconv.Itoa(first),
+			})
+			continue
+		}
+		seen[row.Email] = row.Line
+		batch = append(batch, row)
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+// prepareBatch drops rows whose email is already taken or whose password
+// the policy rejects, and hashes the passwords of the rest in parallel.
+func prepareBatch(ctx context.Context, repo repositories.UserRepository, passwords Passwords, batch []ImportRow, report *ImportReport) ([]models.User, error) {
+	emails := make([]string, len(batch))
+	for i, row := range batch {
+		emails[i] = row.Email
+	}
+	existing, err := repo.ExistingEmails(ctx, emails)
+	if err != nil {
+		return nil, err
+	}
+	taken := make(map[string]bool, len(existing))
+	for _, email := range existing {
+		taken[email] = true
+	}
+
+	users := make([]models.User, 0, len(batch))
+	for _, row := range batch {
+		if taken[row.Email] {
+			report.fail(row.Line, row.Email, apperror.FieldError{
+				Field: "email", Code: ErrUserEmailExists.Code, Message: ErrUserEmailExists.Message,
+			})
+			continue
+		}
+		user := models.User{Name: row.Name, Email: row.Email, Password: row.Password}
+		if user.Password != "" {
+			if problems := passwords.check("password", user.Password, &user); len(problems) > 0 {
+				report.fail(row.Line, row.Email, problems...)
+				continue
+			}
+		}
+		users = append(users, user)
+	}
+	if err := hashPasswords(ctx, passwords, users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// hashPasswords replaces the Password of users that have one with its
+// hash, using all CPUs since hashing dominates the cost of an import.
+func hashPasswords(ctx context.Context, passwords Passwords, users []models.User) error {
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	for i := range users {
+		if users[i].Password == "" {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() { <-sem; wg.Done() }()
+			hash, err := passwords.hash(ctx, "password", users[i].Password, &users[i])
+			if err != nil {
+				once.Do(func() { firstErr = err })
+				return
+			}
+			users[i].PasswordHash, users[i].Password = hash, ""
+		}(i)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+func (s *userServiceImpl) ExportUsers(ctx context.Context, q repositories.UserQuery, fn func(models.User) error) (err error) {
+	ctx, end := tracing.Start(ctx, "UserService.ExportUsers")
+	defer end(&err)
+	q.Sort = withIDTiebreak(q.Sort)
+	var after []any
+	for {
+		users, err := s.userRepo.ListAfter(ctx, q, after, exportPageSize)
+		if err != nil {
+			return err
+		}
+		for _, u := range users {
+			if err := fn(u); err != nil {
+				return err
+			}
+		}
+		if len(users) < exportPageSize {
+			return nil
+		}
+		after = keysetValues(q.Sort, users[len(users)-1])
+	}
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/jobs"
+)
+
+// PurgeDeletedUsersArgs purges users soft-deleted for longer than
+// RetentionPeriod. The worker enqueues it periodically.
+type PurgeDeletedUsersArgs struct {
+	RetentionPeriod time.Duration `json:"retention_period"`
+}
+
+func (PurgeDeletedUsersArgs) Kind() string { return "users.purge_deleted" }
+
+// SendVerificationEmailArgs mails a verification link to Email, unless by
+// the time the job runs it is no longer the user's unverified address.
+type SendVerificationEmailArgs struct {
+	UserID uint   `json:"user_id"`
+	Email  string `json:"email"`
+}
+
+func (SendVerificationEmailArgs) Kind() string { return "users.send_verification_email" }
+
+// SendPasswordResetEmailArgs mails a password reset link to Email, unless
+// by the time the job runs it is no longer the user's address.
+type SendPasswordResetEmailArgs struct {
+	UserID uint   `json:"user_id"`
+	Email  string `json:"email"`
+}
+
+func (SendPasswordResetEmailArgs) Kind() string { return "users.send_password_reset_email" }
+
+// RegisterUserJobs registers the handlers for the user job kinds.
+func RegisterUserJobs(r *jobs.Registry, users UserService, verification EmailVerificationService, passwords PasswordService) {
+	jobs.Register(r, func(ctx context.Context, job *jobs.Job, args PurgeDeletedUsersArgs) error {
+		// PurgeDeletedUsers logs how many users it purged
+		_, err := NewUserRetention(users, args.RetentionPeriod).RunOnce(ctx)
+		return err
+	})
+	jobs.Register(r, func(ctx context.Context, job *jobs.Job, args SendVerificationEmailArgs) error {
+		return verification.DeliverVerification(ctx, args)
+	})
+	jobs.Register(r, func(ctx context.Context, job *jobs.Job, args SendPasswordResetEmailArgs) error {
+		return passwords.DeliverPasswordReset(ctx, args)
+	})
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/apperror"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+)
+
+const maxInValues = 100
+
+// userFilterOps lists the filterable fields and the operators each accepts.
+var userFilterOps = map[repositories.UserField][]repositories.FilterOp{
+	repositories.UserFieldName:      {repositories.OpEq, repositories.OpPrefix, repositories.OpIn},
+	repositories.UserFieldEmail:     {repositories.OpEq, repositories.OpPrefix, repositories.OpIn},
+	repositories.UserFieldCreatedAt: {repositories.OpGt, repositories.OpGte, repositories.OpLt, repositories.OpLte},
+}
+
+var userSortFields = map[repositories.UserField]bool{
+	repositories.UserFieldID:        true,
+	repositories.UserFieldName:      true,
+	repositories.UserFieldEmail:     true,
+	repositories.UserFieldCreatedAt: true,
+}
+
+// ParseUserQuery builds a UserQuery from query parameters:
+//
+//	name=Jane                        equality (shorthand for name[eq])
+//	email[prefix]=jane@              prefix match
+//	name[in]=Jane,John               any of a comma-separated list
+//	created_at[gte]=2024-01-01       range bounds gt, gte, lt and lte (RFC 3339
+//	                                 or YYYY-MM-DD, which covers the whole day)
+//	deleted=exclude|include|only     soft-deleted users
+//	q=smith                          substring search on name and email
+//	sort=-created_at,name            sort keys, "-" for descending
+//
+// Unrelated parameters are ignored. All problems are reported together as
+// field errors on ErrValidationFailed.
+func ParseUserQuery(params map[string][]string) (repositories.UserQuery, error) {
+	var q repositories.UserQuery
+	var problems []apperror.FieldError
+	invalid := func(field, code, format string, args ...any) {
+		problems = append(problems, apperror.FieldError{Field: field, Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Visit keys in order so errors and filters come out deterministically
+	for _, key := range slices.Sorted(maps.Keys(params)) {
+		values := params[key]
+		if len(values) == 0 {
+			continue
+		}
+		value := values[len(values)-1]
+
+		switch key {
+		case "deleted":
+			switch scope := repositories.DeletedScope(value); scope {
+			case repositories.DeletedExclude, repositories.DeletedInclude, repositories.DeletedOnly:
+				q.Deleted = scope
+			default:
+				invalid(key, "oneof", "must be one of: exclude include only")
+			}
+			continue
+		case "q":
+			q.Search = strings.TrimSpace(value)
+			continue
+		case "sort":
+			q.Sort, problems = parseUserSort(value, problems)
+			continue
+		}
+
+		name, op := key, repositories.OpEq
+		if i := strings.IndexByte(key, '['); i >= 0 {
+			if !strings.HasSuffix(key, "]") {
+				invalid(key, "syntax", "must have the form field[operator]")
+				continue
+			}
+			name, op = key[:i], repositories.FilterOp(key[i+1:len(key)-1])
+		}
+		field := repositories.UserField(name)
+		ops, filterable := userFilterOps[field]
+		if !filterable {
+			if name != key { // Plain unknown parameters are not filters
+				invalid(key, "unknown_field", "cannot filter on %q", name)
+			}
+			continue
+		}
+		if !containsOp(ops, op) {
+			invalid(key, "unsupported_operator", "operator %q is not supported on %s", op, name)
+			continue
+		}
+
+		raw := []string{value}
+		if op == repositories.OpIn {
+			raw = strings.Split(value, ",")
+			if len(raw) > maxInValues {
+				invalid(key, "max", "must list at most %d values", maxInValues)
+				continue
+			}
+		}
+		filter := repositories.UserFilter{Field: field, Op: op}
+		for _, v := range raw {
+			typed, err := parseFilterValue(field, v)
+			if err != nil {
+				invalid(key, "format", "%s", err.Error())
+				break
+			}
+			filter.Values = append(filter.Values, typed)
+		}
+		if len(filter.Values) == len(raw) {
+			q.Filters = append(q.Filters, wholeDayBound(filter, value))
+		}
+	}
+
+	if len(problems) > 0 {
+		return repositories.UserQuery{}, ErrValidationFailed.WithFields(problems...)
+	}
+	return q, nil
+}
+
+func parseUserSort(value string, problems []apperror.FieldError) ([]repositories.SortKey, []apperror.FieldError) {
+	if value == "" {
+		return nil, problems
+	}
+	var keys []repositories.SortKey
+	seen := map[repositories.UserField]bool{}
+	for _, part := range strings.Split(value, ",") {
+		key := repositories.SortKey{Field: repositories.UserField(strings.TrimPrefix(part, "-")), Desc: strings.HasPrefix(part, "-")}
+		if !userSortFields[key.Field] {
+			problems = append(problems, apperror.FieldError{Field: "sort", Code: "unknown_field", Message: fmt.Sprintf("cannot sort on %q", key.Field)})
+			continue
+		}
+		if seen[key.Field] {
+			problems = append(problems, apperror.FieldError{Field: "sort", Code: "duplicate", Message: fmt.Sprintf("%q is listed more than once", key.Field)})
+			continue
+		}
+		seen[key.Field] = true
+		keys = append(keys, key)
+	}
+	return keys, problems
+}
+
+func containsOp(ops []repositories.FilterOp, op repositories.FilterOp) bool {
+	for _, o := range ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+func parseFilterValue(field repositories.UserField, v string) (any, error) {
+	if field != repositories.UserFieldCreatedAt {
+		if v == "" {
+			return nil, fmt.Errorf("must not be empty")
+		}
+		return v, nil
+	}
+	t, err := parseTime(v)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// parseTime accepts an RFC 3339 timestamp or a YYYY-MM-DD date.
+func parseTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("must be an RFC 3339 timestamp or YYYY-MM-DD date")
+}
+
+// wholeDayBound treats a YYYY-MM-DD bound as the whole day: lte becomes
+// "before the next day" and gt "from the next day on".
+func wholeDayBound(f repositories.UserFilter, raw string) repositories.UserFilter {
+	if f.Op != repositories.OpLte && f.Op != repositories.OpGt {
+		return f
+	}
+	day, ok := f.Values[0].(time.Time)
+	if _, err := time.Parse(time.DateOnly, raw); !ok || err != nil {
+		return f
+	}
+	op := repositories.OpLt
+	if f.Op == repositories.OpGt {
+		op = repositories.OpGte
+	}
+	return repositories.UserFilter{Field: f.Field, Op: op, Values: []any{day.AddDate(0, 0, 1)}}
+}
+
+// withIDTiebreak appends an ID key unless the sort already has one, so
+// every keyset position is unique.
+func withIDTiebreak(sort []repositories.SortKey) []repositories.SortKey {
+	for _, key := range sort {
+		if key.Field == repositories.UserFieldID {
+			return sort
+		}
+	}
+	return append(append([]repositories.SortKey(nil), sort...), repositories.SortKey{Field: repositories.UserFieldID})
+}
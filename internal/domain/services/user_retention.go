@@ -0,0 +1,23 @@
+package services
+
+import (
+	"context"
+	"time"
+)
+
+// UserRetention purges users that have been soft-deleted for longer than
+// the retention period. The worker runs it as the PurgeDeletedUsersArgs job.
+type UserRetention struct {
+	users     UserService
+	retention time.Duration
+	now       func() time.Time
+}
+
+func NewUserRetention(users UserService, retention time.Duration) *UserRetention {
+	return &UserRetention{users: users, retention: retention, now: time.Now}
+}
+
+// RunOnce purges users deleted before now minus the retention period.
+func (r *UserRetention) RunOnce(ctx context.Context) (int64, error) {
+	return r.users.PurgeDeletedUsers(ctx, r.now().Add(-r.retention))
+}
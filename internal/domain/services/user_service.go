@@ -1,45 +1,147 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"iter"
 	"math"
+	"time"
 
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/apperror"
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
-	"github.com/gin-gonic/gin"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/logger"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm" // Required for gorm.ErrRecordNotFound, potentially define custom errors later
 )
 
-// Define custom errors (good practice, can be moved to a dedicated errors package)
 var (
-	ErrUserNotFound     = errors.New("user not found")
-	ErrUserEmailExists  = errors.New("user with this email already exists")
-	ErrEmailInUse       = errors.New("email already in use by another user")
-	ErrValidationFailed = errors.New("validation failed")
+	ErrUserNotFound     = apperror.New(apperror.KindNotFound, "user_not_found", "user not found")
+	ErrUserEmailExists  = apperror.New(apperror.KindConflict, "user_email_exists", "user with this email already exists")
+	ErrEmailInUse       = apperror.New(apperror.KindConflict, "email_in_use", "email already in use by another user")
+	ErrValidationFailed = apperror.ErrValidation
+	ErrVersionMismatch  = apperror.New(apperror.KindPreconditionFailed, "version_mismatch", "user has been modified since it was read")
+
+	ErrDeletedUserNotFound = apperror.New(apperror.KindNotFound, "deleted_user_not_found", "deleted user not found")
 )
 
+// purgeBatchSize bounds how many users PurgeDeletedUsers removes per transaction.
+const purgeBatchSize = 100
+
+// UserService is the use-case boundary for managing users. It depends only on
+// context.Context, so it can be driven from HTTP handlers, CLIs or workers alike.
+type UserService interface {
+	// ListUsers returns one page of users matching q together with the page count and total.
+	ListUsers(ctx context.Context, q repositories.UserQuery, page, limit int) ([]models.User, int, int64, error)
+	// ListUsersAfter returns the page following q.After using keyset pagination,
+	// which stays fast on deep pages and stable under concurrent inserts.
+	ListUsersAfter(ctx context.Context, q CursorQuery) (*CursorPage, error)
+	GetUserByID(ctx context.Context, id uint) (*models.User, error)
+	// CreateUser stores a new user and queues a verification email. A
+	// user.Password is checked against the password policy and hashed.
+	CreateUser(ctx context.Context, user *models.User) (*models.User, error)
+	// UpdateUser replaces every updatable field of the user with the values in
+	// userUpdate; zero values clear fields rather than leave them unchanged.
+	// A non-zero userUpdate.Version must match the stored version. Changing
+	// the email marks it unverified and queues a verification email.
+	UpdateUser(ctx context.Context, id uint, userUpdate *models.User) (*models.User, error)
+	// DeleteUser soft-deletes a user. A non-zero version must match the stored version.
+	DeleteUser(ctx context.Context, id uint, version int64) error
+	// RestoreUser undoes a soft delete. It fails with ErrEmailInUse if the
+	// email has been taken by another user in the meantime.
+	RestoreUser(ctx context.Context, id uint) (*models.User, error)
+	// PurgeUser permanently removes a soft-deleted user.
+	PurgeUser(ctx context.Context, id uint) error
+	// PurgeDeletedUsers permanently removes every user soft-deleted before
+	// cutoff and reports how many were removed.
+	PurgeDeletedUsers(ctx context.Context, cutoff time.Time) (int64, error)
+	// ImportUsers creates users from rows in batches and reports per-row
+	// failures. Rows are consumed as they are read, so large inputs can be
+	// streamed.
+	ImportUsers(ctx context.Context, rows iter.Seq[ImportRow], opts ImportOptions) (*ImportReport, error)
+	// ExportUsers calls fn for every user matching q, in q's sort order,
+	// reading one page at a time. It stops at the first error from fn.
+	ExportUsers(ctx context.Context, q repositories.UserQuery, fn func(models.User) error) error
+}
+
 type userServiceImpl struct {
-	userRepo repositories.UserRepository
+	userRepo  repositories.UserRepository
+	passwords Passwords
 }
 
-func NewUserService(userRepo repositories.UserRepository) UserService {
-	return &userServiceImpl{userRepo: userRepo}
+func NewUserService(userRepo repositories.UserRepository, passwords Passwords) UserService {
+	return &userServiceImpl{userRepo: userRepo, passwords: passwords}
 }
 
-func (s *userServiceImpl) ListUsers(c *gin.Context, page, limit int) ([]models.User, int, int64, error) {
-	users, total, err := s.userRepo.List(c, page, limit)
-	if err != nil {
+func (s *userServiceImpl) ListUsers(ctx context.Context, q repositories.UserQuery, page, limit int) (_ []models.User, _ int, _ int64, err error) {
+	ctx, end := tracing.Start(ctx, "UserService.ListUsers")
+	defer end(&err)
+	if page < 1 {
+		return nil, 0, 0, ErrValidationFailed.WithFields(apperror.FieldError{
+			Field: "page", Code: "min", Message: "must be at least 1",
+		})
+	}
+	if limit, err = checkLimit(limit); err != nil {
 		return nil, 0, 0, err
 	}
-	totalPages := 0
-	if limit > 0 {
-		totalPages = int(math.Ceil(float64(total) / float64(limit)))
+	users, total, err := s.userRepo.List(ctx, q, page, limit)
+	if err != nil {
+		return nil, 0, 0, err
 	}
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
 	return users, totalPages, total, nil
 }
 
-func (s *userServiceImpl) GetUserByID(c *gin.Context, id uint) (*models.User, error) {
-	user, err := s.userRepo.GetByID(c, id)
+func (s *userServiceImpl) ListUsersAfter(ctx context.Context, q CursorQuery) (_ *CursorPage, err error) {
+	ctx, end := tracing.Start(ctx, "UserService.ListUsersAfter")
+	defer end(&err)
+	limit, err := checkLimit(q.Limit)
+	if err != nil {
+		return nil, err
+	}
+	query := q.Query
+	query.Sort = withIDTiebreak(query.Sort)
+	after, err := decodeCursor(q.After, query.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	users, err := s.userRepo.ListAfter(ctx, query, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	page := &CursorPage{Users: users, Limit: limit}
+	if len(users) > limit {
+		page.Users = users[:limit]
+		page.HasMore = true
+		page.NextCursor = encodeCursor(query.Sort, page.Users[limit-1])
+	}
+
+	switch q.Count {
+	case CountExact:
+		total, err := s.userRepo.Count(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	case CountEstimate:
+		total, err := s.userRepo.EstimateCount(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = &total
+		page.TotalEstimated = true
+	}
+	return page, nil
+}
+
+func (s *userServiceImpl) GetUserByID(ctx context.Context, id uint) (_ *models.User, err error) {
+	ctx, end := tracing.Start(ctx, "UserService.GetUserByID", userIDAttr(id))
+	defer end(&err)
+	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err // Pass through repository error
 	}
@@ -49,61 +151,220 @@ func (s *userServiceImpl) GetUserByID(c *gin.Context, id uint) (*models.User, er
 	return user, nil
 }
 
-func (s *userServiceImpl) CreateUser(c *gin.Context, user *models.User) (*models.User, error) {
+func (s *userServiceImpl) CreateUser(ctx context.Context, user *models.User) (_ *models.User, err error) {
+	ctx, end := tracing.Start(ctx, "UserService.CreateUser")
+	defer end(&err)
 	// Business logic: Check if user with email already exists
-	existingUser, err := s.userRepo.GetByEmail(c, user.Email)
+	existingUser, err := s.userRepo.GetByEmail(ctx, user.Email)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && existingUser != nil { // Check if it is not simply a "not found" error
 		return nil, err // Database error
 	}
 	if existingUser != nil {
 		return nil, ErrUserEmailExists
 	}
+	if user.Password != "" {
+		if user.PasswordHash, err = s.passwords.hash(ctx, "password", user.Password, user); err != nil {
+			return nil, err
+		}
+		user.Password = ""
+	}
 
-	if err := s.userRepo.Create(c, user); err != nil {
+	err = s.userRepo.Transaction(ctx, func(repo repositories.UserRepository) error {
+		if err := repo.Create(ctx, user); err != nil {
+			return err
+		}
+		if err := recordUserChanges(ctx, repo, models.AuditUserCreated, userChange{after: user}); err != nil {
+			return err
+		}
+		return enqueueVerificationEmail(ctx, repo, user)
+	})
+	if err != nil {
 		return nil, err
 	}
+	logger.FromContext(ctx).Infow("User created", "user_id", user.ID)
 	return user, nil
 }
 
-func (s *userServiceImpl) UpdateUser(c *gin.Context, id uint, userUpdate *models.User) (*models.User, error) {
-	existingUser, err := s.userRepo.GetByID(c, id)
+func (s *userServiceImpl) UpdateUser(ctx context.Context, id uint, userUpdate *models.User) (_ *models.User, err error) {
+	ctx, end := tracing.Start(ctx, "UserService.UpdateUser", userIDAttr(id))
+	defer end(&err)
+	existingUser, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err // Database error
 	}
 	if existingUser == nil {
 		return nil, ErrUserNotFound
 	}
+	if userUpdate.Version != 0 && userUpdate.Version != existingUser.Version {
+		return nil, ErrVersionMismatch
+	}
+	before := *existingUser
 
 	// Business logic: Check if email is being changed and if it already exists for another user
-	if userUpdate.Email != "" && userUpdate.Email != existingUser.Email {
-		collidingUser, err := s.userRepo.GetByEmail(c, userUpdate.Email)
+	if userUpdate.Email != existingUser.Email {
+		collidingUser, err := s.userRepo.GetByEmail(ctx, userUpdate.Email)
 		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && collidingUser != nil {
 			return nil, err // Database error
 		}
 		if collidingUser != nil && collidingUser.ID != existingUser.ID {
 			return nil, ErrEmailInUse
 		}
-		existingUser.Email = userUpdate.Email
+		// The new address has to be verified again
+		existingUser.Email, existingUser.EmailVerifiedAt = userUpdate.Email, nil
 	}
 
-	if userUpdate.Name != "" {
-		existingUser.Name = userUpdate.Name
-	}
+	existingUser.Name = userUpdate.Name
 	// Potentially update other fields as needed
 
-	if err := s.userRepo.Update(c, existingUser); err != nil {
+	err = s.userRepo.Transaction(ctx, func(repo repositories.UserRepository) error {
+		if err := repo.Update(ctx, existingUser); err != nil {
+			return err
+		}
+		if err := recordUserChanges(ctx, repo, models.AuditUserUpdated, userChange{&before, existingUser}); err != nil {
+			return err
+		}
+		if existingUser.Email != before.Email {
+			// Reset links sent to the old address stop working
+			if err := repo.PasswordResets().UseAllForUser(ctx, existingUser.ID); err != nil {
+				return err
+			}
+			return enqueueVerificationEmail(ctx, repo, existingUser)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, repositories.ErrVersionConflict) { // Lost a race with a concurrent write
+			return nil, ErrVersionMismatch
+		}
 		return nil, err
 	}
+	logger.FromContext(ctx).Infow("User updated", "user_id", existingUser.ID)
 	return existingUser, nil
 }
 
-func (s *userServiceImpl) DeleteUser(c *gin.Context, id uint) error {
-	user, err := s.userRepo.GetByID(c, id)
+func (s *userServiceImpl) DeleteUser(ctx context.Context, id uint, version int64) (err error) {
+	ctx, end := tracing.Start(ctx, "UserService.DeleteUser", userIDAttr(id))
+	defer end(&err)
+	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		return err // Database error
 	}
 	if user == nil {
 		return ErrUserNotFound
 	}
-	return s.userRepo.Delete(c, id)
+	if version != 0 && version != user.Version {
+		return ErrVersionMismatch
+	}
+	err = s.userRepo.Transaction(ctx, func(repo repositories.UserRepository) error {
+		if err := repo.Delete(ctx, id, user.Version); err != nil {
+			return err
+		}
+		deleted := *user
+		deleted.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		return recordUserChanges(ctx, repo, models.AuditUserDeleted, userChange{user, &deleted})
+	})
+	if err != nil {
+		if errors.Is(err, repositories.ErrVersionConflict) {
+			return ErrVersionMismatch
+		}
+		return err
+	}
+	logger.FromContext(ctx).Infow("User deleted", "user_id", id)
+	return nil
+}
+
+func (s *userServiceImpl) RestoreUser(ctx context.Context, id uint) (_ *models.User, err error) {
+	ctx, end := tracing.Start(ctx, "UserService.RestoreUser", userIDAttr(id))
+	defer end(&err)
+	user, err := s.userRepo.GetDeletedByID(ctx, id)
+	if err != nil {
+		return nil, err // Database error
+	}
+	if user == nil {
+		return nil, ErrDeletedUserNotFound
+	}
+	// The partial unique index lets a new account reuse a deleted user's email
+	collidingUser, err := s.userRepo.GetByEmail(ctx, user.Email)
+	if err != nil {
+		return nil, err
+	}
+	if collidingUser != nil {
+		return nil, ErrEmailInUse
+	}
+	before := *user
+	err = s.userRepo.Transaction(ctx, func(repo repositories.UserRepository) error {
+		if err := repo.Restore(ctx, user); err != nil {
+			return err
+		}
+		return recordUserChanges(ctx, repo, models.AuditUserRestored, userChange{&before, user})
+	})
+	if err != nil {
+		if errors.Is(err, repositories.ErrVersionConflict) { // Restored or purged concurrently
+			return nil, ErrDeletedUserNotFound
+		}
+		if errors.Is(err, repositories.ErrEmailTaken) { // Registered since the check above
+			return nil, ErrEmailInUse
+		}
+		return nil, err
+	}
+	logger.FromContext(ctx).Infow("User restored", "user_id", id)
+	return user, nil
+}
+
+func (s *userServiceImpl) PurgeUser(ctx context.Context, id uint) (err error) {
+	ctx, end := tracing.Start(ctx, "UserService.PurgeUser", userIDAttr(id))
+	defer end(&err)
+	user, err := s.userRepo.GetDeletedByID(ctx, id)
+	if err != nil {
+		return err // Database error
+	}
+	if user == nil {
+		return ErrDeletedUserNotFound
+	}
+	err = s.userRepo.Transaction(ctx, func(repo repositories.UserRepository) error {
+		if err := repo.Purge(ctx, id); err != nil {
+			return err
+		}
+		return recordUserChanges(ctx, repo, models.AuditUserPurged, userChange{before: user})
+	})
+	if err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Infow("User purged", "user_id", id)
+	return nil
+}
+
+func (s *userServiceImpl) PurgeDeletedUsers(ctx context.Context, cutoff time.Time) (total int64, err error) {
+	ctx, end := tracing.Start(ctx, "UserService.PurgeDeletedUsers")
+	defer end(&err)
+	for {
+		var n int
+		err := s.userRepo.Transaction(ctx, func(repo repositories.UserRepository) error {
+			users, err := repo.PurgeDeletedBefore(ctx, cutoff, purgeBatchSize)
+			if err != nil {
+				return err
+			}
+			n = len(users)
+			changes := make([]userChange, len(users))
+			for i := range users {
+				changes[i] = userChange{before: &users[i]}
+			}
+			return recordUserChanges(ctx, repo, models.AuditUserPurged, changes...)
+		})
+		if err != nil {
+			return total, err
+		}
+		total += int64(n)
+		if n < purgeBatchSize {
+			break
+		}
+	}
+	if total > 0 {
+		logger.FromContext(ctx).Infow("Purged deleted users", "count", total, "deleted_before", cutoff)
+	}
+	return total, nil
+}
+
+func userIDAttr(id uint) trace.SpanStartOption {
+	return trace.WithAttributes(attribute.Int64("user.id", int64(id)))
 }
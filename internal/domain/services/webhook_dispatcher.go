@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/logger"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/tracing"
+)
+
+const (
+	// webhookRetryBase is the delay after the first failed attempt; it
+	// doubles with each further failure
+	webhookRetryBase = 30 * time.Second
+	// maxWebhookRetryDelay caps the exponential backoff between attempts
+	maxWebhookRetryDelay = 6 * time.Hour
+	// maxWebhookErrorLength bounds the error stored with a delivery
+	maxWebhookErrorLength = 1000
+	// webhookCleanupInterval is how often Run deletes old finished deliveries
+	webhookCleanupInterval = time.Hour
+)
+
+// WebhookSender sends one delivery to its subscription and returns the
+// response status, or zero if there was no response. Non-2xx responses are
+// errors.
+type WebhookSender interface {
+	Send(ctx context.Context, d *models.WebhookDelivery) (int, error)
+}
+
+type WebhookDispatcherOptions struct {
+	// BatchSize is how many deliveries one RunOnce claims
+	BatchSize int
+	// PollInterval is how long Run waits after finding fewer than BatchSize deliveries
+	PollInterval time.Duration
+	// Lease is how long a claimed batch is reserved for this dispatcher.
+	// It should exceed BatchSize request timeouts, or a batch of slow
+	// receivers may be sent again elsewhere.
+	Lease time.Duration
+	// MaxAttempts is how many failed attempts make a delivery dead
+	MaxAttempts int
+	// Retention is how long succeeded and dead deliveries are kept; zero keeps them forever
+	Retention time.Duration
+}
+
+// WebhookDispatcher sends pending webhook deliveries. Like OutboxRelay, any
+// number of dispatchers may run against the same database. A failed
+// delivery is retried with exponential backoff; after MaxAttempts failures
+// it is dead and stays in the delivery log until redelivered by hand.
+type WebhookDispatcher struct {
+	webhookRepo repositories.WebhookRepository
+	sender      WebhookSender
+	opts        WebhookDispatcherOptions
+	now         func() time.Time
+}
+
+func NewWebhookDispatcher(webhookRepo repositories.WebhookRepository, sender WebhookSender, opts WebhookDispatcherOptions) *WebhookDispatcher {
+	return &WebhookDispatcher{webhookRepo: webhookRepo, sender: sender, opts: opts, now: time.Now}
+}
+
+// RunOnce sends one batch of due deliveries and reports how many it
+// claimed, whether or not they succeeded.
+func (d *WebhookDispatcher) RunOnce(ctx context.Context) (_ int, err error) {
+	ctx, end := tracing.Start(ctx, "WebhookDispatcher.RunOnce")
+	defer end(&err)
+	return d.webhookRepo.ClaimDueDeliveries(ctx, d.opts.BatchSize, d.opts.Lease, func(deliveries []models.WebhookDelivery) error {
+		for i := range deliveries {
+			d.deliver(ctx, &deliveries[i])
+		}
+		return nil
+	})
+}
+
+func (d *WebhookDispatcher) deliver(ctx context.Context, delivery *models.WebhookDelivery) {
+	status, err := d.sender.Send(ctx, delivery)
+	now := d.now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = status
+	if err == nil {
+		delivery.Status, delivery.LastError = models.WebhookDeliverySucceeded, ""
+		return
+	}
+	delivery.LastError = err.Error()
+	if len(delivery.LastError) > maxWebhookErrorLength {
+		delivery.LastError = delivery.LastError[:maxWebhookErrorLength]
+	}
+	log := logger.FromContext(ctx).With("delivery_id", delivery.ID, "subscription_id", delivery.SubscriptionID,
+		"event_id", delivery.EventID, "attempts", delivery.Attempts, "status", status, "error", err)
+	if delivery.Attempts >= d.opts.MaxAttempts {
+		delivery.Status = models.WebhookDeliveryDead
+		log.Errorw("Webhook delivery failed permanently")
+		return
+	}
+	delivery.NextAttemptAt = now.Add(webhookRetryDelay(delivery.Attempts))
+	log.Warnw("Webhook delivery failed", "retry_at", delivery.NextAttemptAt)
+}
+
+// webhookRetryDelay doubles from webhookRetryBase with each failed attempt.
+func webhookRetryDelay(attempts int) time.Duration {
+	if attempts > 10 { // 30s·2^10 already exceeds the cap
+		return maxWebhookRetryDelay
+	}
+	return min(webhookRetryBase<<(attempts-1), maxWebhookRetryDelay)
+}
+
+// Run sends deliveries until ctx is cancelled. It drains full batches back
+// to back and otherwise polls every PollInterval. Old finished deliveries
+// are deleted once an hour.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	log := logger.FromContext(ctx)
+	lastCleanup := time.Time{}
+	for {
+		n, err := d.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Errorw("Webhook dispatcher failed", "error", err)
+		}
+		if d.opts.Retention > 0 && d.now().Sub(lastCleanup) >= webhookCleanupInterval {
+			lastCleanup = d.now()
+			if _, err := d.webhookRepo.DeleteFinishedBefore(ctx, lastCleanup.Add(-d.opts.Retention)); err != nil && ctx.Err() == nil {
+				log.Errorw("Webhook delivery cleanup failed", "error", err)
+			}
+		}
+		if err == nil && n == d.opts.BatchSize {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.opts.PollInterval):
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/apperror"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/events"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/tracing"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/webhooks"
+)
+
+var (
+	ErrWebhookNotFound         = apperror.New(apperror.KindNotFound, "webhook_not_found", "webhook subscription not found")
+	ErrWebhookDeliveryNotFound = apperror.New(apperror.KindNotFound, "webhook_delivery_not_found", "webhook delivery not found")
+)
+
+// WebhookService manages webhook subscriptions and their delivery log.
+// Deliveries are created by Enqueue and sent by WebhookDispatcher.
+type WebhookService interface {
+	// CreateSubscription generates a secret unless in.Secret is set. The
+	// returned subscription carries the secret; nothing else returns it.
+	CreateSubscription(ctx context.Context, in WebhookInput) (*models.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, id uint) (*models.WebhookSubscription, error)
+	// UpdateSubscription replaces the subscription's settings with in. An
+	// empty in.Secret keeps the current secret.
+	UpdateSubscription(ctx context.Context, id uint, in WebhookInput) (*models.WebhookSubscription, error)
+	// DeleteSubscription removes a subscription together with its deliveries.
+	DeleteSubscription(ctx context.Context, id uint) error
+	// ListDeliveries returns a page of a subscription's deliveries, newest first.
+	ListDeliveries(ctx context.Context, q WebhookDeliveryQuery) (*WebhookDeliveryPage, error)
+	// Redeliver sends a delivery again as soon as possible, whatever its
+	// state, with a fresh set of attempts.
+	Redeliver(ctx context.Context, subscriptionID, deliveryID uint) (*models.WebhookDelivery, error)
+	// Enqueue creates a delivery of env for every active subscription that
+	// wants it. It is an events.Handler for the in-process bus.
+	Enqueue(ctx context.Context, env events.Envelope) error
+}
+
+// WebhookInput holds the settings of a subscription.
+type WebhookInput struct {
+	URL         string
+	EventTypes  []string
+	Description string
+	Active      bool
+	Secret      string
+}
+
+type WebhookDeliveryQuery struct {
+	SubscriptionID uint
+	// Status restricts the page to one delivery state; empty means any
+	Status string
+	Before uint
+	Limit  int
+}
+
+// WebhookDeliveryPage is one page of deliveries. NextBefore is zero on the last page.
+type WebhookDeliveryPage struct {
+	Deliveries []models.WebhookDelivery
+	Limit      int
+	NextBefore uint
+}
+
+type webhookServiceImpl struct {
+	webhookRepo repositories.WebhookRepository
+	addresses   *webhooks.AddressPolicy
+}
+
+// NewWebhookService returns a WebhookService accepting subscription URLs
+// that addresses permits.
+func NewWebhookService(webhookRepo repositories.WebhookRepository, addresses *webhooks.AddressPolicy) WebhookService {
+	return &webhookServiceImpl{webhookRepo: webhookRepo, addresses: addresses}
+}
+
+func (s *webhookServiceImpl) CreateSubscription(ctx context.Context, in WebhookInput) (_ *models.WebhookSubscription, err error) {
+	ctx, end := tracing.Start(ctx, "WebhookService.CreateSubscription")
+	defer end(&err)
+	if err := s.validateWebhookInput(ctx, in); err != nil {
+		return nil, err
+	}
+	if in.Secret == "" {
+		if in.Secret, err = webhooks.NewSecret(); err != nil {
+			return nil, err
+		}
+	}
+	sub := &models.WebhookSubscription{}
+	applyWebhookInput(sub, in)
+	if err := s.webhookRepo.CreateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *webhookServiceImpl) ListSubscriptions(ctx context.Context) (_ []models.WebhookSubscription, err error) {
+	ctx, end := tracing.Start(ctx, "WebhookService.ListSubscriptions")
+	defer end(&err)
+	return s.webhookRepo.ListSubscriptions(ctx)
+}
+
+func (s *webhookServiceImpl) GetSubscription(ctx context.Context, id uint) (_ *models.WebhookSubscription, err error) {
+	ctx, end := tracing.Start(ctx, "WebhookService.GetSubscription")
+	defer end(&err)
+	return s.subscription(ctx, id)
+}
+
+func (s *webhookServiceImpl) UpdateSubscription(ctx context.Context, id uint, in WebhookInput) (_ *models.WebhookSubscription, err error) {
+	ctx, end := tracing.Start(ctx, "WebhookService.UpdateSubscription")
+	defer end(&err)
+	if err := s.validateWebhookInput(ctx, in); err != nil {
+		return nil, err
+	}
+	sub, err := s.subscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if in.Secret == "" {
+		in.Secret = sub.Secret
+	}
+	applyWebhookInput(sub, in)
+	if err := s.webhookRepo.UpdateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *webhookServiceImpl) DeleteSubscription(ctx context.Context, id uint) (err error) {
+	ctx, end := tracing.Start(ctx, "WebhookService.DeleteSubscription")
+	defer end(&err)
+	deleted, err := s.webhookRepo.DeleteSubscription(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (s *webhookServiceImpl) ListDeliveries(ctx context.Context, q WebhookDeliveryQuery) (_ *WebhookDeliveryPage, err error) {
+	ctx, end := tracing.Start(ctx, "WebhookService.ListDeliveries")
+	defer end(&err)
+	limit, err := checkLimit(q.Limit)
+	if err != nil {
+		return nil, err
+	}
+	switch q.Status {
+	case "", models.WebhookDeliveryPending, models.WebhookDeliverySucceeded, models.WebhookDeliveryDead:
+	default:
+		return nil, ErrValidationFailed.WithFields(apperror.FieldError{
+			Field: "status", Code: "oneof", Message: "must be pending, succeeded or dead",
+		})
+	}
+	if _, err := s.subscription(ctx, q.SubscriptionID); err != nil {
+		return nil, err
+	}
+	// Fetch one extra row to learn whether another page follows
+	deliveries, err := s.webhookRepo.ListDeliveries(ctx, q.SubscriptionID, q.Status, q.Before, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	page := &WebhookDeliveryPage{Deliveries: deliveries, Limit: limit}
+	if len(deliveries) > limit {
+		page.Deliveries = deliveries[:limit]
+		page.NextBefore = page.Deliveries[limit-1].ID
+	}
+	return page, nil
+}
+
+func (s *webhookServiceImpl) Redeliver(ctx context.Context, subscriptionID, deliveryID uint) (_ *models.WebhookDelivery, err error) {
+	ctx, end := tracing.Start(ctx, "WebhookService.Redeliver")
+	defer end(&err)
+	delivery, err := s.webhookRepo.GetDelivery(ctx, subscriptionID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery == nil {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+	delivery.Status = models.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	if err := s.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+func (s *webhookServiceImpl) Enqueue(ctx context.Context, env events.Envelope) (err error) {
+	ctx, end := tracing.Start(ctx, "WebhookService.Enqueue")
+	defer end(&err)
+	subs, err := s.webhookRepo.ListSubscribers(ctx, env.Type)
+	if err != nil || len(subs) == 0 {
+		return err
+	}
+	body, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	deliveries := make([]*models.WebhookDelivery, len(subs))
+	for i, sub := range subs {
+		deliveries[i] = &models.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        env.ID,
+			EventType:      env.Type,
+			Payload:        body,
+			Status:         models.WebhookDeliveryPending,
+			NextAttemptAt:  now,
+		}
+	}
+	return s.webhookRepo.AddDeliveries(ctx, deliveries...)
+}
+
+func (s *webhookServiceImpl) subscription(ctx context.Context, id uint) (*models.WebhookSubscription, error) {
+	sub, err := s.webhookRepo.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		return nil, ErrWebhookNotFound
+	}
+	return sub, nil
+}
+
+// validateWebhookInput checks what request binding cannot: that the URL is
+// an absolute http(s) URL of a permitted address and that every event type
+// exists.
+func (s *webhookServiceImpl) validateWebhookInput(ctx context.Context, in WebhookInput) error {
+	var problems []apperror.FieldError
+	if u, err := url.Parse(in.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		problems = append(problems, apperror.FieldError{Field: "url", Code: "url", Message: "must be an absolute http or https URL"})
+	} else if u.User != nil {
+		problems = append(problems, apperror.FieldError{Field: "url", Code: "url", Message: "must not contain credentials"})
+	} else if err := s.addresses.CheckURL(ctx, in.URL); err != nil {
+		problems = append(problems, apperror.FieldError{Field: "url", Code: "public_address", Message: "must be a publicly routable address"})
+	}
+	if len(in.EventTypes) == 0 {
+		problems = append(problems, apperror.FieldError{Field: "event_types", Code: "required", Message: "must list at least one event type"})
+	}
+	known := append(events.Types(), models.WebhookAllEvents)
+	for _, t := range in.EventTypes {
+		if !slices.Contains(known, t) {
+			problems = append(problems, apperror.FieldError{Field: "event_types", Code: "oneof", Message: "unknown event type " + t})
+		}
+	}
+	if len(problems) > 0 {
+		return ErrValidationFailed.WithFields(problems...)
+	}
+	return nil
+}
+
+func applyWebhookInput(sub *models.WebhookSubscription, in WebhookInput) {
+	types := slices.Clone(in.EventTypes)
+	slices.Sort(types)
+	sub.URL = in.URL
+	sub.EventTypes = slices.Compact(types)
+	sub.Description = in.Description
+	sub.Active = in.Active
+	sub.Secret = in.Secret
+}
@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/config"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TokenType distinguishes access, refresh, email verification and password
+// reset tokens so one can never be presented in place of another.
+type TokenType string
+
+const (
+	AccessToken            TokenType = "access"
+	RefreshToken           TokenType = "refresh"
+	EmailVerificationToken TokenType = "email_verification"
+	PasswordResetToken     TokenType = "password_reset"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+type Claims struct {
+	Email string    `json:"email,omitempty"`
+	Type  TokenType `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// UserID returns the numeric user ID carried in the subject claim.
+func (c *Claims) UserID() (uint, error) {
+	id, err := strconv.ParseUint(c.Subject, 10, 32)
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+	return uint(id), nil
+}
+
+type JWTManager struct {
+	accessSecret  []byte
+	refreshSecret []byte
+	issuer        string
+	emailSecret   []byte
+	resetSecret   []byte
+	accessTTL     time.Duration
+	refreshTTL    time.Duration
+	emailTTL      time.Duration
+	resetTTL      time.Duration
+}
+
+func NewJWTManager(cfg *config.Config) *JWTManager {
+	return &JWTManager{
+		accessSecret:  []byte(cfg.JWTAccessSecret),
+		refreshSecret: []byte(cfg.JWTRefreshSecret),
+		emailSecret:   []byte(cfg.EmailVerificationSecret),
+		resetSecret:   []byte(cfg.PasswordResetSecret),
+		issuer:        cfg.JWTIssuer,
+		accessTTL:     cfg.JWTAccessTTL,
+		refreshTTL:    cfg.JWTRefreshTTL,
+		emailTTL:      cfg.EmailVerificationTTL,
+		resetTTL:      cfg.PasswordResetTTL,
+	}
+}
+
+// AccessTTL is the lifetime of newly issued access tokens.
+func (m *JWTManager) AccessTTL() time.Duration {
+	return m.accessTTL
+}
+
+func (m *JWTManager) GenerateAccessToken(user *models.User) (string, *Claims, error) {
+	return m.generate(user, AccessToken, m.accessSecret, m.accessTTL)
+}
+
+func (m *JWTManager) GenerateRefreshToken(user *models.User) (string, *Claims, error) {
+	return m.generate(user, RefreshToken, m.refreshSecret, m.refreshTTL)
+}
+
+// GenerateEmailVerificationToken signs a token proving control of the
+// user's current email address, which the Email claim carries.
+func (m *JWTManager) GenerateEmailVerificationToken(user *models.User) (string, *Claims, error) {
+	return m.generate(user, EmailVerificationToken, m.emailSecret, m.emailTTL)
+}
+
+// GeneratePasswordResetToken signs a token that lets the user choose a new
+// password without knowing the current one.
+func (m *JWTManager) GeneratePasswordResetToken(user *models.User) (string, *Claims, error) {
+	return m.generate(user, PasswordResetToken, m.resetSecret, m.resetTTL)
+}
+
+func (m *JWTManager) ParseAccessToken(token string) (*Claims, error) {
+	return m.parse(token, AccessToken, m.accessSecret)
+}
+
+func (m *JWTManager) ParseRefreshToken(token string) (*Claims, error) {
+	return m.parse(token, RefreshToken, m.refreshSecret)
+}
+
+func (m *JWTManager) ParseEmailVerificationToken(token string) (*Claims, error) {
+	return m.parse(token, EmailVerificationToken, m.emailSecret)
+}
+
+func (m *JWTManager) ParsePasswordResetToken(token string) (*Claims, error) {
+	return m.parse(token, PasswordResetToken, m.resetSecret)
+}
+
+func (m *JWTManager) generate(user *models.User, typ TokenType, secret []byte, ttl time.Duration) (string, *Claims, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	claims := &Claims{
+		Email: user.Email,
+		Type:  typ,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Issuer:    m.issuer,
+			Subject:   strconv.FormatUint(uint64(user.ID), 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to sign %s token: %w", typ, err)
+	}
+	return signed, claims, nil
+}
+
+func (m *JWTManager) parse(token string, typ TokenType, secret []byte) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(m.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || claims.Type != typ {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/config"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password hashing algorithms
+const (
+	Argon2id = "argon2id"
+	Bcrypt   = "bcrypt"
+)
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+	// bcryptMaxLength is how many bytes of a password bcrypt uses
+	bcryptMaxLength = 72
+	// maxPasswordLength bounds the work an argon2id hash can be made to do
+	maxPasswordLength = 1024
+)
+
+// PasswordHasher hashes new passwords with the configured algorithm and
+// parameters. It verifies hashes made with either algorithm and any
+// parameters, and reports when one should be replaced, so existing users
+// move to the current settings as they log in.
+type PasswordHasher struct {
+	algorithm  string
+	bcryptCost int
+	argon2     argon2Params
+	// dummy is a hash of a random password made with the current settings
+	dummy string
+	// slots bounds how many hashes are computed at once, and so how much
+	// memory argon2id uses
+	slots chan struct{}
+}
+
+// argon2Params are stored in each hash in the PHC string format,
+// $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>.
+type argon2Params struct {
+	memory      uint32 // KiB
+	iterations  uint32
+	parallelism uint8
+}
+
+func NewPasswordHasher(cfg *config.Config) (*PasswordHasher, error) {
+	h := &PasswordHasher{
+		algorithm:  cfg.PasswordHashAlgorithm,
+		bcryptCost: cfg.PasswordBcryptCost,
+		argon2: argon2Params{
+			memory:      cfg.PasswordArgon2Memory,
+			iterations:  cfg.PasswordArgon2Iterations,
+			parallelism: cfg.PasswordArgon2Parallelism,
+		},
+	}
+	if cfg.PasswordHashConcurrency < 1 {
+		return nil, fmt.Errorf("password hash concurrency must be at least 1")
+	}
+	h.slots = make(chan struct{}, cfg.PasswordHashConcurrency)
+	switch h.algorithm {
+	case Argon2id:
+		if h.argon2.memory < 8*uint32(h.argon2.parallelism) || h.argon2.iterations < 1 || h.argon2.parallelism < 1 {
+			return nil, fmt.Errorf("invalid argon2id parameters m=%d,t=%d,p=%d", h.argon2.memory, h.argon2.iterations, h.argon2.parallelism)
+		}
+	case Bcrypt:
+		if h.bcryptCost < bcrypt.MinCost || h.bcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	default:
+		return nil, fmt.Errorf("unknown password hash algorithm %q", h.algorithm)
+	}
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	dummy, err := h.Hash(context.Background(), base64.RawStdEncoding.EncodeToString(random))
+	if err != nil {
+		return nil, err
+	}
+	h.dummy = dummy
+	return h, nil
+}
+
+// MaxLength is the longest password in bytes that Hash accepts.
+func (h *PasswordHasher) MaxLength() int {
+	if h.algorithm == Bcrypt {
+		return bcryptMaxLength
+	}
+	return maxPasswordLength
+}
+
+// acquire waits until fewer than the configured number of hashes are
+// being computed and returns the function that releases the slot. It
+// gives up with ctx's error if ctx is done before a slot is free.
+func (h *PasswordHasher) acquire(ctx context.Context) (func(), error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	select {
+	case h.slots <- struct{}{}:
+		return func() { <-h.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (h *PasswordHasher) Hash(ctx context.Context, password string) (string, error) {
+	if len(password) > h.MaxLength() {
+		return "", fmt.Errorf("password longer than %d bytes", h.MaxLength())
+	}
+	release, err := h.acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+	if h.algorithm == Bcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hash), nil
+	}
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	p := h.argon2
+	key := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, argon2KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, p.memory, p.iterations, p.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify reports whether password matches hash, and if so whether hash
+// was made with other settings than Hash uses now and should be replaced.
+// An empty or malformed hash never matches, so users without credentials
+// cannot log in. The error is ctx's, if it ends while waiting to hash.
+func (h *PasswordHasher) Verify(ctx context.Context, hash, password string) (ok, rehash bool, err error) {
+	release, err := h.acquire(ctx)
+	if err != nil {
+		return false, false, err
+	}
+	defer release()
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		p, salt, key, err := parseArgon2(hash)
+		if err != nil || len(password) > maxPasswordLength {
+			return false, false, nil
+		}
+		got := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, uint32(len(key)))
+		if subtle.ConstantTimeCompare(got, key) != 1 {
+			return false, false, nil
+		}
+		return true, h.algorithm != Argon2id || p != h.argon2, nil
+	case strings.HasPrefix(hash, "$2"):
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+			return false, false, nil
+		}
+		cost, _ := bcrypt.Cost([]byte(hash))
+		return true, h.algorithm != Bcrypt || cost != h.bcryptCost, nil
+	}
+	return false, false, nil
+}
+
+// VerifyNothing does the work of verifying password against a hash made
+// with the current settings, and fails. Callers without a hash to check,
+// such as a login with an unknown email, use it so their response takes
+// as long as a wrong password's and does not reveal that.
+func (h *PasswordHasher) VerifyNothing(ctx context.Context, password string) {
+	_, _, _ = h.Verify(ctx, h.dummy, password)
+}
+
+var errMalformedHash = errors.New("malformed argon2id hash")
+
+func parseArgon2(hash string) (p argon2Params, salt, key []byte, err error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return p, nil, nil, errMalformedHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, errMalformedHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.iterations, &p.parallelism); err != nil {
+		return p, nil, nil, errMalformedHash
+	}
+	if p.iterations < 1 || p.parallelism < 1 {
+		return p, nil, nil, errMalformedHash
+	}
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return p, nil, nil, errMalformedHash
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(key) == 0 {
+		return p, nil, nil, errMalformedHash
+	}
+	return p, salt, key, nil
+}
@@ -1,10 +1,11 @@
 package database
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/config"
-	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/migrations"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -23,10 +24,33 @@ func NewPostgresDB(cfg *config.Config) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	// Auto migrate the schemas
-	if err := db.AutoMigrate(&models.User{}); err != nil {
-		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	return db, nil
+}
+
+// NewMigratorForDB returns a Migrator for the embedded migrations/ directory.
+func NewMigratorForDB(db *gorm.DB) (*Migrator, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
 	}
+	return NewMigrator(sqlDB, migrations.FS)
+}
 
-	return db, nil
+// CheckMigrations fails if the database has migrations that have not been
+// applied yet. The API calls it at startup instead of migrating itself so
+// that schema changes are always an explicit deploy step.
+func CheckMigrations(ctx context.Context, db *gorm.DB) error {
+	migrator, err := NewMigratorForDB(db)
+	if err != nil {
+		return err
+	}
+	pending, err := migrator.Pending(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check migrations: %w", err)
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("database has %d pending migration(s), starting with %d_%s; run `go run ./cmd/migrate up`",
+			len(pending), pending[0].Version, pending[0].Name)
+	}
+	return nil
 }
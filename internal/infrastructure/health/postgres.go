@@ -0,0 +1,24 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PostgresCheck pings the database connection pool.
+func PostgresCheck(db *gorm.DB, timeout time.Duration) Check {
+	return Check{
+		Name:     "postgres",
+		Critical: true,
+		Timeout:  timeout,
+		Fn: func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		},
+	}
+}
@@ -0,0 +1,55 @@
+// Package idempotency stores the outcome of requests sent with an
+// Idempotency-Key so that retries replay the original response instead of
+// repeating the side effect.
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ErrLockLost is returned by Complete and Release when the key was
+// reclaimed by another request since the caller locked it.
+var ErrLockLost = errors.New("idempotency key was reclaimed by another request")
+
+// Record is the stored state of an idempotency key.
+type Record struct {
+	// Fingerprint identifies the request the key was first used with
+	Fingerprint string
+	// Status is zero while the original request is still in flight
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Completed reports whether the original request has finished.
+func (r *Record) Completed() bool {
+	return r.Status != 0
+}
+
+// Lease is a caller's lock on a key. A lock taken again after it was
+// abandoned has a new LockedAt, so the request that lost it cannot
+// complete or release the key under the new owner.
+type Lease struct {
+	Key         string
+	Fingerprint string
+	LockedAt    time.Time
+}
+
+// Store persists idempotency records.
+type Store interface {
+	// Lock claims key for a new request with the given fingerprint. It returns
+	// a lease when the caller now owns the key and must Complete or Release
+	// it, or else the existing record. Expired records and locks abandoned
+	// for longer than the store's lock timeout are reclaimed.
+	Lock(ctx context.Context, key, fingerprint string) (*Lease, *Record, error)
+	// Complete stores the response for a key while lease still holds it,
+	// and returns ErrLockLost otherwise.
+	Complete(ctx context.Context, lease *Lease, rec Record) error
+	// Release drops the lock on a key without storing a response, so the
+	// request can be retried. It returns ErrLockLost if lease no longer
+	// holds the key.
+	Release(ctx context.Context, lease *Lease) error
+}
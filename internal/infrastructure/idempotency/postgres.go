@@ -0,0 +1,144 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// sweepInterval is how often expired keys are deleted.
+const sweepInterval = time.Minute
+
+// PostgresStore keeps records in the idempotency_keys table. Keys expire
+// after ttl; a lock whose request has not completed within lockTimeout is
+// assumed abandoned, e.g. by a crashed replica, and may be reclaimed.
+type PostgresStore struct {
+	db          *gorm.DB
+	ttl         time.Duration
+	lockTimeout time.Duration
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func NewPostgresStore(db *gorm.DB, ttl, lockTimeout time.Duration) *PostgresStore {
+	return &PostgresStore{db: db, ttl: ttl, lockTimeout: lockTimeout}
+}
+
+// The upsert only claims a key that is new, expired or abandoned, so a row
+// is returned exactly when the caller becomes the owner. Its locked_at
+// fences the owner's Complete and Release.
+const lockSQL = `
+INSERT INTO idempotency_keys AS k (key, fingerprint, locked_at, expires_at)
+VALUES (@key, @fingerprint, now(), now() + make_interval(secs => @ttl))
+ON CONFLICT (key) DO UPDATE
+SET fingerprint = EXCLUDED.fingerprint,
+    locked_at = EXCLUDED.locked_at,
+    expires_at = EXCLUDED.expires_at,
+    status = NULL,
+    header = NULL,
+    body = NULL
+WHERE k.expires_at < now()
+   OR (k.status IS NULL AND k.locked_at < now() - make_interval(secs => @lock_timeout))
+RETURNING locked_at`
+
+type recordRow struct {
+	Fingerprint string
+	Status      *int
+	Header      []byte
+	Body        []byte
+}
+
+func (s *PostgresStore) Lock(ctx context.Context, key, fingerprint string) (*Lease, *Record, error) {
+	s.sweep(ctx)
+
+	// A key released between the upsert and the lookup is free again; retry
+	for attempt := 0; attempt < 3; attempt++ {
+		var claimed []struct{ LockedAt time.Time }
+		err := s.db.WithContext(ctx).Raw(lockSQL, map[string]any{
+			"key":          key,
+			"fingerprint":  fingerprint,
+			"ttl":          s.ttl.Seconds(),
+			"lock_timeout": s.lockTimeout.Seconds(),
+		}).Scan(&claimed).Error
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(claimed) == 1 {
+			return &Lease{Key: key, Fingerprint: fingerprint, LockedAt: claimed[0].LockedAt}, nil, nil
+		}
+
+		var rows []recordRow
+		err = s.db.WithContext(ctx).
+			Raw(`SELECT fingerprint, status, header, body FROM idempotency_keys WHERE key = ?`, key).
+			Scan(&rows).Error
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		rec := &Record{Fingerprint: rows[0].Fingerprint, Body: rows[0].Body}
+		if rows[0].Status != nil {
+			rec.Status = *rows[0].Status
+		}
+		if len(rows[0].Header) > 0 {
+			if err := json.Unmarshal(rows[0].Header, &rec.Header); err != nil {
+				return nil, nil, err
+			}
+		}
+		return nil, rec, nil
+	}
+	return nil, nil, errors.New("idempotency key changed hands repeatedly while locking")
+}
+
+func (s *PostgresStore) Complete(ctx context.Context, lease *Lease, rec Record) error {
+	header, err := json.Marshal(rec.Header)
+	if err != nil {
+		return err
+	}
+	result := s.db.WithContext(ctx).Exec(
+		`UPDATE idempotency_keys SET status = ?, header = ?, body = ?
+		WHERE key = ? AND fingerprint = ? AND locked_at = ? AND status IS NULL`,
+		rec.Status, header, rec.Body, lease.Key, lease.Fingerprint, lease.LockedAt,
+	)
+	return leaseResult(result)
+}
+
+func (s *PostgresStore) Release(ctx context.Context, lease *Lease) error {
+	result := s.db.WithContext(ctx).Exec(
+		`DELETE FROM idempotency_keys WHERE key = ? AND fingerprint = ? AND locked_at = ? AND status IS NULL`,
+		lease.Key, lease.Fingerprint, lease.LockedAt,
+	)
+	return leaseResult(result)
+}
+
+// leaseResult reports a statement fenced on a lease that matched no row
+// as ErrLockLost.
+func leaseResult(result *gorm.DB) error {
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// sweep deletes expired keys at most once per sweepInterval per replica.
+func (s *PostgresStore) sweep(ctx context.Context) {
+	s.mu.Lock()
+	if time.Since(s.lastSweep) < sweepInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastSweep = time.Now()
+	s.mu.Unlock()
+	s.db.WithContext(ctx).Exec(`DELETE FROM idempotency_keys WHERE expires_at < now()`)
+}
+
+var _ Store = (*PostgresStore)(nil)
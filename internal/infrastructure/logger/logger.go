@@ -1,6 +1,8 @@
 package logger
 
 import (
+	"context"
+
 	"go.uber.org/zap"
 )
 
@@ -8,6 +10,12 @@ type Logger struct {
 	*zap.SugaredLogger
 }
 
+type ctxKey struct{}
+
+// nop is returned by FromContext when no logger was attached, so code paths
+// outside a request (CLIs, tests) never have to nil-check.
+var nop = &Logger{zap.NewNop().Sugar()}
+
 func NewLogger(level string) *Logger {
 	var zapLogger *zap.Logger
 	var err error
@@ -26,6 +34,30 @@ func NewLogger(level string) *Logger {
 	return &Logger{sugar}
 }
 
+// With returns a child logger that adds the given key-value pairs to every entry.
+func (l *Logger) With(args ...interface{}) *Logger {
+	return &Logger{l.SugaredLogger.With(args...)}
+}
+
 func (l *Logger) Sync() {
 	_ = l.SugaredLogger.Sync()
 }
+
+// NewContext returns a copy of ctx carrying l. The RequestID middleware uses
+// it to attach a child logger scoped to the current request.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext returns the logger attached to ctx, or a no-op logger.
+func FromContext(ctx context.Context) *Logger {
+	return FromContextOr(ctx, nop)
+}
+
+// FromContextOr returns the logger attached to ctx, or fallback.
+func FromContextOr(ctx context.Context, fallback *Logger) *Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*Logger); ok && l != nil {
+		return l
+	}
+	return fallback
+}
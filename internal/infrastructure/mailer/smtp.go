@@ -0,0 +1,113 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/jobs"
+)
+
+// errNoSTARTTLS is returned when TLS is required but the server does not
+// offer STARTTLS.
+var errNoSTARTTLS = errors.New("smtp: server does not offer STARTTLS")
+
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	// RequireTLS refuses to send when the server does not offer STARTTLS,
+	// instead of sending the message in plaintext
+	RequireTLS bool
+}
+
+// SMTPMailer sends messages through an SMTP server, upgrading the
+// connection with STARTTLS when the server offers it. Credentials are only
+// sent over TLS. A 5xx reply fails with a jobs.Permanent error, since
+// sending the same message again would be rejected again.
+type SMTPMailer struct {
+	cfg SMTPConfig
+}
+
+func NewSMTPMailer(cfg SMTPConfig) *SMTPMailer {
+	return &SMTPMailer{cfg: cfg}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	body, err := format(m.cfg.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	from, _ := mail.ParseAddress(m.cfg.From) // Validated by format
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(m.cfg.Host, m.cfg.Port))
+	if err != nil {
+		return smtpError("smtp", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	// Unblock a stalled exchange when ctx is cancelled
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, m.cfg.Host)
+	if err != nil {
+		return smtpError("smtp", err)
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: m.cfg.Host}); err != nil {
+			return smtpError("smtp starttls", err)
+		}
+	} else if m.cfg.RequireTLS {
+		return errNoSTARTTLS
+	}
+	if m.cfg.Username != "" {
+		// PlainAuth refuses to send credentials over an unencrypted connection
+		// to anything but localhost
+		if err := c.Auth(smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)); err != nil {
+			return smtpError("smtp auth", err)
+		}
+	}
+	if err := c.Mail(from.Address); err != nil {
+		return smtpError("smtp", err)
+	}
+	for _, addr := range msg.To {
+		to, _ := mail.ParseAddress(addr)
+		if err := c.Rcpt(to.Address); err != nil {
+			return smtpError("smtp", err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return smtpError("smtp", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return smtpError("smtp", err)
+	}
+	if err := w.Close(); err != nil {
+		return smtpError("smtp", err)
+	}
+	return c.Quit()
+}
+
+// smtpError prefixes err with the failed step and marks 5xx replies, which
+// retrying will not change, as permanent.
+func smtpError(step string, err error) error {
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		err = jobs.Permanent(err)
+	}
+	return fmt.Errorf("%s: %w", step, err)
+}
@@ -0,0 +1,17 @@
+package persistence
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// uniqueViolation is the SQLSTATE Postgres reports for a write that would
+// break a unique constraint or index.
+const uniqueViolation = "23505"
+
+// isUniqueViolation reports whether err is a unique violation.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
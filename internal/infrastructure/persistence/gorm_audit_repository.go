@@ -0,0 +1,93 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"gorm.io/gorm"
+)
+
+// auditAppendBatchSize bounds the rows per INSERT when appending many entries.
+const auditAppendBatchSize = 500
+
+type GormAuditRepository struct {
+	db *gorm.DB
+}
+
+func NewGormAuditRepository(db *gorm.DB) repositories.AuditRepository {
+	return &GormAuditRepository{db: db}
+}
+
+// Append takes a transaction-scoped advisory lock before reading the chain
+// head, so concurrent appends link up in commit order. The lock is held
+// until the enclosing transaction ends; audited writes therefore commit one
+// at a time.
+func (r *GormAuditRepository) Append(ctx context.Context, entries ...*models.AuditEntry) (err error) {
+	ctx, end := startSpan(ctx, "GormAuditRepository.Append", "audit_log", "INSERT")
+	defer end(&err)
+	if len(entries) == 0 {
+		return nil
+	}
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('audit_log'))").Error; err != nil {
+			return err
+		}
+		var head []string
+		if err := tx.Model(&models.AuditEntry{}).Order("id DESC").Limit(1).Pluck("hash", &head).Error; err != nil {
+			return err
+		}
+		prev := ""
+		if len(head) > 0 {
+			prev = head[0]
+		}
+		// timestamptz keeps microseconds; hash what will be read back
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		for _, e := range entries {
+			e.CreatedAt = now
+			if err := e.Seal(prev); err != nil {
+				return err
+			}
+			prev = e.Hash
+		}
+		return tx.CreateInBatches(entries, auditAppendBatchSize).Error
+	})
+	return logQueryError(ctx, "audit_log.append", err)
+}
+
+func (r *GormAuditRepository) List(ctx context.Context, q repositories.AuditQuery, before uint, limit int) (entries []models.AuditEntry, err error) {
+	ctx, end := startSpan(ctx, "GormAuditRepository.List", "audit_log", "SELECT")
+	defer end(&err)
+	db := r.db.WithContext(ctx)
+	if q.ActorID != nil {
+		db = db.Where("actor_id = ?", *q.ActorID)
+	}
+	if q.Action != "" {
+		db = db.Where("action = ?", q.Action)
+	}
+	if q.TargetType != "" {
+		db = db.Where("target_type = ?", q.TargetType)
+	}
+	if q.TargetID != nil {
+		db = db.Where("target_id = ?", *q.TargetID)
+	}
+	if !q.Since.IsZero() {
+		db = db.Where("created_at >= ?", q.Since)
+	}
+	if !q.Until.IsZero() {
+		db = db.Where("created_at <= ?", q.Until)
+	}
+	if before > 0 {
+		db = db.Where("id < ?", before)
+	}
+	err = db.Order("id DESC").Limit(limit).Find(&entries).Error
+	return entries, logQueryError(ctx, "audit_log.list", err)
+}
+
+func (r *GormAuditRepository) Chain(ctx context.Context, after uint, limit int) (entries []models.AuditEntry, err error) {
+	ctx, end := startSpan(ctx, "GormAuditRepository.Chain", "audit_log", "SELECT")
+	defer end(&err)
+	err = r.db.WithContext(ctx).Where("id > ?", after).Order("id").Limit(limit).Find(&entries).Error
+	return entries, logQueryError(ctx, "audit_log.chain", err)
+}
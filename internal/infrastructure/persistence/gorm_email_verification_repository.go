@@ -0,0 +1,46 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormEmailVerificationRepository struct {
+	db *gorm.DB
+}
+
+func NewGormEmailVerificationRepository(db *gorm.DB) repositories.EmailVerificationRepository {
+	return &GormEmailVerificationRepository{db: db}
+}
+
+func (r *GormEmailVerificationRepository) Create(ctx context.Context, token *models.EmailVerificationToken) (err error) {
+	ctx, end := startSpan(ctx, "GormEmailVerificationRepository.Create", "email_verification_tokens", "INSERT")
+	defer end(&err)
+	if err := r.db.WithContext(ctx).Create(token).Error; err != nil {
+		return logQueryError(ctx, "email_verification_tokens.create", err)
+	}
+	return nil
+}
+
+func (r *GormEmailVerificationRepository) Use(ctx context.Context, tokenID string) (_ *models.EmailVerificationToken, err error) {
+	ctx, end := startSpan(ctx, "GormEmailVerificationRepository.Use", "email_verification_tokens", "UPDATE")
+	defer end(&err)
+	// Conditional update, so of two concurrent uses only one succeeds
+	var token models.EmailVerificationToken
+	now := time.Now()
+	result := r.db.WithContext(ctx).Model(&token).Clauses(clause.Returning{}).
+		Where("token_id = ? AND used_at IS NULL AND expires_at > ?", tokenID, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, logQueryError(ctx, "email_verification_tokens.use", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &token, nil
+}
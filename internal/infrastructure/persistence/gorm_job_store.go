@@ -0,0 +1,154 @@
+package persistence
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/jobs"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// claimJobsSQL leases due jobs in one statement. Rows locked by a
+// concurrent claim are skipped rather than waited for.
+const claimJobsSQL = `
+UPDATE jobs SET
+    state = 'running',
+    attempts = attempts + 1,
+    locked_by = ?,
+    locked_until = now() + make_interval(secs => ?),
+    updated_at = now()
+WHERE id IN (
+    SELECT id FROM jobs
+    WHERE queue IN ?
+      AND ((state = 'available' AND run_at <= now()) OR (state = 'running' AND locked_until < now()))
+    ORDER BY run_at, id
+    LIMIT ?
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *`
+
+// GormJobStore keeps jobs in the jobs table. GormUserRepository.Jobs binds
+// one to its transaction, so jobs are enqueued atomically with other writes.
+type GormJobStore struct {
+	db *gorm.DB
+}
+
+func NewGormJobStore(db *gorm.DB) *GormJobStore {
+	return &GormJobStore{db: db}
+}
+
+var _ jobs.Store = (*GormJobStore)(nil)
+
+func (s *GormJobStore) Insert(ctx context.Context, job *jobs.Job) (_ bool, err error) {
+	ctx, end := startSpan(ctx, "GormJobStore.Insert", "jobs", "INSERT")
+	defer end(&err)
+	result := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "unique_key"}},
+		// Matches the partial unique index on unique_key
+		TargetWhere: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: "unique_key IS NOT NULL AND state IN ('available', 'running')"},
+		}},
+		DoNothing: true,
+	}).Create(job)
+	if result.Error != nil {
+		return false, logQueryError(ctx, "jobs.insert", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (s *GormJobStore) Claim(ctx context.Context, worker string, queues []string, limit int, lease time.Duration) (_ []jobs.Job, err error) {
+	ctx, end := startSpan(ctx, "GormJobStore.Claim", "jobs", "UPDATE")
+	defer end(&err)
+	var claimed []jobs.Job
+	if err := s.db.WithContext(ctx).Raw(claimJobsSQL, worker, lease.Seconds(), queues, limit).Scan(&claimed).Error; err != nil {
+		return nil, logQueryError(ctx, "jobs.claim", err)
+	}
+	// RETURNING does not preserve the subquery's order
+	slices.SortFunc(claimed, func(a, b jobs.Job) int { return a.RunAt.Compare(b.RunAt) })
+	return claimed, nil
+}
+
+func (s *GormJobStore) Extend(ctx context.Context, job *jobs.Job, lease time.Duration) (err error) {
+	ctx, end := startSpan(ctx, "GormJobStore.Extend", "jobs", "UPDATE")
+	defer end(&err)
+	return s.finish(ctx, "jobs.extend", job, map[string]interface{}{
+		"locked_until": gorm.Expr("now() + make_interval(secs => ?)", lease.Seconds()),
+	})
+}
+
+func (s *GormJobStore) Complete(ctx context.Context, job *jobs.Job) (err error) {
+	ctx, end := startSpan(ctx, "GormJobStore.Complete", "jobs", "UPDATE")
+	defer end(&err)
+	return s.finish(ctx, "jobs.complete", job, map[string]interface{}{
+		"state":        jobs.StateSucceeded,
+		"finished_at":  gorm.Expr("now()"),
+		"locked_by":    "",
+		"locked_until": nil,
+		"last_error":   "",
+	})
+}
+
+func (s *GormJobStore) Retry(ctx context.Context, job *jobs.Job, runAt time.Time, reason string) (err error) {
+	ctx, end := startSpan(ctx, "GormJobStore.Retry", "jobs", "UPDATE")
+	defer end(&err)
+	return s.finish(ctx, "jobs.retry", job, map[string]interface{}{
+		"state":        jobs.StateAvailable,
+		"run_at":       runAt,
+		"locked_by":    "",
+		"locked_until": nil,
+		"last_error":   reason,
+	})
+}
+
+func (s *GormJobStore) Fail(ctx context.Context, job *jobs.Job, reason string) (err error) {
+	ctx, end := startSpan(ctx, "GormJobStore.Fail", "jobs", "UPDATE")
+	defer end(&err)
+	return s.finish(ctx, "jobs.fail", job, map[string]interface{}{
+		"state":        jobs.StateFailed,
+		"finished_at":  gorm.Expr("now()"),
+		"locked_by":    "",
+		"locked_until": nil,
+		"last_error":   reason,
+	})
+}
+
+// finish applies changes to a running job only while the claim that
+// returned job holds its lease. Every claim counts an attempt, so attempts
+// tells a worker's claims of the same job apart.
+func (s *GormJobStore) finish(ctx context.Context, operation string, job *jobs.Job, changes map[string]interface{}) error {
+	changes["updated_at"] = gorm.Expr("now()")
+	result := s.db.WithContext(ctx).Model(&jobs.Job{}).
+		Where("id = ? AND state = ? AND locked_by = ? AND attempts = ?", job.ID, jobs.StateRunning, job.LockedBy, job.Attempts).
+		Updates(changes)
+	if result.Error != nil {
+		return logQueryError(ctx, operation, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return jobs.ErrLeaseLost
+	}
+	return nil
+}
+
+func (s *GormJobStore) Depth(ctx context.Context) (_ []jobs.QueueDepth, err error) {
+	ctx, end := startSpan(ctx, "GormJobStore.Depth", "jobs", "SELECT")
+	defer end(&err)
+	var depths []jobs.QueueDepth
+	err = s.db.WithContext(ctx).Model(&jobs.Job{}).
+		Select("queue, state, count(*) AS count, min(run_at) FILTER (WHERE run_at <= now()) AS oldest_due").
+		Where("state IN ?", []string{jobs.StateAvailable, jobs.StateRunning, jobs.StateFailed}).
+		Group("queue, state").
+		Order("queue, state").
+		Scan(&depths).Error
+	return depths, logQueryError(ctx, "jobs.depth", err)
+}
+
+func (s *GormJobStore) DeleteFinishedBefore(ctx context.Context, cutoff time.Time) (_ int64, err error) {
+	ctx, end := startSpan(ctx, "GormJobStore.DeleteFinishedBefore", "jobs", "DELETE")
+	defer end(&err)
+	result := s.db.WithContext(ctx).
+		Where("state IN ? AND finished_at < ?", []string{jobs.StateSucceeded, jobs.StateFailed}, cutoff).
+		Delete(&jobs.Job{})
+	return result.RowsAffected, logQueryError(ctx, "jobs.delete_finished_before", result.Error)
+}
@@ -0,0 +1,98 @@
+package persistence
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"gorm.io/gorm"
+)
+
+// outboxAddBatchSize bounds the rows per INSERT when adding many messages.
+const outboxAddBatchSize = 500
+
+type GormOutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewGormOutboxRepository(db *gorm.DB) repositories.OutboxRepository {
+	return &GormOutboxRepository{db: db}
+}
+
+func (r *GormOutboxRepository) Add(ctx context.Context, msgs ...*models.OutboxMessage) (err error) {
+	ctx, end := startSpan(ctx, "GormOutboxRepository.Add", "outbox_events", "INSERT")
+	defer end(&err)
+	if len(msgs) == 0 {
+		return nil
+	}
+	return logQueryError(ctx, "outbox_events.add", r.db.WithContext(ctx).CreateInBatches(msgs, outboxAddBatchSize).Error)
+}
+
+// claimOutboxSQL leases due messages in one statement by moving their
+// available_at past the lease. Rows locked by a concurrent claim are
+// skipped rather than waited for.
+const claimOutboxSQL = `
+UPDATE outbox_events SET available_at = now() + make_interval(secs => ?)
+WHERE id IN (
+    SELECT id FROM outbox_events
+    WHERE published_at IS NULL AND available_at <= now()
+    ORDER BY id
+    LIMIT ?
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *`
+
+// ClaimDue commits the claim before calling fn, so no transaction or row
+// lock is held while fn publishes to external sinks.
+func (r *GormOutboxRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration, fn func(msgs []models.OutboxMessage) error) (claimed int, err error) {
+	ctx, end := startSpan(ctx, "GormOutboxRepository.ClaimDue", "outbox_events", "UPDATE")
+	defer end(&err)
+	var msgs []models.OutboxMessage
+	if err := r.db.WithContext(ctx).Raw(claimOutboxSQL, lease.Seconds(), limit).Scan(&msgs).Error; err != nil {
+		return 0, logQueryError(ctx, "outbox_events.claim_due", err)
+	}
+	if len(msgs) == 0 {
+		return 0, nil
+	}
+	// RETURNING does not preserve the subquery's order
+	slices.SortFunc(msgs, func(a, b models.OutboxMessage) int { return cmp.Compare(a.ID, b.ID) })
+	leases := make([]time.Time, len(msgs))
+	for i := range msgs {
+		leases[i] = msgs[i].AvailableAt
+	}
+
+	if err := fn(msgs); err != nil {
+		return 0, err
+	}
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, msg := range msgs {
+			// A message whose lease ran out may have been claimed again;
+			// its new holder's result wins
+			if err := tx.Model(&models.OutboxMessage{}).
+				Where("id = ? AND published_at IS NULL AND available_at = ?", msg.ID, leases[i]).
+				Updates(map[string]interface{}{
+					"published_at": msg.PublishedAt,
+					"attempts":     msg.Attempts,
+					"last_error":   msg.LastError,
+					"available_at": msg.AvailableAt,
+				}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, logQueryError(ctx, "outbox_events.save_claimed", err)
+	}
+	return len(msgs), nil
+}
+
+func (r *GormOutboxRepository) DeletePublishedBefore(ctx context.Context, cutoff time.Time) (_ int64, err error) {
+	ctx, end := startSpan(ctx, "GormOutboxRepository.DeletePublishedBefore", "outbox_events", "DELETE")
+	defer end(&err)
+	result := r.db.WithContext(ctx).Where("published_at < ?", cutoff).Delete(&models.OutboxMessage{})
+	return result.RowsAffected, logQueryError(ctx, "outbox_events.delete_published_before", result.Error)
+}
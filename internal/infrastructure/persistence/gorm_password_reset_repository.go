@@ -0,0 +1,58 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormPasswordResetRepository struct {
+	db *gorm.DB
+}
+
+func NewGormPasswordResetRepository(db *gorm.DB) repositories.PasswordResetRepository {
+	return &GormPasswordResetRepository{db: db}
+}
+
+func (r *GormPasswordResetRepository) Create(ctx context.Context, token *models.PasswordResetToken) (err error) {
+	ctx, end := startSpan(ctx, "GormPasswordResetRepository.Create", "password_reset_tokens", "INSERT")
+	defer end(&err)
+	if err := r.db.WithContext(ctx).Create(token).Error; err != nil {
+		return logQueryError(ctx, "password_reset_tokens.create", err)
+	}
+	return nil
+}
+
+func (r *GormPasswordResetRepository) Use(ctx context.Context, tokenID string) (_ *models.PasswordResetToken, err error) {
+	ctx, end := startSpan(ctx, "GormPasswordResetRepository.Use", "password_reset_tokens", "UPDATE")
+	defer end(&err)
+	// Conditional update, so of two concurrent uses only one succeeds
+	var token models.PasswordResetToken
+	now := time.Now()
+	result := r.db.WithContext(ctx).Model(&token).Clauses(clause.Returning{}).
+		Where("token_id = ? AND used_at IS NULL AND expires_at > ?", tokenID, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, logQueryError(ctx, "password_reset_tokens.use", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &token, nil
+}
+
+func (r *GormPasswordResetRepository) UseAllForUser(ctx context.Context, userID uint) (err error) {
+	ctx, end := startSpan(ctx, "GormPasswordResetRepository.UseAllForUser", "password_reset_tokens", "UPDATE")
+	defer end(&err)
+	err = r.db.WithContext(ctx).Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+	if err != nil {
+		return logQueryError(ctx, "password_reset_tokens.use_all", err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"gorm.io/gorm"
+)
+
+type GormRoleRepository struct {
+	db *gorm.DB
+}
+
+func NewGormRoleRepository(db *gorm.DB) repositories.RoleRepository {
+	return &GormRoleRepository{db: db}
+}
+
+func (r *GormRoleRepository) List(ctx context.Context) ([]models.Role, error) {
+	var roles []models.Role
+	if err := r.db.WithContext(ctx).Preload("Permissions").Order("name").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *GormRoleRepository) GetByName(ctx context.Context, name string) (*models.Role, error) {
+	var role models.Role
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *GormRoleRepository) ListForUser(ctx context.Context, userID uint) ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.WithContext(ctx).Preload("Permissions").
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userID).
+		Order("roles.name").
+		Find(&roles).Error
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *GormRoleRepository) Assign(ctx context.Context, userID uint, role *models.Role) error {
+	return r.db.WithContext(ctx).Model(&models.User{ID: userID}).Association("Roles").Append(role)
+}
+
+func (r *GormRoleRepository) Unassign(ctx context.Context, userID uint, role *models.Role) error {
+	return r.db.WithContext(ctx).Model(&models.User{ID: userID}).Association("Roles").Delete(role)
+}
+
+func (r *GormRoleRepository) UserHasPermission(ctx context.Context, userID uint, permission string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Table("user_roles").
+		Joins("JOIN role_permissions ON role_permissions.role_id = user_roles.role_id").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Where("user_roles.user_id = ? AND permissions.name = ?", userID, permission).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -1,13 +1,17 @@
 package persistence
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
-	"math"
+	"fmt"
+	"time"
 
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
-	"github.com/gin-gonic/gin"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/jobs"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type GormUserRepository struct {
@@ -18,51 +22,302 @@ func NewGormUserRepository(db *gorm.DB) repositories.UserRepository {
 	return &GormUserRepository{db: db}
 }
 
-func (r *GormUserRepository) List(c *gin.Context, page, limit int) ([]models.User, int64, error) {
-	var users []models.User
-	var total int64
+func (r *GormUserRepository) List(ctx context.Context, q repositories.UserQuery, page, limit int) (users []models.User, total int64, err error) {
+	ctx, end := startSpan(ctx, "GormUserRepository.List", "users", "SELECT")
+	defer end(&err)
 	offset := (page - 1) * limit
 
-	if err := r.db.Model(&models.User{}).Count(&total).Error; err != nil {
+	db, err := applyUserFilters(r.db.WithContext(ctx).Model(&models.User{}), q)
+	if err != nil {
 		return nil, 0, err
 	}
+	if err := db.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, logQueryError(ctx, "users.count", err)
+	}
 
-	if err := r.db.Offset(offset).Limit(limit).Find(&users).Error; err != nil {
+	if db, err = applyUserSort(db, q); err != nil {
 		return nil, 0, err
 	}
+	if err := db.Offset(offset).Limit(limit).Find(&users).Error; err != nil {
+		return nil, 0, logQueryError(ctx, "users.list", err)
+	}
 	return users, total, nil
 }
 
-func (r *GormUserRepository) GetByID(c *gin.Context, id uint) (*models.User, error) {
+func (r *GormUserRepository) ListAfter(ctx context.Context, q repositories.UserQuery, after []any, limit int) (users []models.User, err error) {
+	ctx, end := startSpan(ctx, "GormUserRepository.ListAfter", "users", "SELECT")
+	defer end(&err)
+	db, err := applyUserFilters(r.db.WithContext(ctx), q)
+	if err != nil {
+		return nil, err
+	}
+	if after != nil {
+		cond, args, err := keysetCondition(userSortKeys(q), after)
+		if err != nil {
+			return nil, err
+		}
+		db = db.Where(cond, args...)
+	}
+	if db, err = applyUserSort(db, q); err != nil {
+		return nil, err
+	}
+	if err := db.Limit(limit).Find(&users).Error; err != nil {
+		return nil, logQueryError(ctx, "users.list_after", err)
+	}
+	return users, nil
+}
+
+func (r *GormUserRepository) Count(ctx context.Context, q repositories.UserQuery) (total int64, err error) {
+	ctx, end := startSpan(ctx, "GormUserRepository.Count", "users", "SELECT")
+	defer end(&err)
+	db, err := applyUserFilters(r.db.WithContext(ctx).Model(&models.User{}), q)
+	if err != nil {
+		return 0, err
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return 0, logQueryError(ctx, "users.count", err)
+	}
+	return total, nil
+}
+
+// EstimateCount asks the planner how many rows the filtered SELECT would
+// return instead of counting them.
+func (r *GormUserRepository) EstimateCount(ctx context.Context, q repositories.UserQuery) (total int64, err error) {
+	ctx, end := startSpan(ctx, "GormUserRepository.EstimateCount", "users", "SELECT")
+	defer end(&err)
+	db, err := applyUserFilters(r.db.WithContext(ctx).Session(&gorm.Session{DryRun: true}).Model(&models.User{}), q)
+	if err != nil {
+		return 0, err
+	}
+	stmt := db.Select("users.id").Find(&[]models.User{}).Statement
+
+	var plan []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	var raw string
+	if err := r.db.WithContext(ctx).Raw("EXPLAIN (FORMAT JSON) "+stmt.SQL.String(), stmt.Vars...).Scan(&raw).Error; err != nil {
+		return 0, logQueryError(ctx, "users.estimate_count", err)
+	}
+	if err := json.Unmarshal([]byte(raw), &plan); err != nil {
+		return 0, fmt.Errorf("parse query plan: %w", err)
+	}
+	if len(plan) == 0 {
+		return 0, errors.New("empty query plan")
+	}
+	return int64(plan[0].Plan.Rows), nil
+}
+
+func (r *GormUserRepository) GetByID(ctx context.Context, id uint) (_ *models.User, err error) {
+	ctx, end := startSpan(ctx, "GormUserRepository.GetByID", "users", "SELECT")
+	defer end(&err)
 	var user models.User
-	if err := r.db.First(&user, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).First(&user, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil // Or a custom domain error e.g. ErrUserNotFound
 		}
-		return nil, err
+		return nil, logQueryError(ctx, "users.get_by_id", err)
 	}
 	return &user, nil
 }
 
-func (r *GormUserRepository) GetByEmail(c *gin.Context, email string) (*models.User, error) {
+func (r *GormUserRepository) GetByEmail(ctx context.Context, email string) (_ *models.User, err error) {
+	ctx, end := startSpan(ctx, "GormUserRepository.GetByEmail", "users", "SELECT")
+	defer end(&err)
 	var user models.User
-	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil // Or a custom domain error e.g. ErrUserNotFound
 		}
-		return nil, err
+		return nil, logQueryError(ctx, "users.get_by_email", err)
+	}
+	return &user, nil
+}
+
+func (r *GormUserRepository) Create(ctx context.Context, user *models.User) (err error) {
+	ctx, end := startSpan(ctx, "GormUserRepository.Create", "users", "INSERT")
+	defer end(&err)
+	return logQueryError(ctx, "users.create", r.db.WithContext(ctx).Create(user).Error)
+}
+
+func (r *GormUserRepository) Update(ctx context.Context, user *models.User) (err error) {
+	ctx, end := startSpan(ctx, "GormUserRepository.Update", "users", "UPDATE")
+	defer end(&err)
+	// Compare-and-set on the version column instead of last-write-wins
+	next := *user
+	next.Version = user.Version + 1
+	result := r.db.WithContext(ctx).Model(&next).
+		Where("version = ?", user.Version).
+		Select("*").Omit("id", "created_at", "deleted_at", clause.Associations).
+		Updates(&next)
+	if result.Error != nil {
+		return logQueryError(ctx, "users.update", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repositories.ErrVersionConflict
+	}
+	*user = next
+	return nil
+}
+
+func (r *GormUserRepository) ReplacePasswordHash(ctx context.Context, id uint, old, hash string) (err error) {
+	ctx, end := startSpan(ctx, "GormUserRepository.ReplacePasswordHash", "users", "UPDATE")
+	defer end(&err)
+	// UpdateColumn leaves updated_at alone along with the version
+	err = r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND password_hash = ?", id, old).
+		UpdateColumn("password_hash", hash).Error
+	if err != nil {
+		return logQueryError(ctx, "users.replace_password_hash", err)
+	}
+	return nil
+}
+
+func (r *GormUserRepository) Delete(ctx context.Context, id uint, version int64) (err error) {
+	ctx, end := startSpan(ctx, "GormUserRepository.Delete", "users", "DELETE")
+	defer end(&err)
+	result := r.db.WithContext(ctx).Where("version = ?", version).Delete(&models.User{}, id)
+	if result.Error != nil {
+		return logQueryError(ctx, "users.delete", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repositories.ErrVersionConflict
+	}
+	return nil
+}
+
+func (r *GormUserRepository) GetDeletedByID(ctx context.Context, id uint) (_ *models.User, err error) {
+	ctx, end := startSpan(ctx, "GormUserRepository.GetDeletedByID", "users", "SELECT")
+	defer end(&err)
+	var user models.User
+	if err := r.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, logQueryError(ctx, "users.get_deleted_by_id", err)
 	}
 	return &user, nil
 }
 
-func (r *GormUserRepository) Create(c *gin.Context, user *models.User) error {
-	return r.db.Create(user).Error
+func (r *GormUserRepository) Restore(ctx context.Context, user *models.User) (err error) {
+	ctx, end := startSpan(ctx, "GormUserRepository.Restore", "users", "UPDATE")
+	defer end(&err)
+	result := r.db.WithContext(ctx).Unscoped().Model(&models.User{}).
+		Where("id = ? AND version = ? AND deleted_at IS NOT NULL", user.ID, user.Version).
+		Updates(map[string]any{"deleted_at": nil, "version": user.Version + 1})
+	if isUniqueViolation(result.Error) { // idx_users_email_live
+		return repositories.ErrEmailTaken
+	}
+	if result.Error != nil {
+		return logQueryError(ctx, "users.restore", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repositories.ErrVersionConflict
+	}
+	user.DeletedAt = gorm.DeletedAt{}
+	user.Version++
+	return nil
+}
+
+func (r *GormUserRepository) Purge(ctx context.Context, id uint) (err error) {
+	ctx, end := startSpan(ctx, "GormUserRepository.Purge", "users", "DELETE")
+	defer end(&err)
+	_, err = r.purge(ctx, 1, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", id)
+	})
+	return logQueryError(ctx, "users.purge", err)
+}
+
+func (r *GormUserRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time, limit int) (_ []models.User, err error) {
+	ctx, end := startSpan(ctx, "GormUserRepository.PurgeDeletedBefore", "users", "DELETE")
+	defer end(&err)
+	users, err := r.purge(ctx, limit, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("deleted_at < ?", cutoff).Order("deleted_at")
+	})
+	return users, logQueryError(ctx, "users.purge_deleted_before", err)
+}
+
+// purge hard-deletes up to limit soft-deleted users selected by scope,
+// together with their refresh tokens, in one transaction, and returns the
+// removed users. Rows locked by a concurrent purge are skipped; role
+// assignments go with the user through ON DELETE CASCADE.
+func (r *GormUserRepository) purge(ctx context.Context, limit int, scope func(*gorm.DB) *gorm.DB) ([]models.User, error) {
+	var users []models.User
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := scope(tx.Unscoped()).
+			Where("deleted_at IS NOT NULL").
+			Limit(limit).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Find(&users).Error; err != nil {
+			return err
+		}
+		if len(users) == 0 {
+			return nil
+		}
+		ids := make([]uint, len(users))
+		for i, u := range users {
+			ids[i] = u.ID
+		}
+		if err := tx.Where("user_id IN ?", ids).Delete(&models.RefreshToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.User{}, ids).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// CreateBatch inserts users with a single multi-row INSERT, which sets
+// their IDs and is atomic on its own.
+func (r *GormUserRepository) CreateBatch(ctx context.Context, users []models.User) (err error) {
+	ctx, end := startSpan(ctx, "GormUserRepository.CreateBatch", "users", "INSERT")
+	defer end(&err)
+	if len(users) == 0 {
+		return nil
+	}
+	err = r.db.WithContext(ctx).Create(&users).Error
+	return logQueryError(ctx, "users.create_batch", err)
+}
+
+func (r *GormUserRepository) ExistingEmails(ctx context.Context, emails []string) (existing []string, err error) {
+	ctx, end := startSpan(ctx, "GormUserRepository.ExistingEmails", "users", "SELECT")
+	defer end(&err)
+	if len(emails) == 0 {
+		return nil, nil
+	}
+	err = r.db.WithContext(ctx).Model(&models.User{}).Where("email IN ?", emails).Pluck("email", &existing).Error
+	return existing, logQueryError(ctx, "users.existing_emails", err)
+}
+
+func (r *GormUserRepository) Transaction(ctx context.Context, fn func(repo repositories.UserRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&GormUserRepository{db: tx})
+	})
+}
+
+func (r *GormUserRepository) Audit() repositories.AuditRepository {
+	return &GormAuditRepository{db: r.db}
+}
+
+func (r *GormUserRepository) Outbox() repositories.OutboxRepository {
+	return &GormOutboxRepository{db: r.db}
+}
+
+func (r *GormUserRepository) Jobs() jobs.Inserter {
+	return NewGormJobStore(r.db)
+}
+
+func (r *GormUserRepository) EmailVerifications() repositories.EmailVerificationRepository {
+	return &GormEmailVerificationRepository{db: r.db}
 }
 
-func (r *GormUserRepository) Update(c *gin.Context, user *models.User) error {
-	return r.db.Save(user).Error
+func (r *GormUserRepository) PasswordResets() repositories.PasswordResetRepository {
+	return &GormPasswordResetRepository{db: r.db}
 }
 
-func (r *GormUserRepository) Delete(c *gin.Context, id uint) error {
-	return r.db.Delete(&models.User{}, id).Error
+func (r *GormUserRepository) RefreshTokens() repositories.RefreshTokenRepository {
+	return &GormRefreshTokenRepository{db: r.db}
 }
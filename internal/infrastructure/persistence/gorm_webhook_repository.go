@@ -0,0 +1,219 @@
+package persistence
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// deliveryResultColumns are the columns a delivery attempt or a
+// redelivery changes.
+var deliveryResultColumns = []string{
+	"status", "attempts", "next_attempt_at", "last_attempt_at", "response_status", "last_error", "updated_at",
+}
+
+type GormWebhookRepository struct {
+	db *gorm.DB
+}
+
+func NewGormWebhookRepository(db *gorm.DB) repositories.WebhookRepository {
+	return &GormWebhookRepository{db: db}
+}
+
+func (r *GormWebhookRepository) CreateSubscription(ctx context.Context, sub *models.WebhookSubscription) (err error) {
+	ctx, end := startSpan(ctx, "GormWebhookRepository.CreateSubscription", "webhook_subscriptions", "INSERT")
+	defer end(&err)
+	return logQueryError(ctx, "webhook_subscriptions.create", r.db.WithContext(ctx).Create(sub).Error)
+}
+
+func (r *GormWebhookRepository) GetSubscription(ctx context.Context, id uint) (_ *models.WebhookSubscription, err error) {
+	ctx, end := startSpan(ctx, "GormWebhookRepository.GetSubscription", "webhook_subscriptions", "SELECT")
+	defer end(&err)
+	var sub models.WebhookSubscription
+	if err := r.db.WithContext(ctx).First(&sub, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, logQueryError(ctx, "webhook_subscriptions.get", err)
+	}
+	return &sub, nil
+}
+
+func (r *GormWebhookRepository) ListSubscriptions(ctx context.Context) (subs []models.WebhookSubscription, err error) {
+	ctx, end := startSpan(ctx, "GormWebhookRepository.ListSubscriptions", "webhook_subscriptions", "SELECT")
+	defer end(&err)
+	err = r.db.WithContext(ctx).Order("id").Find(&subs).Error
+	return subs, logQueryError(ctx, "webhook_subscriptions.list", err)
+}
+
+func (r *GormWebhookRepository) ListSubscribers(ctx context.Context, eventType string) (subs []models.WebhookSubscription, err error) {
+	ctx, end := startSpan(ctx, "GormWebhookRepository.ListSubscribers", "webhook_subscriptions", "SELECT")
+	defer end(&err)
+	// A JSON array contains a string if the string is one of its elements
+	err = r.db.WithContext(ctx).
+		Where("active AND (event_types @> to_jsonb(?::text) OR event_types @> to_jsonb(?::text))", eventType, models.WebhookAllEvents).
+		Order("id").
+		Find(&subs).Error
+	return subs, logQueryError(ctx, "webhook_subscriptions.list_subscribers", err)
+}
+
+func (r *GormWebhookRepository) UpdateSubscription(ctx context.Context, sub *models.WebhookSubscription) (err error) {
+	ctx, end := startSpan(ctx, "GormWebhookRepository.UpdateSubscription", "webhook_subscriptions", "UPDATE")
+	defer end(&err)
+	err = r.db.WithContext(ctx).Model(sub).
+		Select("url", "event_types", "description", "secret", "active", "updated_at").
+		Updates(sub).Error
+	return logQueryError(ctx, "webhook_subscriptions.update", err)
+}
+
+func (r *GormWebhookRepository) DeleteSubscription(ctx context.Context, id uint) (_ bool, err error) {
+	ctx, end := startSpan(ctx, "GormWebhookRepository.DeleteSubscription", "webhook_subscriptions", "DELETE")
+	defer end(&err)
+	result := r.db.WithContext(ctx).Delete(&models.WebhookSubscription{}, id)
+	return result.RowsAffected > 0, logQueryError(ctx, "webhook_subscriptions.delete", result.Error)
+}
+
+func (r *GormWebhookRepository) AddDeliveries(ctx context.Context, deliveries ...*models.WebhookDelivery) (err error) {
+	ctx, end := startSpan(ctx, "GormWebhookRepository.AddDeliveries", "webhook_deliveries", "INSERT")
+	defer end(&err)
+	if len(deliveries) == 0 {
+		return nil
+	}
+	err = r.db.WithContext(ctx).
+		Omit("Subscription").
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "subscription_id"}, {Name: "event_id"}},
+			DoNothing: true,
+		}).
+		Create(deliveries).Error
+	return logQueryError(ctx, "webhook_deliveries.add", err)
+}
+
+// claimDeliveriesSQL leases due deliveries of active subscriptions in one
+// statement by moving their next_attempt_at past the lease. Rows locked by
+// a concurrent claim are skipped rather than waited for.
+const claimDeliveriesSQL = `
+UPDATE webhook_deliveries SET next_attempt_at = now() + make_interval(secs => ?)
+WHERE id IN (
+    SELECT d.id FROM webhook_deliveries d
+    JOIN webhook_subscriptions s ON s.id = d.subscription_id AND s.active
+    WHERE d.status = ? AND d.next_attempt_at <= now()
+    ORDER BY d.id
+    LIMIT ?
+    FOR UPDATE OF d SKIP LOCKED
+)
+RETURNING *`
+
+// ClaimDueDeliveries commits the claim before calling fn, so no
+// transaction or row lock is held while fn waits on slow receivers.
+func (r *GormWebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration, fn func(deliveries []models.WebhookDelivery) error) (claimed int, err error) {
+	ctx, end := startSpan(ctx, "GormWebhookRepository.ClaimDueDeliveries", "webhook_deliveries", "UPDATE")
+	defer end(&err)
+	var deliveries []models.WebhookDelivery
+	if err := r.db.WithContext(ctx).Raw(claimDeliveriesSQL, lease.Seconds(), models.WebhookDeliveryPending, limit).Scan(&deliveries).Error; err != nil {
+		return 0, logQueryError(ctx, "webhook_deliveries.claim_due", err)
+	}
+	if len(deliveries) == 0 {
+		return 0, nil
+	}
+	// RETURNING does not preserve the subquery's order
+	slices.SortFunc(deliveries, func(a, b models.WebhookDelivery) int { return cmp.Compare(a.ID, b.ID) })
+	if err := r.loadSubscriptions(ctx, deliveries); err != nil {
+		return 0, logQueryError(ctx, "webhook_deliveries.claim_due", err)
+	}
+	deliveries = slices.DeleteFunc(deliveries, func(d models.WebhookDelivery) bool { return d.Subscription == nil })
+	leases := make([]time.Time, len(deliveries))
+	for i := range deliveries {
+		leases[i] = deliveries[i].NextAttemptAt
+	}
+
+	if err := fn(deliveries); err != nil {
+		return 0, err
+	}
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range deliveries {
+			// A delivery whose lease ran out may have been claimed again;
+			// its new holder's result wins
+			if err := tx.Model(&deliveries[i]).
+				Where("status = ? AND next_attempt_at = ?", models.WebhookDeliveryPending, leases[i]).
+				Select(deliveryResultColumns).
+				Updates(&deliveries[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, logQueryError(ctx, "webhook_deliveries.save_claimed", err)
+	}
+	return len(deliveries), nil
+}
+
+// loadSubscriptions sets the Subscription of each delivery.
+func (r *GormWebhookRepository) loadSubscriptions(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	ids := make([]uint, len(deliveries))
+	for i, d := range deliveries {
+		ids[i] = d.SubscriptionID
+	}
+	var subs []models.WebhookSubscription
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&subs).Error; err != nil {
+		return err
+	}
+	for i := range deliveries {
+		// Deliveries of a subscription deleted since the claim are gone too
+		if j := slices.IndexFunc(subs, func(s models.WebhookSubscription) bool { return s.ID == deliveries[i].SubscriptionID }); j >= 0 {
+			deliveries[i].Subscription = &subs[j]
+		}
+	}
+	return nil
+}
+
+func (r *GormWebhookRepository) GetDelivery(ctx context.Context, subscriptionID, id uint) (_ *models.WebhookDelivery, err error) {
+	ctx, end := startSpan(ctx, "GormWebhookRepository.GetDelivery", "webhook_deliveries", "SELECT")
+	defer end(&err)
+	var delivery models.WebhookDelivery
+	if err := r.db.WithContext(ctx).Where("subscription_id = ?", subscriptionID).First(&delivery, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, logQueryError(ctx, "webhook_deliveries.get", err)
+	}
+	return &delivery, nil
+}
+
+func (r *GormWebhookRepository) ListDeliveries(ctx context.Context, subscriptionID uint, status string, before uint, limit int) (deliveries []models.WebhookDelivery, err error) {
+	ctx, end := startSpan(ctx, "GormWebhookRepository.ListDeliveries", "webhook_deliveries", "SELECT")
+	defer end(&err)
+	db := r.db.WithContext(ctx).Where("subscription_id = ?", subscriptionID)
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	if before > 0 {
+		db = db.Where("id < ?", before)
+	}
+	err = db.Order("id DESC").Limit(limit).Find(&deliveries).Error
+	return deliveries, logQueryError(ctx, "webhook_deliveries.list", err)
+}
+
+func (r *GormWebhookRepository) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) (err error) {
+	ctx, end := startSpan(ctx, "GormWebhookRepository.UpdateDelivery", "webhook_deliveries", "UPDATE")
+	defer end(&err)
+	err = r.db.WithContext(ctx).Model(delivery).Select(deliveryResultColumns).Updates(delivery).Error
+	return logQueryError(ctx, "webhook_deliveries.update", err)
+}
+
+func (r *GormWebhookRepository) DeleteFinishedBefore(ctx context.Context, cutoff time.Time) (_ int64, err error) {
+	ctx, end := startSpan(ctx, "GormWebhookRepository.DeleteFinishedBefore", "webhook_deliveries", "DELETE")
+	defer end(&err)
+	result := r.db.WithContext(ctx).
+		Where("status <> ? AND updated_at < ?", models.WebhookDeliveryPending, cutoff).
+		Delete(&models.WebhookDelivery{})
+	return result.RowsAffected, logQueryError(ctx, "webhook_deliveries.delete_finished_before", result.Error)
+}
@@ -0,0 +1,21 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/logger"
+	"gorm.io/gorm"
+)
+
+// logQueryError records unexpected database errors with the request-scoped
+// logger, so they carry the request ID, and returns err unchanged.
+func logQueryError(ctx context.Context, operation string, err error) error {
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.FromContext(ctx).Errorw("Database operation failed",
+			"operation", operation,
+			"error", err,
+		)
+	}
+	return err
+}
@@ -0,0 +1,22 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/tracing"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startSpan opens a client span for a repository query, tagged with the
+// database semantic-convention attributes.
+func startSpan(ctx context.Context, name, table, operation string) (context.Context, func(errp *error)) {
+	return tracing.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBCollectionName(table),
+			semconv.DBOperationName(operation),
+		),
+	)
+}
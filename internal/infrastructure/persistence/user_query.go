@@ -0,0 +1,131 @@
+package persistence
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// userColumns is the whitelist of columns a UserQuery may reference. Field
+// names never reach SQL directly.
+var userColumns = map[repositories.UserField]string{
+	repositories.UserFieldID:        "users.id",
+	repositories.UserFieldName:      "users.name",
+	repositories.UserFieldEmail:     "users.email",
+	repositories.UserFieldCreatedAt: "users.created_at",
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func userColumn(field repositories.UserField) (string, error) {
+	col, ok := userColumns[field]
+	if !ok {
+		return "", fmt.Errorf("unsupported user field %q", field)
+	}
+	return col, nil
+}
+
+// applyUserFilters adds the WHERE clauses of q to db.
+func applyUserFilters(db *gorm.DB, q repositories.UserQuery) (*gorm.DB, error) {
+	switch q.Deleted {
+	case repositories.DeletedInclude:
+		db = db.Unscoped()
+	case repositories.DeletedOnly:
+		db = db.Unscoped().Where("users.deleted_at IS NOT NULL")
+	}
+
+	for _, f := range q.Filters {
+		col, err := userColumn(f.Field)
+		if err != nil {
+			return nil, err
+		}
+		if len(f.Values) == 0 || (f.Op != repositories.OpIn && len(f.Values) != 1) {
+			return nil, fmt.Errorf("wrong number of values for %s %s", f.Field, f.Op)
+		}
+		switch f.Op {
+		case repositories.OpEq:
+			db = db.Where(col+" = ?", f.Values[0])
+		case repositories.OpPrefix:
+			prefix, ok := f.Values[0].(string)
+			if !ok {
+				return nil, fmt.Errorf("prefix on %s needs a string", f.Field)
+			}
+			db = db.Where(col+` LIKE ? ESCAPE '\'`, likeEscaper.Replace(prefix)+"%")
+		case repositories.OpIn:
+			db = db.Where(col+" IN ?", f.Values)
+		case repositories.OpGt:
+			db = db.Where(col+" > ?", f.Values[0])
+		case repositories.OpGte:
+			db = db.Where(col+" >= ?", f.Values[0])
+		case repositories.OpLt:
+			db = db.Where(col+" < ?", f.Values[0])
+		case repositories.OpLte:
+			db = db.Where(col+" <= ?", f.Values[0])
+		default:
+			return nil, fmt.Errorf("unsupported filter operator %q", f.Op)
+		}
+	}
+
+	if q.Search != "" {
+		pattern := "%" + likeEscaper.Replace(q.Search) + "%"
+		db = db.Where(`(users.name ILIKE ? ESCAPE '\' OR users.email ILIKE ? ESCAPE '\')`, pattern, pattern)
+	}
+	return db, nil
+}
+
+// userSortKeys returns the sort of q, defaulting to ID order.
+func userSortKeys(q repositories.UserQuery) []repositories.SortKey {
+	if len(q.Sort) == 0 {
+		return []repositories.SortKey{{Field: repositories.UserFieldID}}
+	}
+	return q.Sort
+}
+
+// applyUserSort adds ORDER BY for the sort of q.
+func applyUserSort(db *gorm.DB, q repositories.UserQuery) (*gorm.DB, error) {
+	for _, key := range userSortKeys(q) {
+		col, err := userColumn(key.Field)
+		if err != nil {
+			return nil, err
+		}
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: col, Raw: true}, Desc: key.Desc})
+	}
+	return db, nil
+}
+
+// keysetCondition returns the WHERE clause selecting rows that sort strictly
+// after the position after under the given sort keys:
+//
+//	(k1 > v1) OR (k1 = v1 AND k2 > v2) OR ...
+//
+// with < in place of > for descending keys.
+func keysetCondition(sort []repositories.SortKey, after []any) (string, []any, error) {
+	if len(after) != len(sort) {
+		return "", nil, fmt.Errorf("keyset has %d values for %d sort keys", len(after), len(sort))
+	}
+	var disjuncts []string
+	var args []any
+	for i, key := range sort {
+		var conj []string
+		for j := 0; j < i; j++ {
+			col, _ := userColumn(sort[j].Field)
+			conj = append(conj, col+" = ?")
+			args = append(args, after[j])
+		}
+		col, err := userColumn(key.Field)
+		if err != nil {
+			return "", nil, err
+		}
+		op := " > ?"
+		if key.Desc {
+			op = " < ?"
+		}
+		conj = append(conj, col+op)
+		args = append(args, after[i])
+		disjuncts = append(disjuncts, "("+strings.Join(conj, " AND ")+")")
+	}
+	return "(" + strings.Join(disjuncts, " OR ") + ")", args, nil
+}
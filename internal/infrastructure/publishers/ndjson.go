@@ -0,0 +1,46 @@
+// Package publishers holds events.Publisher implementations that deliver
+// outbox events outside the process.
+package publishers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/events"
+)
+
+// NDJSONPublisher writes each event as one JSON line. It is meant for
+// local development, audits of what was published and offline tests.
+type NDJSONPublisher struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewNDJSONPublisher(w io.Writer) *NDJSONPublisher {
+	return &NDJSONPublisher{w: w}
+}
+
+// OpenNDJSONFile appends events to the file at path, creating it if needed.
+// Lines are written with a single write call each, so concurrent appends
+// from several processes do not interleave.
+func OpenNDJSONFile(path string) (*NDJSONPublisher, *os.File, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewNDJSONPublisher(f), f, nil
+}
+
+func (p *NDJSONPublisher) Publish(ctx context.Context, env events.Envelope) error {
+	line, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err = p.w.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,52 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often stores drop buckets that have fully refilled,
+// which are indistinguishable from absent ones.
+const sweepInterval = time.Minute
+
+// MemoryStore keeps buckets in process memory. Limits are per replica.
+type MemoryStore struct {
+	// Now returns the current time; tests may replace it
+	Now func() time.Time
+
+	mu        sync.Mutex
+	tats      map[string]time.Time
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{Now: time.Now, tats: map[string]time.Time{}}
+}
+
+func (s *MemoryStore) Allow(_ context.Context, key string, p Policy) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.Now()
+	s.sweep(now)
+
+	tat, allowed := next(p, s.tats[key], now)
+	if allowed {
+		s.tats[key] = tat
+	} else {
+		tat = s.tats[key]
+	}
+	return result(p, tat, now, allowed), nil
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, tat := range s.tats {
+		if tat.Before(now) {
+			delete(s.tats, key)
+		}
+	}
+}
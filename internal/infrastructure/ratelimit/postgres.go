@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PostgresStore keeps buckets in the rate_limit_buckets table so limits
+// hold across replicas. Each check is a single conditional upsert that
+// uses the database clock, so replica clock skew does not matter.
+type PostgresStore struct {
+	db *gorm.DB
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+type bucketRow struct {
+	TAT time.Time
+	Now time.Time
+}
+
+// The upsert only writes when the request fits in the burst, so a row is
+// returned exactly when the request is allowed.
+const allowSQL = `
+INSERT INTO rate_limit_buckets AS b (key, tat)
+VALUES (@key, now() + make_interval(secs => @interval))
+ON CONFLICT (key) DO UPDATE
+SET tat = GREATEST(b.tat, now()) + make_interval(secs => @interval)
+WHERE GREATEST(b.tat, now()) + make_interval(secs => @interval) <= now() + make_interval(secs => @window)
+RETURNING tat, now() AS now`
+
+func (s *PostgresStore) Allow(ctx context.Context, key string, p Policy) (Result, error) {
+	s.sweep(ctx)
+
+	interval := p.interval()
+	var rows []bucketRow
+	err := s.db.WithContext(ctx).Raw(allowSQL, map[string]any{
+		"key":      key,
+		"interval": interval.Seconds(),
+		"window":   (time.Duration(p.burst()) * interval).Seconds(),
+	}).Scan(&rows).Error
+	if err != nil {
+		return Result{}, err
+	}
+	if len(rows) == 1 {
+		return result(p, rows[0].TAT, rows[0].Now, true), nil
+	}
+
+	var row bucketRow
+	err = s.db.WithContext(ctx).Raw(`SELECT tat, now() AS now FROM rate_limit_buckets WHERE key = ?`, key).Scan(&row).Error
+	if err != nil {
+		return Result{}, err
+	}
+	if row.TAT.IsZero() {
+		return Result{}, errors.New("rate limit bucket disappeared while denying a request")
+	}
+	return result(p, row.TAT, row.Now, false), nil
+}
+
+// sweep deletes fully refilled buckets at most once per sweepInterval per
+// replica. It runs inline because it is cheap with the tat index and
+// failures only delay cleanup.
+func (s *PostgresStore) sweep(ctx context.Context) {
+	s.mu.Lock()
+	if time.Since(s.lastSweep) < sweepInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastSweep = time.Now()
+	s.mu.Unlock()
+	s.db.WithContext(ctx).Exec(`DELETE FROM rate_limit_buckets WHERE tat < now()`)
+}
@@ -0,0 +1,114 @@
+// Package ratelimit implements token-bucket rate limiting over pluggable
+// storage. Buckets are tracked with the generic cell rate algorithm (GCRA):
+// each key stores a single "theoretical arrival time", which makes a check
+// one atomic read-modify-write in any backend.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy allows Limit requests per Period, refilled continuously, with
+// bursts of up to Burst requests.
+type Policy struct {
+	Limit  int
+	Period time.Duration
+	Burst  int
+}
+
+// ParsePolicy parses "<limit>/<period>" such as "10/1m", with an optional
+// ",burst=<n>" suffix. Burst defaults to the limit. An empty string or "0"
+// means no limit and yields the zero Policy.
+func ParsePolicy(s string) (Policy, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "0" {
+		return Policy{}, nil
+	}
+	spec, burstSpec, hasBurst := strings.Cut(s, ",")
+	limitStr, periodStr, ok := strings.Cut(spec, "/")
+	if !ok {
+		return Policy{}, fmt.Errorf("rate limit %q: want <limit>/<period>, e.g. 10/1m", s)
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(limitStr))
+	if err != nil || limit < 1 {
+		return Policy{}, fmt.Errorf("rate limit %q: limit must be a positive integer", s)
+	}
+	period, err := time.ParseDuration(strings.TrimSpace(periodStr))
+	if err != nil || period <= 0 {
+		return Policy{}, fmt.Errorf("rate limit %q: period must be a positive duration", s)
+	}
+	p := Policy{Limit: limit, Period: period, Burst: limit}
+	if hasBurst {
+		n, found := strings.CutPrefix(strings.TrimSpace(burstSpec), "burst=")
+		burst, err := strconv.Atoi(n)
+		if !found || err != nil || burst < 1 {
+			return Policy{}, fmt.Errorf("rate limit %q: burst must be burst=<positive integer>", s)
+		}
+		p.Burst = burst
+	}
+	return p, nil
+}
+
+// Enabled reports whether the policy limits anything.
+func (p Policy) Enabled() bool {
+	return p.Limit > 0 && p.Period > 0
+}
+
+// interval is the time it takes to refill one token.
+func (p Policy) interval() time.Duration {
+	return p.Period / time.Duration(p.Limit)
+}
+
+func (p Policy) burst() int {
+	if p.Burst < 1 {
+		return p.Limit
+	}
+	return p.Burst
+}
+
+// Result describes the state of a bucket after a request was counted.
+type Result struct {
+	Allowed bool
+	// Limit is the bucket capacity
+	Limit int
+	// Remaining is how many more requests would be allowed right now
+	Remaining int
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is how long until the next request would be allowed; zero when allowed
+	RetryAfter time.Duration
+}
+
+// Store counts a request against the bucket identified by key.
+type Store interface {
+	Allow(ctx context.Context, key string, p Policy) (Result, error)
+}
+
+// next applies one request to a bucket whose theoretical arrival time is
+// tat, returning the new tat and whether the request fits in the burst.
+func next(p Policy, tat, now time.Time) (time.Time, bool) {
+	if tat.Before(now) {
+		tat = now
+	}
+	newTAT := tat.Add(p.interval())
+	return newTAT, !newTAT.After(now.Add(time.Duration(p.burst()) * p.interval()))
+}
+
+// result derives the client-facing bucket state from its tat.
+func result(p Policy, tat, now time.Time, allowed bool) Result {
+	interval := p.interval()
+	burstWindow := time.Duration(p.burst()) * interval
+	res := Result{Allowed: allowed, Limit: p.burst()}
+	if reset := tat.Sub(now); reset > 0 {
+		res.Reset = reset
+	}
+	res.Remaining = int((burstWindow - res.Reset) / interval)
+	if !allowed {
+		res.RetryAfter = tat.Add(interval).Sub(now.Add(burstWindow))
+	}
+	return res
+}
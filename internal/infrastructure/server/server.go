@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/config"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/logger"
+)
+
+type shutdownHook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Server wraps http.Server with a signal-friendly lifecycle: it serves until
+// its context is cancelled, drains in-flight requests within the shutdown
+// timeout, and then closes registered dependencies in reverse order.
+type Server struct {
+	httpServer      *http.Server
+	shutdownTimeout time.Duration
+	hooks           []shutdownHook
+	log             *logger.Logger
+}
+
+func New(cfg *config.Config, handler http.Handler, log *logger.Logger) *Server {
+	return &Server{
+		httpServer: &http.Server{
+			Addr:              ":" + cfg.Port,
+			Handler:           handler,
+			ReadTimeout:       cfg.ServerReadTimeout,
+			ReadHeaderTimeout: cfg.ServerReadHeaderTimeout,
+			WriteTimeout:      cfg.ServerWriteTimeout,
+			IdleTimeout:       cfg.ServerIdleTimeout,
+		},
+		shutdownTimeout: cfg.ShutdownTimeout,
+		log:             log,
+	}
+}
+
+// OnShutdown registers a dependency to close once the HTTP server has
+// drained. Hooks run in reverse registration order, so register
+// dependencies in the order they were opened.
+func (s *Server) OnShutdown(name string, fn func(ctx context.Context) error) {
+	s.hooks = append(s.hooks, shutdownHook{name: name, fn: fn})
+}
+
+// Run listens on the configured address and blocks until ctx is cancelled
+// and shutdown completes, or the listener fails.
+func (s *Server) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve is like Run but accepts connections on an existing listener.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		s.log.Info("Starting server on " + ln.Addr().String())
+		serveErr <- s.httpServer.Serve(ln)
+	}()
+
+	select {
+	case err := <-serveErr:
+		// The listener failed before we were asked to stop
+		return errors.Join(err, s.closeDependencies(context.Background()))
+	case <-ctx.Done():
+	}
+
+	s.log.Infow("Shutting down server", "drain_timeout", s.shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	var shutdownErr error
+	if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
+		shutdownErr = fmt.Errorf("failed to drain connections: %w", err)
+		_ = s.httpServer.Close()
+	}
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		shutdownErr = errors.Join(shutdownErr, err)
+	}
+
+	return errors.Join(shutdownErr, s.closeDependencies(shutdownCtx))
+}
+
+func (s *Server) closeDependencies(ctx context.Context) error {
+	var errs []error
+	for i := len(s.hooks) - 1; i >= 0; i-- {
+		hook := s.hooks[i]
+		if err := hook.fn(ctx); err != nil {
+			s.log.Errorw("Failed to close dependency", "dependency", hook.name, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", hook.name, err))
+			continue
+		}
+		s.log.Infow("Closed dependency", "dependency", hook.name)
+	}
+	return errors.Join(errs...)
+}
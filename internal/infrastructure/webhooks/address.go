@@ -0,0 +1,127 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned for webhook URLs that are or resolve to
+// an address that is not publicly routable.
+var ErrForbiddenAddress = errors.New("webhook address is not publicly routable")
+
+// lookupTimeout bounds the DNS lookup of CheckURL
+const lookupTimeout = 2 * time.Second
+
+// nonPublic are the special-purpose ranges that netip.Addr's predicates do
+// not cover.
+var nonPublic = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"), // NAT64 can reach any IPv4 address
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+// AddressPolicy decides which addresses webhooks may be sent to: public
+// ones, plus the networks it was created with. Without it a subscription
+// could point at loopback, the private network or a cloud metadata
+// endpoint, and the delivery log would report what answered.
+type AddressPolicy struct {
+	allowed []netip.Prefix
+}
+
+// NewAddressPolicy returns a policy that also permits the given networks,
+// written as CIDR prefixes or single IP addresses. Allow only what local
+// development and tests need, such as 127.0.0.1.
+func NewAddressPolicy(allowed []string) (*AddressPolicy, error) {
+	p := &AddressPolicy{}
+	for _, s := range allowed {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(s)
+			if addrErr != nil {
+				return nil, fmt.Errorf("invalid webhook network %q", s)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		p.allowed = append(p.allowed, prefix.Masked())
+	}
+	return p, nil
+}
+
+// Permits reports whether webhooks may be sent to addr.
+func (p *AddressPolicy) Permits(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range p.allowed {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublic {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL rejects a URL whose host is, or resolves to, an address the
+// policy does not permit. A host that cannot be resolved now passes; the
+// Sender checks the address again when it connects.
+func (p *AddressPolicy) CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := u.Hostname()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !p.Permits(addr) {
+			return ErrForbiddenAddress
+		}
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if !p.Permits(addr) {
+			return ErrForbiddenAddress
+		}
+	}
+	return nil
+}
+
+// control is a net.Dialer Control function that refuses connections to
+// addresses the policy does not permit. It runs after DNS resolution, so
+// a name rebound to an internal address since CheckURL is still refused.
+func (p *AddressPolicy) control(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !p.Permits(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+// Package webhooks sends signed webhook requests and verifies them on the
+// receiving end.
+//
+// Every request carries the event ID, a Unix timestamp and an HMAC-SHA256
+// signature of "<timestamp>.<body>" keyed with the subscription's secret:
+//
+//	Webhook-Id: 0190f0c2-...
+//	Webhook-Timestamp: 1714564800
+//	Webhook-Signature: v1=5257a869...
+//
+// Receivers recompute the signature and reject requests whose timestamp is
+// too far from their clock, which stops replays of captured requests. The
+// ID is stable across retries and redeliveries, so receivers can drop
+// duplicates.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+)
+
+const (
+	HeaderID        = "Webhook-Id"
+	HeaderEvent     = "Webhook-Event"
+	HeaderTimestamp = "Webhook-Timestamp"
+	HeaderSignature = "Webhook-Signature"
+
+	signatureVersion = "v1"
+	secretPrefix     = "whsec_"
+	userAgent        = "lean-backend-boilerplate-webhooks"
+	// maxResponseBody bounds how much of a receiver's response is read
+	maxResponseBody = 64 << 10
+)
+
+var (
+	ErrMissingHeaders   = errors.New("webhook headers missing")
+	ErrInvalidSignature = errors.New("webhook signature does not match")
+	ErrStaleTimestamp   = errors.New("webhook timestamp outside tolerance")
+)
+
+// NewSecret returns a random signing secret.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return secretPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Sign returns the Webhook-Signature value for body sent at timestamp.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	return signatureVersion + "=" + hex.EncodeToString(mac(secret, timestamp.Unix(), body))
+}
+
+func mac(secret string, timestamp int64, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+// Verify checks the signature headers of a received webhook against body.
+// The header may list several space-separated signatures; one match is
+// enough. Requests signed more than tolerance before or after now fail
+// with ErrStaleTimestamp.
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration, now time.Time) error {
+	ts, sigs := header.Get(HeaderTimestamp), header.Get(HeaderSignature)
+	if ts == "" || sigs == "" {
+		return ErrMissingHeaders
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	if d := now.Sub(time.Unix(unix, 0)); d > tolerance || d < -tolerance {
+		return ErrStaleTimestamp
+	}
+	expected := mac(secret, unix, body)
+	for _, sig := range strings.Fields(sigs) {
+		version, value, ok := strings.Cut(sig, "=")
+		if !ok || version != signatureVersion {
+			continue
+		}
+		if got, err := hex.DecodeString(value); err == nil && hmac.Equal(got, expected) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// Sender POSTs deliveries to their subscription's URL. Redirects are not
+// followed: a receiver that moved must update its subscription.
+type Sender struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewSender returns a Sender whose requests time out after timeout and
+// that connects only to addresses the policy permits. Proxies from the
+// environment are ignored, since the policy could not check the target.
+func NewSender(timeout time.Duration, addresses *AddressPolicy) *Sender {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   addresses.control,
+	}).DialContext
+	return &Sender{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		now: time.Now,
+	}
+}
+
+// Send delivers d and returns the response status. Any status other than
+// 2xx is reported as an error along with the status.
+func (s *Sender) Send(ctx context.Context, d *models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.Subscription.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderID, d.EventID)
+	req.Header.Set(HeaderEvent, d.EventType)
+	now := s.now()
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(d.Subscription.Secret, now, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
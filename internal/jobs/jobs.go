@@ -0,0 +1,172 @@
+// Package jobs is a Postgres-backed background job queue.
+//
+// A job is a row in the jobs table naming a kind and carrying JSON
+// arguments. Services enqueue jobs with Enqueue, usually through the
+// Inserter of their repository transaction so that a job exists only if
+// the change that asked for it commits. Workers (see package runner) claim
+// due jobs with FOR UPDATE SKIP LOCKED, run the handler registered for the
+// kind and retry failures with exponential backoff.
+//
+// A claimed job is leased to its worker for a while, and the worker keeps
+// extending the lease while the handler runs. If the worker dies, the lease
+// runs out and another worker picks the job up again. Handlers must
+// therefore be idempotent: a job may run more than once.
+//
+// This package only defines jobs and their Store, and imports nothing
+// beyond the standard library, so the domain can depend on it. The
+// Postgres Store is persistence.GormJobStore.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Job states. A job waits as available until its RunAt, is running while
+// leased to a worker, and ends up succeeded or, once it runs out of
+// attempts or fails permanently, failed.
+const (
+	StateAvailable = "available"
+	StateRunning   = "running"
+	StateSucceeded = "succeeded"
+	StateFailed    = "failed"
+)
+
+const (
+	DefaultQueue       = "default"
+	DefaultMaxAttempts = 10
+)
+
+// ErrLeaseLost is returned when a worker finishes or extends a job whose
+// lease it no longer holds, because the lease ran out and another worker
+// claimed the job.
+var ErrLeaseLost = errors.New("job lease lost")
+
+type Job struct {
+	ID          uint            `json:"id" gorm:"primarykey"`
+	Queue       string          `json:"queue"`
+	Kind        string          `json:"kind"`
+	Args        json.RawMessage `json:"args" gorm:"type:jsonb;serializer:json"`
+	State       string          `json:"state"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	RunAt       time.Time       `json:"run_at"`
+	// LockedBy and LockedUntil identify the worker holding a running job's
+	// lease and when it expires
+	LockedBy    string     `json:"locked_by,omitempty"`
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+	UniqueKey   *string    `json:"unique_key,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+func (Job) TableName() string { return "jobs" }
+
+// Args are the arguments of a job kind. They are stored as JSON, so only
+// exported fields survive the trip to the worker.
+type Args interface {
+	// Kind names the job type; handlers are registered by it
+	Kind() string
+}
+
+// Options control how a job is enqueued. The zero value runs the job on
+// the default queue as soon as possible.
+type Options struct {
+	Queue string
+	// RunAt schedules the job; it is ignored if Delay is set
+	RunAt time.Time
+	// Delay schedules the job this long after it is enqueued
+	Delay       time.Duration
+	MaxAttempts int
+	// UniqueKey, if set, skips the job while another job with the same
+	// key is waiting or running
+	UniqueKey string
+}
+
+// Inserter stores new jobs. Insert reports false if the job was skipped as
+// a duplicate of a unique job.
+type Inserter interface {
+	Insert(ctx context.Context, job *Job) (bool, error)
+}
+
+// Store is the queue as workers see it. The methods that finish or extend
+// a job fail with ErrLeaseLost unless the claim that returned job still
+// holds its lease: the job is running, locked by job.LockedBy and at
+// job.Attempts. A worker that claims the same job again after its lease ran
+// out therefore cannot finish it through the older claim.
+type Store interface {
+	Inserter
+	// Claim leases up to limit due jobs from queues to worker for lease,
+	// oldest first. Due jobs are available ones whose RunAt has passed and
+	// running ones whose lease has expired. Claiming counts as an attempt.
+	Claim(ctx context.Context, worker string, queues []string, limit int, lease time.Duration) ([]Job, error)
+	// Extend renews the lease on a running job.
+	Extend(ctx context.Context, job *Job, lease time.Duration) error
+	Complete(ctx context.Context, job *Job) error
+	// Retry makes a job available again at runAt.
+	Retry(ctx context.Context, job *Job, runAt time.Time, reason string) error
+	// Fail gives up on a job.
+	Fail(ctx context.Context, job *Job, reason string) error
+	// Depth counts available, running and failed jobs per queue and state.
+	Depth(ctx context.Context) ([]QueueDepth, error)
+	// DeleteFinishedBefore removes succeeded and failed jobs finished before cutoff.
+	DeleteFinishedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// QueueDepth is the number of jobs in one queue and state. OldestDue is the
+// earliest RunAt among them that has passed, if any.
+type QueueDepth struct {
+	Queue     string
+	State     string
+	Count     int64
+	OldestDue *time.Time
+}
+
+// Enqueue stores a job with args. It returns nil without error if the job
+// was skipped because of its UniqueKey.
+func Enqueue(ctx context.Context, ins Inserter, args Args, opts Options) (*Job, error) {
+	job, err := NewJob(args, opts)
+	if err != nil {
+		return nil, err
+	}
+	inserted, err := ins.Insert(ctx, job)
+	if err != nil || !inserted {
+		return nil, err
+	}
+	return job, nil
+}
+
+// NewJob builds the job Enqueue would insert, with defaults applied.
+func NewJob(args Args, opts Options) (*Job, error) {
+	body, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+	job := &Job{
+		Queue:       opts.Queue,
+		Kind:        args.Kind(),
+		Args:        body,
+		State:       StateAvailable,
+		MaxAttempts: opts.MaxAttempts,
+		RunAt:       opts.RunAt,
+	}
+	if job.Queue == "" {
+		job.Queue = DefaultQueue
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.Delay > 0 {
+		job.RunAt = time.Now().Add(opts.Delay)
+	} else if job.RunAt.IsZero() {
+		job.RunAt = time.Now()
+	}
+	if opts.UniqueKey != "" {
+		job.UniqueKey = &opts.UniqueKey
+	}
+	return job, nil
+}
@@ -0,0 +1,71 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Handler runs one attempt of a job.
+type Handler func(ctx context.Context, job *Job) error
+
+// Registry maps job kinds to their handlers.
+type Registry struct {
+	handlers map[string]Handler
+}
+
+func NewRegistry() *Registry {
+	return &Registry{handlers: map[string]Handler{}}
+}
+
+// Register adds the handler for the kind of T. The job's arguments are
+// decoded into a T before fn is called; arguments that cannot be decoded
+// fail the job permanently. Registering a kind twice panics.
+func Register[T Args](r *Registry, fn func(ctx context.Context, job *Job, args T) error) {
+	var zero T
+	kind := zero.Kind()
+	if _, ok := r.handlers[kind]; ok {
+		panic("jobs: handler for " + kind + " registered twice")
+	}
+	r.handlers[kind] = func(ctx context.Context, job *Job) error {
+		var args T
+		if err := json.Unmarshal(job.Args, &args); err != nil {
+			return Permanent(fmt.Errorf("decode %s arguments: %w", kind, err))
+		}
+		return fn(ctx, job, args)
+	}
+}
+
+// Handler returns the handler for kind, if any.
+func (r *Registry) Handler(kind string) (Handler, bool) {
+	h, ok := r.handlers[kind]
+	return h, ok
+}
+
+// Kinds lists the registered kinds in sorted order.
+func (r *Registry) Kinds() []string {
+	kinds := make([]string, 0, len(r.handlers))
+	for kind := range r.handlers {
+		kinds = append(kinds, kind)
+	}
+	slices.Sort(kinds)
+	return kinds
+}
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying; the job fails right away.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent.
+func IsPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p)
+}
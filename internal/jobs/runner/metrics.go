@@ -0,0 +1,66 @@
+package runner
+
+import (
+	"context"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/metrics"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/jobs"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DepthCollector reports queue depths from a Store at scrape time. Register
+// it with metrics.Metrics.Register.
+type DepthCollector struct {
+	store     jobs.Store
+	timeout   time.Duration
+	depth     *prometheus.Desc
+	oldestDue *prometheus.Desc
+	errors    prometheus.Counter
+}
+
+// NewDepthCollector returns a collector that queries store for at most
+// timeout per scrape.
+func NewDepthCollector(store jobs.Store, timeout time.Duration) *DepthCollector {
+	return &DepthCollector{
+		store:   store,
+		timeout: timeout,
+		depth: prometheus.NewDesc(prometheus.BuildFQName(metrics.Namespace, "jobs", "queue_depth"),
+			"Jobs by queue and state (available, running or failed).",
+			[]string{"queue", "state"}, nil),
+		oldestDue: prometheus.NewDesc(prometheus.BuildFQName(metrics.Namespace, "jobs", "oldest_due_age_seconds"),
+			"How long the oldest due job in a queue has been waiting to run.",
+			[]string{"queue"}, nil),
+		errors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "jobs",
+			Name:      "queue_depth_errors_total",
+			Help:      "Scrapes whose queue depth query failed.",
+		}),
+	}
+}
+
+func (c *DepthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.depth
+	ch <- c.oldestDue
+	c.errors.Describe(ch)
+}
+
+// Collect queries the store. A failed query is counted rather than failing
+// the whole scrape.
+func (c *DepthCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	depths, err := c.store.Depth(ctx)
+	if err != nil {
+		c.errors.Inc()
+	}
+	now := time.Now()
+	for _, d := range depths {
+		ch <- prometheus.MustNewConstMetric(c.depth, prometheus.GaugeValue, float64(d.Count), d.Queue, d.State)
+		if d.State == jobs.StateAvailable && d.OldestDue != nil {
+			ch <- prometheus.MustNewConstMetric(c.oldestDue, prometheus.GaugeValue, now.Sub(*d.OldestDue).Seconds(), d.Queue)
+		}
+	}
+	c.errors.Collect(ch)
+}
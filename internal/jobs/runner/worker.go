@@ -0,0 +1,340 @@
+// Package runner runs the jobs of package jobs: a Worker claims them from a
+// jobs.Store and calls their handlers, and a DepthCollector reports queue
+// depths as metrics.
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/logger"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/metrics"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/tracing"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/jobs"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// retryBase is the delay after the first failed attempt; it doubles
+	// with each further failure
+	retryBase = 10 * time.Second
+	// maxRetryDelay caps the exponential backoff between attempts
+	maxRetryDelay = time.Hour
+	// maxErrorLength bounds the error stored with a job
+	maxErrorLength = 1000
+	// cleanupInterval is how often Run deletes old finished jobs
+	cleanupInterval = time.Hour
+)
+
+// Outcomes of a job attempt, as recorded in the jobs_processed_total metric.
+const (
+	OutcomeSucceeded = "succeeded"
+	OutcomeRetried   = "retried"
+	OutcomeFailed    = "failed"
+	OutcomeLeaseLost = "lease_lost"
+)
+
+// Periodic enqueues a job every Interval while a worker runs. Unless
+// Options.UniqueKey is set, the job's kind is used as its unique key, so at
+// most one instance waits or runs at a time however many workers there are.
+type Periodic struct {
+	Args     jobs.Args
+	Interval time.Duration
+	Options  jobs.Options
+}
+
+type WorkerOptions struct {
+	// ID identifies the worker in job leases; it defaults to host name and pid
+	ID string
+	// Queues are the queues the worker consumes; empty means DefaultQueue
+	Queues []string
+	// Concurrency is how many jobs run at once
+	Concurrency int
+	// PollInterval is how long Run waits after finding fewer jobs than free slots
+	PollInterval time.Duration
+	// Lease is how long a claimed job stays locked without a heartbeat. The
+	// worker renews it every Lease/3 while the handler runs.
+	Lease time.Duration
+	// Retention is how long succeeded and failed jobs are kept; zero keeps them forever
+	Retention time.Duration
+	// ShutdownTimeout is how long running jobs may finish once Run's context
+	// is done before their own contexts are cancelled
+	ShutdownTimeout time.Duration
+	Periodic        []Periodic
+}
+
+// Worker claims jobs from a Store and runs the handlers registered for
+// them. Any number of workers may consume the same queues.
+type Worker struct {
+	store    jobs.Store
+	registry *jobs.Registry
+	opts     WorkerOptions
+	metrics  *metrics.Metrics
+	now      func() time.Time
+}
+
+// NewWorker returns a worker running jobs from store with the handlers in
+// registry. m may be nil.
+func NewWorker(store jobs.Store, registry *jobs.Registry, opts WorkerOptions, m *metrics.Metrics) *Worker {
+	if opts.ID == "" {
+		host, _ := os.Hostname()
+		opts.ID = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	if len(opts.Queues) == 0 {
+		opts.Queues = []string{jobs.DefaultQueue}
+	}
+	opts.Concurrency = max(opts.Concurrency, 1)
+	return &Worker{store: store, registry: registry, opts: opts, metrics: m, now: time.Now}
+}
+
+// RunOnce claims up to Concurrency due jobs, runs them concurrently and
+// reports how many it claimed once all have finished.
+func (w *Worker) RunOnce(ctx context.Context) (int, error) {
+	batch, err := w.store.Claim(ctx, w.opts.ID, w.opts.Queues, w.opts.Concurrency, w.opts.Lease)
+	if err != nil {
+		return 0, err
+	}
+	var wg sync.WaitGroup
+	for i := range batch {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.process(ctx, &batch[i])
+		}()
+	}
+	wg.Wait()
+	return len(batch), nil
+}
+
+// Run runs jobs until ctx is cancelled, keeping up to Concurrency of them
+// in flight. It claims again as soon as a slot frees up if the last claim
+// filled every free slot, and otherwise polls every PollInterval. Periodic
+// jobs are enqueued as they come due and old finished jobs are deleted
+// once an hour. When ctx is done Run stops claiming and returns once the
+// running jobs have finished.
+func (w *Worker) Run(ctx context.Context) {
+	log := logger.FromContext(ctx)
+	// Running jobs outlive ctx by up to ShutdownTimeout
+	jobCtx, cancelJobs := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelJobs()
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, w.opts.Concurrency)
+	freed := make(chan struct{}, 1)
+	nextPeriodic := make([]time.Time, len(w.opts.Periodic))
+	lastCleanup := time.Time{}
+
+	for ctx.Err() == nil {
+		w.enqueuePeriodic(ctx, nextPeriodic)
+		if w.opts.Retention > 0 && w.now().Sub(lastCleanup) >= cleanupInterval {
+			lastCleanup = w.now()
+			if _, err := w.store.DeleteFinishedBefore(ctx, lastCleanup.Add(-w.opts.Retention)); err != nil && ctx.Err() == nil {
+				log.Errorw("Job cleanup failed", "error", err)
+			}
+		}
+
+		free := cap(slots) - len(slots)
+		var claimed int
+		var err error
+		if free > 0 {
+			var batch []jobs.Job
+			batch, err = w.store.Claim(ctx, w.opts.ID, w.opts.Queues, free, w.opts.Lease)
+			if err != nil && ctx.Err() == nil {
+				log.Errorw("Failed to claim jobs", "error", err)
+			}
+			claimed = len(batch)
+			for i := range batch {
+				slots <- struct{}{}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer func() {
+						<-slots
+						select {
+						case freed <- struct{}{}:
+						default:
+						}
+					}()
+					w.process(jobCtx, &batch[i])
+				}()
+			}
+		}
+
+		switch {
+		case free == 0 || (err == nil && claimed == free):
+			// Busy, or more jobs may be due: claim again once a slot frees up
+			select {
+			case <-ctx.Done():
+			case <-freed:
+			}
+		default:
+			select {
+			case <-ctx.Done():
+			case <-time.After(w.opts.PollInterval):
+			}
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return
+	case <-time.After(w.opts.ShutdownTimeout):
+		log.Warnw("Cancelling running jobs", "running", len(slots))
+		cancelJobs()
+	}
+	<-done
+}
+
+// enqueuePeriodic enqueues the periodic jobs whose time has come; next
+// holds when each is due again.
+func (w *Worker) enqueuePeriodic(ctx context.Context, next []time.Time) {
+	now := w.now()
+	for i, p := range w.opts.Periodic {
+		if now.Before(next[i]) {
+			continue
+		}
+		next[i] = now.Add(p.Interval)
+		opts := p.Options
+		if opts.UniqueKey == "" {
+			opts.UniqueKey = p.Args.Kind()
+		}
+		if _, err := jobs.Enqueue(ctx, w.store, p.Args, opts); err != nil && ctx.Err() == nil {
+			logger.FromContext(ctx).Errorw("Failed to enqueue periodic job", "kind", p.Args.Kind(), "error", err)
+		}
+	}
+}
+
+// process runs one claimed job and records its outcome.
+func (w *Worker) process(ctx context.Context, job *jobs.Job) {
+	started := time.Now()
+	outcome := w.execute(ctx, job)
+	if w.metrics != nil {
+		w.metrics.JobsProcessed.WithLabelValues(job.Queue, job.Kind, outcome).Inc()
+		w.metrics.JobDuration.WithLabelValues(job.Queue, job.Kind).Observe(time.Since(started).Seconds())
+	}
+}
+
+func (w *Worker) execute(ctx context.Context, job *jobs.Job) (outcome string) {
+	ctx, end := tracing.Start(ctx, "Job "+job.Kind, trace.WithSpanKind(trace.SpanKindConsumer), trace.WithAttributes(
+		attribute.Int64("job.id", int64(job.ID)),
+		attribute.String("job.queue", job.Queue),
+		attribute.Int("job.attempt", job.Attempts),
+	))
+	var err error
+	defer func() { end(&err) }()
+	log := logger.FromContext(ctx).With("job_id", job.ID, "kind", job.Kind, "queue", job.Queue, "attempt", job.Attempts)
+
+	handler, ok := w.registry.Handler(job.Kind)
+	switch {
+	case !ok:
+		err = jobs.Permanent(fmt.Errorf("no handler registered for job kind %q", job.Kind))
+	case job.Attempts > job.MaxAttempts:
+		// The lease of the last attempt ran out, so its worker died mid-job
+		err = jobs.Permanent(errors.New("lease expired on the final attempt"))
+	default:
+		err = w.runHandler(ctx, job, handler)
+	}
+
+	if errors.Is(err, jobs.ErrLeaseLost) {
+		log.Warnw("Job lease lost; another worker may run it")
+		return OutcomeLeaseLost
+	}
+	// Record the result even if the job was cancelled by shutdown
+	finishCtx := context.WithoutCancel(ctx)
+	var finishErr error
+	switch {
+	case err == nil:
+		outcome, finishErr = OutcomeSucceeded, w.store.Complete(finishCtx, job)
+	case jobs.IsPermanent(err) || job.Attempts >= job.MaxAttempts:
+		log.Errorw("Job failed permanently", "error", err)
+		outcome, finishErr = OutcomeFailed, w.store.Fail(finishCtx, job, truncateError(err))
+	default:
+		runAt := w.now().Add(retryDelay(job.Attempts))
+		log.Warnw("Job failed", "error", err, "retry_at", runAt)
+		outcome, finishErr = OutcomeRetried, w.store.Retry(finishCtx, job, runAt, truncateError(err))
+	}
+	if errors.Is(finishErr, jobs.ErrLeaseLost) {
+		log.Warnw("Job lease lost before its result was recorded", "outcome", outcome)
+		return OutcomeLeaseLost
+	}
+	if finishErr != nil {
+		// The lease will run out and the job will be retried
+		log.Errorw("Failed to record job result", "outcome", outcome, "error", finishErr)
+	}
+	return outcome
+}
+
+// runHandler calls handler while a heartbeat extends the job's lease. It
+// returns ErrLeaseLost, after cancelling the handler's context, if the
+// lease could not be extended because another worker took the job over.
+func (w *Worker) runHandler(ctx context.Context, job *jobs.Job, handler jobs.Handler) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	stop := make(chan struct{})
+	heartbeat := make(chan struct{})
+	go func() {
+		defer close(heartbeat)
+		ticker := time.NewTicker(max(w.opts.Lease/3, time.Second))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			err := w.store.Extend(ctx, job, w.opts.Lease)
+			if errors.Is(err, jobs.ErrLeaseLost) {
+				cancel(jobs.ErrLeaseLost)
+				return
+			}
+			if err != nil && ctx.Err() == nil {
+				logger.FromContext(ctx).Warnw("Failed to extend job lease", "job_id", job.ID, "error", err)
+			}
+		}
+	}()
+
+	err := safeCall(ctx, job, handler)
+	close(stop)
+	<-heartbeat
+	if errors.Is(context.Cause(ctx), jobs.ErrLeaseLost) {
+		return jobs.ErrLeaseLost
+	}
+	return err
+}
+
+// safeCall runs handler, turning a panic into an error.
+func safeCall(ctx context.Context, job *jobs.Job, handler jobs.Handler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job handler panicked: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// retryDelay doubles from retryBase with each failed attempt.
+func retryDelay(attempts int) time.Duration {
+	if attempts > 10 { // 10s·2^9 already exceeds the cap
+		return maxRetryDelay
+	}
+	return min(retryBase<<(max(attempts, 1)-1), maxRetryDelay)
+}
+
+func truncateError(err error) string {
+	msg := err.Error()
+	if len(msg) > maxErrorLength {
+		msg = msg[:maxErrorLength]
+	}
+	return msg
+}
@@ -0,0 +1,4 @@
+DELETE FROM role_permissions
+WHERE role_id IN (SELECT id FROM roles WHERE name IN ('admin', 'viewer'));
+DELETE FROM roles WHERE name IN ('admin', 'viewer');
+DELETE FROM permissions WHERE name IN ('users:read', 'users:write', 'users:delete', 'roles:read', 'roles:assign');
@@ -0,0 +1,21 @@
+-- Built-in roles and the permissions they grant (see models.Role* and models.Perm*).
+
+INSERT INTO roles (name, created_at, updated_at)
+VALUES ('admin', now(), now()),
+       ('viewer', now(), now())
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO permissions (name)
+VALUES ('users:read'),
+       ('users:write'),
+       ('users:delete'),
+       ('roles:read'),
+       ('roles:assign')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON r.name = 'admin'
+    OR (r.name = 'viewer' AND p.name IN ('users:read', 'roles:read'))
+ON CONFLICT DO NOTHING;
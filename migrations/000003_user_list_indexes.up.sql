@@ -0,0 +1,3 @@
+-- Support keyset pagination and sorting on the listable columns.
+CREATE INDEX IF NOT EXISTS idx_users_created_at_id ON users (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_users_name_id ON users (name, id);
@@ -0,0 +1,3 @@
+-- Optimistic concurrency: every update bumps version, and writes are
+-- conditional on the version the client read.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,5 @@
+-- Fails if a deleted and a live user share an email; purge one of them first.
+DELETE FROM permissions WHERE name = 'users:purge';
+
+DROP INDEX IF EXISTS idx_users_email_live;
+ALTER TABLE users ADD CONSTRAINT uni_users_email UNIQUE (email);
@@ -0,0 +1,14 @@
+-- Soft-deleted users keep their row until purged, so email uniqueness only
+-- applies to live users: a deleted account's address can be reused.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS uni_users_email;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_live ON users (email) WHERE deleted_at IS NULL;
+
+INSERT INTO permissions (name)
+VALUES ('users:purge')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+JOIN permissions p ON r.name = 'admin' AND p.name = 'users:purge'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,9 @@
+-- Token buckets for the Postgres rate limit store (see ratelimit.PostgresStore).
+-- tat is the bucket's theoretical arrival time; rows in the past are full
+-- buckets and are swept periodically.
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limit_buckets (
+    key TEXT PRIMARY KEY,
+    tat TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_tat ON rate_limit_buckets (tat);
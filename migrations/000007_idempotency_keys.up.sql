@@ -0,0 +1,14 @@
+-- Responses of requests sent with an Idempotency-Key (see
+-- idempotency.PostgresStore). status is NULL while the original request is
+-- in flight; locked_at lets a stale lock from a crashed replica be reclaimed.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key         TEXT PRIMARY KEY,
+    fingerprint TEXT NOT NULL,
+    status      INTEGER,
+    header      JSONB,
+    body        BYTEA,
+    locked_at   TIMESTAMPTZ NOT NULL,
+    expires_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
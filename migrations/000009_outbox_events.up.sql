@@ -0,0 +1,20 @@
+-- Transactional outbox (see services.OutboxRelay). Rows are written in the
+-- same transaction as the change they describe and published afterwards.
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id             BIGSERIAL PRIMARY KEY,
+    event_id       UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+    event_type     TEXT NOT NULL,
+    aggregate_type TEXT NOT NULL,
+    aggregate_id   BIGINT NOT NULL,
+    payload        JSONB NOT NULL,
+    request_id     TEXT NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    available_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    published_at   TIMESTAMPTZ,
+    attempts       INTEGER NOT NULL DEFAULT 0,
+    last_error     TEXT NOT NULL DEFAULT ''
+);
+
+-- The relay only ever scans unpublished rows
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending ON outbox_events (available_at, id) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_published_at ON outbox_events (published_at) WHERE published_at IS NOT NULL;
@@ -0,0 +1,28 @@
+-- Background job queue (see package jobs). Workers claim due rows with
+-- FOR UPDATE SKIP LOCKED and hold them under a lease until locked_until.
+CREATE TABLE IF NOT EXISTS jobs (
+    id           BIGSERIAL PRIMARY KEY,
+    queue        TEXT NOT NULL DEFAULT 'default',
+    kind         TEXT NOT NULL,
+    args         JSONB NOT NULL DEFAULT '{}',
+    state        TEXT NOT NULL DEFAULT 'available'
+                 CHECK (state IN ('available', 'running', 'succeeded', 'failed')),
+    attempts     INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 10,
+    run_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    locked_by    TEXT NOT NULL DEFAULT '',
+    locked_until TIMESTAMPTZ,
+    unique_key   TEXT,
+    last_error   TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at  TIMESTAMPTZ
+);
+
+-- Claiming scans due available jobs and running jobs with expired leases
+CREATE INDEX IF NOT EXISTS idx_jobs_fetch ON jobs (queue, run_at, id) WHERE state = 'available';
+CREATE INDEX IF NOT EXISTS idx_jobs_lease ON jobs (locked_until) WHERE state = 'running';
+-- A unique job is skipped while another with the same key waits or runs
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_unique_key ON jobs (unique_key)
+    WHERE unique_key IS NOT NULL AND state IN ('available', 'running');
+CREATE INDEX IF NOT EXISTS idx_jobs_finished_at ON jobs (finished_at) WHERE finished_at IS NOT NULL;
@@ -0,0 +1,15 @@
+-- Email verification. email_verified_at is reset whenever the email changes.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ;
+
+-- Issued verification tokens, by JWT ID, so each can be used only once
+CREATE TABLE IF NOT EXISTS email_verification_tokens (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    token_id   VARCHAR(64) NOT NULL,
+    email      TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_email_verification_tokens_user_id ON email_verification_tokens (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_verification_tokens_token_id ON email_verification_tokens (token_id);
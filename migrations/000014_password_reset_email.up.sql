@@ -0,0 +1,4 @@
+-- Reset tokens are tied to the address they were mailed to. Tokens issued
+-- before that have no address; deleting them makes their links unusable.
+DELETE FROM password_reset_tokens;
+ALTER TABLE password_reset_tokens ADD COLUMN IF NOT EXISTS email TEXT NOT NULL;
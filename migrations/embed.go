@@ -0,0 +1,11 @@
+// Package migrations holds the versioned SQL migrations for the database schema.
+//
+// Files are named <version>_<name>.up.sql and <version>_<name>.down.sql and are
+// applied in version order by database.Migrator. Use `make migrate-create NAME=...`
+// to add a new pair.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,41 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"regexp"
+)
+
+// Header is the HTTP header used to propagate request IDs.
+const Header = "X-Request-ID"
+
+// validID limits accepted client-supplied IDs to a safe charset and length,
+// so they cannot be used to inject content into logs or headers.
+var validID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+type ctxKey struct{}
+
+// New returns a random RFC 4122 version 4 UUID.
+func New() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:]) // crypto/rand.Read never returns an error on supported platforms
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// IsValid reports whether a client-supplied ID may be reused as-is.
+func IsValid(id string) bool {
+	return validID.MatchString(id)
+}
+
+func WithContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext returns the request ID stored in ctx, or "" if there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"net/http"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType is the media type of RFC 7807 error responses.
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details object, extended with a stable
+// error code, the request ID and field-level validation errors.
+type Problem struct {
+	Type      string         `json:"type"`
+	Title     string         `json:"title"`
+	Status    int            `json:"status"`
+	Detail    string         `json:"detail,omitempty"`
+	Instance  string         `json:"instance,omitempty"`
+	Code      string         `json:"code,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
+	Errors    []InvalidParam `json:"errors,omitempty"`
+}
+
+// InvalidParam describes why a single request field was rejected.
+type InvalidParam struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ProblemResponse writes p as application/problem+json and aborts the
+// handler chain. Type, Title, Instance and RequestID are filled in when empty.
+func ProblemResponse(c *gin.Context, p Problem) {
+	if p.Type == "" {
+		p.Type = "about:blank"
+		if p.Code != "" {
+			p.Type = "urn:problem-type:" + p.Code
+		}
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	if p.Instance == "" {
+		p.Instance = c.Request.URL.Path
+	}
+	if p.RequestID == "" {
+		p.RequestID = requestid.FromContext(c.Request.Context())
+	}
+	c.Header("Content-Type", ProblemContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
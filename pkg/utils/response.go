@@ -1,13 +1,13 @@
 package utils
 
-import (
-	"github.com/gin-gonic/gin"
-)
+import "github.com/gin-gonic/gin"
 
 type Response struct {
 	Success bool        `json:"success"`
 	Data    interface{} `json:"data,omitempty"`
 	Message string      `json:"message"`
+	// RequestID is set on unsuccessful envelopes, such as a failing readiness probe
+	RequestID string `json:"request_id,omitempty"`
 }
 
 func SuccessResponse(c *gin.Context, data interface{}, message string) {
@@ -18,9 +18,9 @@ func SuccessResponse(c *gin.Context, data interface{}, message string) {
 	})
 }
 
+// ErrorResponse writes an application/problem+json response with message as
+// the detail. Handlers should prefer c.Error with a typed domain error and
+// let middleware.Errors render it.
 func ErrorResponse(c *gin.Context, statusCode int, message string) {
-	c.JSON(statusCode, Response{
-		Success: false,
-		Message: message,
-	})
+	ProblemResponse(c, Problem{Status: statusCode, Detail: message})
 }
@@ -0,0 +1,149 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/handlers"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/middleware"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/logger"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/requestid"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func setupAuditRouter() (*gin.Engine, *testutils.FakeUserRepository) {
+	repo := testutils.NewFakeUserRepository()
+	userHandler := handlers.NewUserHandler(services.NewUserService(repo, testutils.NewTestPasswords()))
+	auditHandler := handlers.NewAuditHandler(services.NewAuditService(repo.Audit()))
+
+	r, _, _ := testutils.SetupTestRouter(false)
+	r.Use(middleware.RequestID(&logger.Logger{SugaredLogger: zap.NewNop().Sugar()}))
+	r.Use(middleware.ClientIP())
+	r.POST("/api/users", userHandler.Create)
+	r.DELETE("/api/users/:id", userHandler.Delete)
+	r.GET("/api/audit", auditHandler.List)
+	r.GET("/api/audit/verify", auditHandler.Verify)
+	return r, repo
+}
+
+type auditListResponse struct {
+	Data struct {
+		Entries    []models.AuditEntry `json:"entries"`
+		Pagination struct {
+			HasMore    bool `json:"has_more"`
+			NextBefore uint `json:"next_before"`
+		} `json:"pagination"`
+	} `json:"data"`
+}
+
+func getAudit(t *testing.T, r *gin.Engine, path string) (*httptest.ResponseRecorder, auditListResponse) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", path, nil)
+	r.ServeHTTP(w, req)
+	var response auditListResponse
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	}
+	return w, response
+}
+
+func TestAuditRecordsRequestContext(t *testing.T) {
+	r, _ := setupAuditRouter()
+
+	// httptest.NewRequest, unlike http.NewRequest, sets RemoteAddr to 192.0.2.1
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/users",
+		strings.NewReader(`{"name":"Jane Doe","email":"jane@example.com","password":"correct-horse-battery"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(requestid.Header, "audit-test-request")
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w, response := getAudit(t, r, "/api/audit")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, response.Data.Entries, 1)
+	entry := response.Data.Entries[0]
+	assert.Equal(t, models.AuditUserCreated, entry.Action)
+	assert.Equal(t, models.AuditActorAnonymous, entry.ActorType)
+	assert.Equal(t, "audit-test-request", entry.RequestID)
+	assert.Equal(t, "192.0.2.1", entry.IP, "X-Forwarded-For is ignored unless a trusted proxy sent it")
+	assert.Equal(t, "Jane Doe", entry.Changes["name"].After)
+	assert.NotEmpty(t, entry.Hash)
+}
+
+func TestAuditListFiltersAndPages(t *testing.T) {
+	r, _ := setupAuditRouter()
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		w := postJSON(r, "/api/users", map[string]string{"name": "User", "email": email, "password": "correct-horse-battery"})
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+	w := trashRequest(r, "DELETE", "/api/users/2")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	_, response := getAudit(t, r, "/api/audit?action=user.deleted")
+	require.Len(t, response.Data.Entries, 1)
+	assert.Equal(t, uint(2), *response.Data.Entries[0].TargetID)
+
+	_, response = getAudit(t, r, "/api/audit?target_id=2")
+	assert.Len(t, response.Data.Entries, 2)
+
+	w, response = getAudit(t, r, "/api/audit?limit=3")
+	require.Len(t, response.Data.Entries, 3)
+	assert.True(t, response.Data.Pagination.HasMore)
+	assert.Equal(t, uint(2), response.Data.Pagination.NextBefore)
+	assert.Equal(t, `</api/audit?before=2&limit=3>; rel="next"`, w.Header().Get("Link"))
+
+	_, response = getAudit(t, r, "/api/audit?limit=3&before=2")
+	require.Len(t, response.Data.Entries, 1)
+	assert.False(t, response.Data.Pagination.HasMore)
+}
+
+func TestAuditListRejectsInvalidFilters(t *testing.T) {
+	r, _ := setupAuditRouter()
+
+	w, _ := getAudit(t, r, "/api/audit?actor_id=abc&since=yesterday")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	problem := decodeProblem(t, w)
+	assert.Equal(t, "validation_failed", problem.Code)
+	require.Len(t, problem.Errors, 2)
+	assert.Equal(t, "actor_id", problem.Errors[0].Field)
+	assert.Equal(t, "since", problem.Errors[1].Field)
+}
+
+func TestAuditVerify(t *testing.T) {
+	r, repo := setupAuditRouter()
+	w := postJSON(r, "/api/users", map[string]string{"name": "Jane Doe", "email": "jane@example.com", "password": "correct-horse-battery"})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	verify := func() services.AuditVerification {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/audit/verify", nil)
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data services.AuditVerification `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Data
+	}
+
+	result := verify()
+	assert.True(t, result.Valid)
+	assert.Equal(t, int64(1), result.Checked)
+
+	repo.AuditLog().Tamper(1, func(e *models.AuditEntry) { e.IP = "198.51.100.1" })
+	result = verify()
+	assert.False(t, result.Valid)
+	require.NotNil(t, result.BrokenAt)
+	assert.Equal(t, uint(1), *result.BrokenAt)
+}
@@ -0,0 +1,130 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/handlers"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/middleware"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/utils"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postJSON(r *gin.Engine, path string, body interface{}, headers ...string) *httptest.ResponseRecorder {
+	jsonData, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", path, bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthFlow(t *testing.T) {
+	r, userService, db := testutils.SetupTestRouter(true)
+	if db == nil {
+		t.Fatal("Database connection required for auth tests")
+	}
+	testutils.CleanupDatabase(db)
+	defer testutils.CleanupDatabase(db)
+
+	authService, tokens := testutils.SetupTestAuth(db)
+	authHandler := handlers.NewAuthHandler(authService)
+	userHandler := handlers.NewUserHandler(userService)
+
+	r.POST("/api/auth/login", authHandler.Login)
+	r.POST("/api/auth/refresh", authHandler.Refresh)
+	r.POST("/api/auth/logout", authHandler.Logout)
+	r.GET("/api/users/me", middleware.Auth(tokens), userHandler.Me)
+
+	hash, err := testutils.NewTestPasswordHasher().Hash(context.Background(), "correct-horse")
+	require.NoError(t, err)
+	user := models.User{Name: "Auth User", Email: "auth@example.com", PasswordHash: hash}
+	require.NoError(t, db.Create(&user).Error)
+
+	t.Run("Login With Wrong Password", func(t *testing.T) {
+		w := postJSON(r, "/api/auth/login", map[string]string{"email": user.Email, "password": "wrong-password"})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	var pair map[string]interface{}
+	login := func(t *testing.T) map[string]interface{} {
+		w := postJSON(r, "/api/auth/login", map[string]string{"email": user.Email, "password": "correct-horse"})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response utils.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		data, ok := response.Data.(map[string]interface{})
+		require.True(t, ok)
+		assert.NotEmpty(t, data["access_token"])
+		assert.NotEmpty(t, data["refresh_token"])
+		assert.Equal(t, "Bearer", data["token_type"])
+		return data
+	}
+
+	t.Run("Login", func(t *testing.T) {
+		pair = login(t)
+	})
+
+	t.Run("Me Requires Token", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/users/me", nil)
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Me With Token", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/users/me", nil)
+		req.Header.Set("Authorization", "Bearer "+pair["access_token"].(string))
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response utils.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		data := response.Data.(map[string]interface{})
+		assert.Equal(t, user.Email, data["email"])
+		assert.NotContains(t, w.Body.String(), hash, "Password hash must never be serialized")
+	})
+
+	t.Run("Refresh Token Is Not An Access Token", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/users/me", nil)
+		req.Header.Set("Authorization", "Bearer "+pair["refresh_token"].(string))
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Refresh Rotates Token", func(t *testing.T) {
+		old := pair["refresh_token"].(string)
+		w := postJSON(r, "/api/auth/refresh", map[string]string{"refresh_token": old})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		// The rotated-out token can no longer be used
+		wReuse := postJSON(r, "/api/auth/refresh", map[string]string{"refresh_token": old})
+		assert.Equal(t, http.StatusUnauthorized, wReuse.Code)
+
+		var revoked int64
+		db.Model(&models.RefreshToken{}).Where("user_id = ? AND revoked_at IS NULL", user.ID).Count(&revoked)
+		assert.Zero(t, revoked, "Reusing a rotated token should revoke the whole family")
+	})
+
+	t.Run("Logout Revokes Refresh Token", func(t *testing.T) {
+		fresh := login(t)
+		w := postJSON(r, "/api/auth/logout", map[string]string{"refresh_token": fresh["refresh_token"].(string)})
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		wRefresh := postJSON(r, "/api/auth/refresh", map[string]string{"refresh_token": fresh["refresh_token"].(string)})
+		assert.Equal(t, http.StatusUnauthorized, wRefresh.Code)
+	})
+}
@@ -0,0 +1,114 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/handlers"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/middleware"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupConcurrencyRouter(requireIfMatch bool) *gin.Engine {
+	repo := testutils.NewFakeUserRepository(models.User{Name: "Jane Doe", Email: "jane@example.com"})
+	userHandler := handlers.NewUserHandler(services.NewUserService(repo, testutils.NewTestPasswords()))
+
+	r, _, _ := testutils.SetupTestRouter(false)
+	conditional := []gin.HandlerFunc{}
+	if requireIfMatch {
+		conditional = append(conditional, middleware.RequireIfMatch())
+	}
+	r.GET("/api/users/:id", userHandler.Get)
+	r.PUT("/api/users/:id", append(conditional, userHandler.Update)...)
+	r.PATCH("/api/users/:id", append(conditional, userHandler.Patch)...)
+	r.DELETE("/api/users/:id", append(conditional, userHandler.Delete)...)
+	return r
+}
+
+func conditionalRequest(r *gin.Engine, method, ifMatch, contentType, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, "/api/users/1", strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestETagAndIfMatch(t *testing.T) {
+	r := setupConcurrencyRouter(false)
+
+	w := conditionalRequest(r, "GET", "", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	assert.Equal(t, `"1"`, etag)
+
+	t.Run("Not Modified", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/users/1", nil)
+		req.Header.Set("If-None-Match", etag)
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotModified, w.Code)
+	})
+
+	// The first admin wins; the second is working from a stale copy
+	w = conditionalRequest(r, "PUT", etag, "application/json", `{"name":"Jane Smith","email":"jane@example.com"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	newETag := w.Header().Get("ETag")
+	assert.Equal(t, `"2"`, newETag)
+
+	w = conditionalRequest(r, "PUT", etag, "application/json", `{"name":"Jane Jones","email":"jane@example.com"}`)
+	require.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, services.ErrVersionMismatch.Code, decodeProblem(t, w).Code)
+
+	w = conditionalRequest(r, "PATCH", etag, handlers.MergePatchContentType, `{"name":"Jane Jones"}`)
+	require.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	w = conditionalRequest(r, "DELETE", etag, "", "")
+	require.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	w = conditionalRequest(r, "PATCH", newETag, handlers.MergePatchContentType, `{"name":"Jane Jones"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+	var response struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(3), response.Data["version"])
+
+	t.Run("Wildcard And Missing Header", func(t *testing.T) {
+		w := conditionalRequest(r, "PUT", "*", "application/json", `{"name":"Jane Doe","email":"jane@example.com"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = conditionalRequest(r, "PUT", "", "application/json", `{"name":"Jane Doe","email":"jane@example.com"}`)
+		assert.Equal(t, http.StatusOK, w.Code, "If-Match is optional unless RequireIfMatch is enabled")
+	})
+
+	t.Run("Malformed If-Match", func(t *testing.T) {
+		w := conditionalRequest(r, "DELETE", `W/"5"`, "", "")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "invalid_if_match", decodeProblem(t, w).Code)
+	})
+}
+
+func TestRequireIfMatch(t *testing.T) {
+	r := setupConcurrencyRouter(true)
+
+	w := conditionalRequest(r, "DELETE", "", "", "")
+	require.Equal(t, http.StatusPreconditionRequired, w.Code)
+	assert.Equal(t, "if_match_required", decodeProblem(t, w).Code)
+
+	w = conditionalRequest(r, "DELETE", `"1"`, "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,131 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/middleware"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/config"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupCORSRouter(t *testing.T) *gin.Engine {
+	policy, err := middleware.NewCORSPolicy(&config.Config{
+		CORSAllowedOrigins:   []string{"https://app.example.com", " https://*.example.org "},
+		CORSAllowedMethods:   []string{"GET", "POST", "DELETE"},
+		CORSAllowedHeaders:   []string{"Authorization", "Content-Type"},
+		CORSExposedHeaders:   []string{"X-Request-ID", "ETag"},
+		CORSAllowCredentials: true,
+		CORSMaxAge:           10 * time.Minute,
+	})
+	require.NoError(t, err)
+
+	r, _, _ := testutils.SetupTestRouter(false)
+	r.Use(middleware.CORS(policy, map[string]middleware.CORSPolicy{
+		"/public": {AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}},
+	}))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/api/users", ok)
+	r.GET("/public/status", ok)
+	return r
+}
+
+func corsRequest(r *gin.Engine, method, path, origin string, headers ...string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORSPolicyValidation(t *testing.T) {
+	_, err := middleware.NewCORSPolicy(&config.Config{CORSAllowedOrigins: []string{"*"}, CORSAllowCredentials: true})
+	assert.Error(t, err, "Browsers reject a wildcard origin with credentials")
+
+	for _, origin := range []string{"app.example.com", "https://app.example.com/", "https://a*.example.com", "https://*.*.example.com"} {
+		_, err := middleware.NewCORSPolicy(&config.Config{CORSAllowedOrigins: []string{origin}})
+		assert.Error(t, err, origin)
+	}
+}
+
+func TestCORSOriginMatching(t *testing.T) {
+	policy := middleware.CORSPolicy{AllowedOrigins: []string{"https://app.example.com", "https://*.example.org"}}
+
+	assert.True(t, policy.AllowsOrigin("https://app.example.com"))
+	assert.True(t, policy.AllowsOrigin("https://APP.example.com"))
+	assert.True(t, policy.AllowsOrigin("https://a.example.org"))
+	assert.True(t, policy.AllowsOrigin("https://a.b.example.org"))
+
+	assert.False(t, policy.AllowsOrigin("http://app.example.com"), "Scheme must match")
+	assert.False(t, policy.AllowsOrigin("https://app.example.com:8443"), "Port must match")
+	assert.False(t, policy.AllowsOrigin("https://example.org"), "Wildcards need a subdomain")
+	assert.False(t, policy.AllowsOrigin("https://evil.com/.example.org"))
+	assert.False(t, policy.AllowsOrigin("https://example.org.evil.com"))
+}
+
+func TestCORS(t *testing.T) {
+	r := setupCORSRouter(t)
+
+	t.Run("Allowed Origin", func(t *testing.T) {
+		w := corsRequest(r, "GET", "/api/users", "https://app.example.com")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Equal(t, "X-Request-ID, ETag", w.Header().Get("Access-Control-Expose-Headers"))
+		assert.Contains(t, w.Header().Values("Vary"), "Origin")
+	})
+
+	t.Run("Disallowed Origin Gets No CORS Headers", func(t *testing.T) {
+		w := corsRequest(r, "GET", "/api/users", "https://evil.example.com")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, w.Header().Values("Vary"), "Origin", "Caches must still key on Origin")
+	})
+
+	t.Run("Preflight", func(t *testing.T) {
+		w := corsRequest(r, "OPTIONS", "/api/users", "https://x.example.org",
+			"Access-Control-Request-Method", "DELETE",
+			"Access-Control-Request-Headers", "authorization, content-type, accept")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "https://x.example.org", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "GET, POST, DELETE", w.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "authorization, content-type, accept", w.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+	})
+
+	for name, headers := range map[string][]string{
+		"Preflight Rejects Disallowed Method": {"Access-Control-Request-Method", "PATCH"},
+		"Preflight Rejects Disallowed Header": {"Access-Control-Request-Method", "GET", "Access-Control-Request-Headers", "X-Secret"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := corsRequest(r, "OPTIONS", "/api/users", "https://app.example.com", headers...)
+			assert.Equal(t, http.StatusForbidden, w.Code)
+			assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+			assert.Equal(t, "cors_preflight_rejected", decodeProblem(t, w).Code)
+		})
+	}
+
+	t.Run("Preflight Rejects Disallowed Origin", func(t *testing.T) {
+		w := corsRequest(r, "OPTIONS", "/api/users", "https://evil.example.com", "Access-Control-Request-Method", "GET")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Route Group Override", func(t *testing.T) {
+		w := corsRequest(r, "GET", "/public/status", "https://anywhere.test")
+		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+
+		w = corsRequest(r, "OPTIONS", "/public/status", "https://anywhere.test", "Access-Control-Request-Method", "POST")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
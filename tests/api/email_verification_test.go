@@ -0,0 +1,68 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/handlers"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupVerificationRouter(users ...models.User) (*gin.Engine, *testutils.FakeUserRepository) {
+	repo := testutils.NewFakeUserRepository(users...)
+	h := handlers.NewEmailVerificationHandler(services.NewEmailVerificationService(repo, testutils.NewTestJWTManager(), services.EmailVerificationOptions{}))
+
+	r, _, _ := testutils.SetupTestRouter(false)
+	r.POST("/api/users/:id/verify-email/send", h.Send)
+	r.GET("/api/verify-email", h.Verify)
+	return r, repo
+}
+
+func TestSendVerificationEmail(t *testing.T) {
+	r, repo := setupVerificationRouter(models.User{Name: "Jane Doe", Email: "jane@example.com"})
+
+	decodeData(t, webhookRequest(r, "POST", "/api/users/1/verify-email/send", nil), nil)
+	queued := repo.JobStore().Jobs()
+	require.Len(t, queued, 1)
+	assert.Equal(t, services.SendVerificationEmailArgs{}.Kind(), queued[0].Kind)
+
+	assert.Equal(t, http.StatusNotFound, webhookRequest(r, "POST", "/api/users/2/verify-email/send", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, webhookRequest(r, "POST", "/api/users/abc/verify-email/send", nil).Code)
+}
+
+func TestVerifyEmailEndpoint(t *testing.T) {
+	r, repo := setupVerificationRouter(models.User{Name: "Jane Doe", Email: "jane@example.com"})
+	ctx := context.Background()
+	user, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+
+	// Issue a token the way the worker does
+	token, claims, err := testutils.NewTestJWTManager().GenerateEmailVerificationToken(user)
+	require.NoError(t, err)
+	require.NoError(t, repo.EmailVerifications().Create(ctx, &models.EmailVerificationToken{
+		UserID: user.ID, TokenID: claims.ID, Email: user.Email, ExpiresAt: claims.ExpiresAt.Time,
+	}))
+
+	assert.Equal(t, http.StatusBadRequest, webhookRequest(r, "GET", "/api/verify-email", nil).Code, "The token is required")
+
+	w := webhookRequest(r, "GET", "/api/verify-email?token="+url.QueryEscape(token), nil)
+	var verified map[string]interface{}
+	decodeData(t, w, &verified)
+	assert.NotNil(t, verified["email_verified_at"])
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+
+	w = webhookRequest(r, "GET", "/api/verify-email?token="+url.QueryEscape(token), nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "Tokens are single use")
+	assert.Contains(t, w.Body.String(), "invalid_verification_token")
+
+	assert.Equal(t, http.StatusConflict, webhookRequest(r, "POST", "/api/users/1/verify-email/send", nil).Code,
+		"Verified addresses need no email")
+	assert.Empty(t, repo.JobStore().Jobs())
+}
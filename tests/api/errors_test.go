@@ -0,0 +1,113 @@
+package api_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/handlers"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/middleware"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/logger"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/requestid"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/utils"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func setupErrorsRouter() (*gin.Engine, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	log := &logger.Logger{SugaredLogger: zap.New(core).Sugar()}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestID(log))
+	r.Use(middleware.Errors())
+
+	userHandler := handlers.NewUserHandler(services.NewUserService(testutils.NewFakeUserRepository(), testutils.NewTestPasswords()))
+	r.POST("/api/users", userHandler.Create)
+	r.GET("/api/users/:id", userHandler.Get)
+	r.GET("/boom", func(c *gin.Context) {
+		c.Error(errors.New(`pq: relation "users" does not exist`))
+	})
+	return r, logs
+}
+
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) utils.Problem {
+	t.Helper()
+	assert.Equal(t, utils.ProblemContentType, w.Header().Get("Content-Type"))
+	var problem utils.Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	return problem
+}
+
+func TestProblemValidationErrors(t *testing.T) {
+	r, _ := setupErrorsRouter()
+
+	w := postJSON(r, "/api/users", map[string]string{"email": "not-an-email"})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	problem := decodeProblem(t, w)
+	assert.Equal(t, "validation_failed", problem.Code)
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, "/api/users", problem.Instance)
+	assert.Equal(t, w.Header().Get(requestid.Header), problem.RequestID)
+
+	fields := map[string]string{}
+	for _, e := range problem.Errors {
+		fields[e.Field] = e.Code
+	}
+	assert.Equal(t, map[string]string{"name": "required", "email": "email"}, fields, "Field errors should use JSON names")
+}
+
+func TestProblemMalformedBody(t *testing.T) {
+	r, _ := setupErrorsRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/users", strings.NewReader(`{"name":`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "malformed_body", decodeProblem(t, w).Code)
+}
+
+func TestProblemDomainError(t *testing.T) {
+	r, _ := setupErrorsRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/users/42", nil)
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusNotFound, w.Code)
+	problem := decodeProblem(t, w)
+	assert.Equal(t, services.ErrUserNotFound.Code, problem.Code)
+	assert.Equal(t, "urn:problem-type:"+services.ErrUserNotFound.Code, problem.Type)
+	assert.Equal(t, "Not Found", problem.Title)
+}
+
+func TestProblemInternalErrorIsNotLeaked(t *testing.T) {
+	r, logs := setupErrorsRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/boom", nil)
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	problem := decodeProblem(t, w)
+	assert.Equal(t, "internal_error", problem.Code)
+	assert.NotContains(t, w.Body.String(), "pq:", "Internal error details must not reach the client")
+
+	failures := logs.FilterMessage("Request failed").All()
+	require.Len(t, failures, 1)
+	assert.Equal(t, problem.RequestID, failures[0].ContextMap()["request_id"])
+	assert.Contains(t, failures[0].ContextMap()["error"], "pq:")
+}
@@ -1,12 +1,16 @@
 package api_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/handlers"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/health"
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/utils"
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
 	"github.com/stretchr/testify/assert"
@@ -14,7 +18,7 @@ import (
 
 func TestHealthCheck(t *testing.T) {
 	// Setup - health check doesn't need database
-	r, _ := testutils.SetupTestRouter(false)
+	r, _, _ := testutils.SetupTestRouter(false)
 	r.GET("/api/health", handlers.HealthCheck)
 
 	// Create request
@@ -33,3 +37,84 @@ func TestHealthCheck(t *testing.T) {
 	assert.True(t, response.Success)
 	assert.Equal(t, "Service is healthy", response.Message)
 }
+
+func setupHealthRouter(checks ...health.Check) http.Handler {
+	r, _, _ := testutils.SetupTestRouter(false)
+	registry := health.NewRegistry()
+	for _, check := range checks {
+		registry.Register(check)
+	}
+	h := handlers.NewHealthHandler(registry)
+	r.GET("/api/health/live", h.Live)
+	r.GET("/api/health/ready", h.Ready)
+	return r
+}
+
+func getReport(t *testing.T, r http.Handler, path string) (int, health.Report) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", path, nil)
+	r.ServeHTTP(w, req)
+
+	var response struct {
+		Success bool          `json:"success"`
+		Data    health.Report `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return w.Code, response.Data
+}
+
+func TestLivenessIgnoresDependencies(t *testing.T) {
+	r := setupHealthRouter(health.Check{
+		Name: "postgres", Critical: true,
+		Fn: func(ctx context.Context) error { return errors.New("connection refused") },
+	})
+
+	code, _ := getReport(t, r, "/api/health/live")
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestReadiness(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+	fail := func(ctx context.Context) error { return errors.New("connection refused") }
+
+	t.Run("All Checks Pass", func(t *testing.T) {
+		r := setupHealthRouter(health.Check{Name: "postgres", Critical: true, Fn: ok})
+		code, report := getReport(t, r, "/api/health/ready")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, health.StatusUp, report.Status)
+		assert.Len(t, report.Checks, 1)
+		assert.Equal(t, "postgres", report.Checks[0].Name)
+		assert.Equal(t, health.StatusUp, report.Checks[0].Status)
+	})
+
+	t.Run("Critical Check Fails", func(t *testing.T) {
+		r := setupHealthRouter(
+			health.Check{Name: "postgres", Critical: true, Fn: fail},
+			health.Check{Name: "cache", Fn: ok},
+		)
+		code, report := getReport(t, r, "/api/health/ready")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, health.StatusDown, report.Status)
+		assert.Equal(t, "connection refused", report.Checks[0].Error)
+	})
+
+	t.Run("Non-Critical Check Fails", func(t *testing.T) {
+		r := setupHealthRouter(
+			health.Check{Name: "postgres", Critical: true, Fn: ok},
+			health.Check{Name: "cache", Fn: fail},
+		)
+		code, report := getReport(t, r, "/api/health/ready")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, health.StatusDegraded, report.Status)
+	})
+
+	t.Run("Check Times Out", func(t *testing.T) {
+		r := setupHealthRouter(health.Check{
+			Name: "postgres", Critical: true, Timeout: 20 * time.Millisecond,
+			Fn: func(ctx context.Context) error { time.Sleep(time.Second); return nil },
+		})
+		code, report := getReport(t, r, "/api/health/ready")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks[0].Error)
+	})
+}
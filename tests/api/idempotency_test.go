@@ -0,0 +1,154 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/handlers"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/middleware"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/identity"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/utils"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func idempotentPost(r *gin.Engine, path, key string, body any, headers ...string) *httptest.ResponseRecorder {
+	return postJSON(r, path, body, append([]string{middleware.IdempotencyKeyHeader, key}, headers...)...)
+}
+
+func TestIdempotency(t *testing.T) {
+	repo := testutils.NewFakeUserRepository()
+	userHandler := handlers.NewUserHandler(services.NewUserService(repo, testutils.NewTestPasswords()))
+	idem := middleware.Idempotency(testutils.NewFakeIdempotencyStore())
+
+	var failures int
+	r, _, _ := testutils.SetupTestRouter(false)
+	asUser := func(c *gin.Context) {
+		if id := c.GetHeader("X-Test-User"); id != "" {
+			ctx := identity.WithPrincipal(c.Request.Context(), &identity.Principal{UserID: uint(len(id))})
+			c.Request = c.Request.WithContext(ctx)
+		}
+	}
+	r.POST("/api/users", asUser, idem, userHandler.Create)
+	r.POST("/flaky", idem, func(c *gin.Context) {
+		failures++
+		if failures == 1 {
+			c.Error(errors.New("database unavailable"))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"attempt": failures})
+	})
+
+	body := map[string]string{"name": "Jane Doe", "email": "jane@example.com"}
+
+	t.Run("Retry Replays The Stored Response", func(t *testing.T) {
+		first := idempotentPost(r, "/api/users", "create-jane", body)
+		require.Equal(t, http.StatusOK, first.Code)
+		assert.Empty(t, first.Header().Get(middleware.IdempotentReplayedHeader))
+
+		retry := idempotentPost(r, "/api/users", "create-jane", body)
+		require.Equal(t, http.StatusOK, retry.Code, "A retry must not fail with user_email_exists")
+		assert.Equal(t, "true", retry.Header().Get(middleware.IdempotentReplayedHeader))
+		assert.Equal(t, first.Body.String(), retry.Body.String())
+		assert.Equal(t, first.Header().Get("Content-Type"), retry.Header().Get("Content-Type"))
+
+		count, err := repo.Count(context.Background(), repositories.UserQuery{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("Key Reused With A Different Payload", func(t *testing.T) {
+		w := idempotentPost(r, "/api/users", "create-jane", map[string]string{"name": "John Roe", "email": "john@example.com"})
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, "idempotency_key_reused", decodeProblem(t, w).Code)
+	})
+
+	t.Run("Errors Are Replayed Too", func(t *testing.T) {
+		first := idempotentPost(r, "/api/users", "dup-jane", body)
+		require.Equal(t, http.StatusConflict, first.Code)
+		retry := idempotentPost(r, "/api/users", "dup-jane", body)
+		assert.Equal(t, http.StatusConflict, retry.Code)
+		assert.Equal(t, "true", retry.Header().Get(middleware.IdempotentReplayedHeader))
+		assert.Equal(t, utils.ProblemContentType, retry.Header().Get("Content-Type"))
+	})
+
+	t.Run("Keys Are Scoped Per User", func(t *testing.T) {
+		w := idempotentPost(r, "/api/users", "create-jane", map[string]string{"name": "John Roe", "email": "john@example.com"},
+			"X-Test-User", "someone")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get(middleware.IdempotentReplayedHeader))
+	})
+
+	t.Run("Anonymous Keys Are Scoped Per Client IP", func(t *testing.T) {
+		fromIP := func(ip string, body any) *httptest.ResponseRecorder {
+			payload, _ := json.Marshal(body)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/users", bytes.NewReader(payload))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(middleware.IdempotencyKeyHeader, "signup")
+			req.RemoteAddr = ip + ":4711"
+			r.ServeHTTP(w, req)
+			return w
+		}
+		first := fromIP("203.0.113.1", map[string]string{"name": "Ann Lee", "email": "ann@example.com"})
+		require.Equal(t, http.StatusOK, first.Code)
+		assert.Equal(t, "true", fromIP("203.0.113.1", map[string]string{"name": "Ann Lee", "email": "ann@example.com"}).Header().Get(middleware.IdempotentReplayedHeader))
+
+		other := fromIP("203.0.113.2", map[string]string{"name": "Bob Lee", "email": "bob@example.com"})
+		assert.Equal(t, http.StatusOK, other.Code, "Another client's key is not reused")
+		assert.Empty(t, other.Header().Get(middleware.IdempotentReplayedHeader))
+		assert.NotContains(t, other.Body.String(), "ann@example.com")
+	})
+
+	t.Run("Server Errors Are Not Stored", func(t *testing.T) {
+		first := idempotentPost(r, "/flaky", "flaky", nil)
+		require.Equal(t, http.StatusInternalServerError, first.Code)
+		retry := idempotentPost(r, "/flaky", "flaky", nil)
+		assert.Equal(t, http.StatusOK, retry.Code)
+		assert.Empty(t, retry.Header().Get(middleware.IdempotentReplayedHeader))
+	})
+
+	t.Run("Invalid Key", func(t *testing.T) {
+		w := idempotentPost(r, "/api/users", "bad\tkey", body)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "invalid_idempotency_key", decodeProblem(t, w).Code)
+	})
+}
+
+func TestIdempotencyLocksInFlightDuplicates(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	r, _, _ := testutils.SetupTestRouter(false)
+	r.POST("/slow", middleware.Idempotency(testutils.NewFakeIdempotencyStore()), func(c *gin.Context) {
+		close(started)
+		<-release
+		c.Status(http.StatusNoContent)
+	})
+
+	var wg sync.WaitGroup
+	var first *httptest.ResponseRecorder
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		first = idempotentPost(r, "/slow", "slow-key", nil)
+	}()
+	<-started
+
+	w := idempotentPost(r, "/slow", "slow-key", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "idempotency_request_in_progress", decodeProblem(t, w).Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, http.StatusNoContent, first.Code)
+}
@@ -0,0 +1,227 @@
+package api_test
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/handlers"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupImportRouter(users ...models.User) *gin.Engine {
+	userHandler := handlers.NewUserHandler(services.NewUserService(testutils.NewFakeUserRepository(users...), testutils.NewTestPasswords()))
+	r, _, _ := testutils.SetupTestRouter(false)
+	r.GET("/api/users", userHandler.List)
+	r.POST("/api/users/import", userHandler.Import)
+	r.GET("/api/users/export", userHandler.Export)
+	return r
+}
+
+func importUsers(t *testing.T, r *gin.Engine, query, contentType, body string) services.ImportReport {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/users/import"+query, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Data services.ImportReport `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response.Data
+}
+
+func failedLines(report services.ImportReport) map[int]string {
+	lines := map[int]string{}
+	for _, e := range report.Errors {
+		lines[e.Line] = e.Errors[0].Code
+	}
+	return lines
+}
+
+func TestImportCSV(t *testing.T) {
+	r := setupImportRouter(models.User{Name: "Existing", Email: "taken@example.com"})
+
+	body := strings.Join([]string{
+		"Email,Name,Password,Ignored",
+		"ada@example.com,Ada Lovelace,,x",
+		"grace@example.com,Grace Hopper,correct-horse,x",
+		"not-an-email,Bad Email,,x",
+		"ada@example.com,Ada Again,,x",
+		"taken@example.com,Taken,,x",
+		"short@example.com,Al,short,x",
+		`"broken,quote@example.com,Broken,,x`,
+	}, "\n")
+	report := importUsers(t, r, "?batch_size=2", handlers.CSVContentType, body)
+
+	assert.Equal(t, services.ImportModeBatch, report.Mode)
+	assert.Equal(t, 2, report.Imported)
+	assert.False(t, report.RolledBack)
+	assert.Equal(t, map[int]string{
+		4: "email",
+		5: "duplicate",
+		6: "user_email_exists",
+		7: "too_short",
+		8: "syntax",
+	}, failedLines(report))
+	assert.Equal(t, report.Rows, report.Imported+report.Failed)
+	for _, e := range report.Errors {
+		if e.Line == 4 {
+			assert.Equal(t, "email", e.Errors[0].Field, "Fields are reported by their JSON name")
+		}
+	}
+}
+
+func TestImportNDJSON(t *testing.T) {
+	r := setupImportRouter()
+	body := `{"name":"Ada Lovelace","email":"ada@example.com"}
+
+{"name":"Grace Hopper","email":"grace@example.com","id":42}
+{"name":123,"email":"bad@example.com"}
+not json
+`
+	report := importUsers(t, r, "", "application/x-ndjson", body)
+	assert.Equal(t, 2, report.Imported)
+	assert.Equal(t, map[int]string{4: "type", 5: "syntax"}, failedLines(report))
+}
+
+func TestImportAllOrNothing(t *testing.T) {
+	r := setupImportRouter()
+	body := "name,email\nAda Lovelace,ada@example.com\nGrace Hopper,grace@example.com\nBad,bad\n"
+
+	report := importUsers(t, r, "?transaction=all&batch_size=1", handlers.CSVContentType, body)
+	assert.True(t, report.RolledBack)
+	assert.Zero(t, report.Imported)
+	assert.Equal(t, 1, report.Failed)
+	assert.Empty(t, exportedEmails(t, r, "csv"), "A failed all-or-nothing import leaves no users behind")
+
+	report = importUsers(t, r, "?transaction=all", handlers.CSVContentType, "name,email\nAda Lovelace,ada@example.com\n")
+	assert.False(t, report.RolledBack)
+	assert.Equal(t, 1, report.Imported)
+}
+
+func TestImportRejectsBadRequests(t *testing.T) {
+	r := setupImportRouter()
+	for name, tc := range map[string]struct {
+		query, contentType, body string
+		status                   int
+		code                     string
+	}{
+		"Unsupported Media Type": {"", "application/json", "[]", http.StatusUnsupportedMediaType, ""},
+		"Missing Columns":        {"", handlers.CSVContentType, "name,password\n", http.StatusBadRequest, "invalid_csv_header"},
+		"Unknown Mode":           {"?transaction=sometimes", handlers.CSVContentType, "name,email\n", http.StatusBadRequest, "invalid_import_mode"},
+		"Batch Size Too Large":   {"?batch_size=100000", handlers.CSVContentType, "name,email\n", http.StatusBadRequest, "validation_failed"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/users/import"+tc.query, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tc.status, w.Code)
+			if tc.code != "" {
+				assert.Equal(t, tc.code, decodeProblem(t, w).Code)
+			}
+		})
+	}
+}
+
+func exportedEmails(t *testing.T, r *gin.Engine, format string) []string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/users/export?format="+format, nil)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	emails := []string{}
+	switch format {
+	case "csv":
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.NotEmpty(t, records)
+		assert.Equal(t, []string{"id", "name", "email", "version", "created_at", "updated_at", "deleted_at"}, records[0])
+		for _, record := range records[1:] {
+			emails = append(emails, record[2])
+		}
+	case "ndjson":
+		scanner := bufio.NewScanner(w.Body)
+		for scanner.Scan() {
+			var u models.User
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &u))
+			emails = append(emails, u.Email)
+		}
+	}
+	return emails
+}
+
+func TestExport(t *testing.T) {
+	// More users than fit on one export page
+	users := make([]models.User, 1203)
+	for i := range users {
+		users[i] = models.User{Name: fmt.Sprintf("User %d", i+1), Email: fmt.Sprintf("user%04d@example.com", i+1)}
+	}
+	r := setupImportRouter(users...)
+
+	for _, format := range []string{"csv", "ndjson"} {
+		t.Run(format, func(t *testing.T) {
+			emails := exportedEmails(t, r, format)
+			require.Len(t, emails, len(users))
+			assert.Equal(t, "user0001@example.com", emails[0])
+			assert.Equal(t, "user1203@example.com", emails[len(emails)-1])
+		})
+	}
+
+	t.Run("Round Trip", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/users/export", nil)
+		r.ServeHTTP(w, req)
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "users.csv")
+
+		report := importUsers(t, setupImportRouter(), "", handlers.CSVContentType, w.Body.String())
+		assert.Equal(t, len(users), report.Imported)
+	})
+
+	t.Run("Invalid Format", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/users/export?format=xml", nil)
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Disposition"))
+	})
+}
+
+func TestExportEscapesFormulas(t *testing.T) {
+	names := []string{"=HYPERLINK(\"http://evil.example\")", "+1", "-1", "@SUM(A1)", "\tTab", "\rReturn", "Jane = Doe"}
+	users := make([]models.User, len(names))
+	for i, name := range names {
+		users[i] = models.User{Name: name, Email: fmt.Sprintf("user%d@example.com", i+1)}
+	}
+	users = append(users, models.User{Name: "Plus", Email: "+plus@example.com"})
+	r := setupImportRouter(users...)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/users/export", nil)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	records, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, len(users)+1)
+
+	for i, name := range names[:6] {
+		assert.Equal(t, "'"+name, records[i+1][1])
+	}
+	assert.Equal(t, "Jane = Doe", records[7][1], "Only a leading formula character is escaped")
+	assert.Equal(t, "'+plus@example.com", records[8][2])
+	assert.Equal(t, "1", records[1][0], "Cells the API generates are left alone")
+}
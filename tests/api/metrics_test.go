@@ -0,0 +1,65 @@
+package api_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/middleware"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/metrics"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, r http.Handler) string {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	body, _ := io.ReadAll(w.Body)
+	return string(body)
+}
+
+func TestHTTPMetrics(t *testing.T) {
+	r, _, _ := testutils.SetupTestRouter(false)
+	m := metrics.New()
+	r.Use(middleware.Metrics(m))
+	r.GET("/metrics", gin.WrapH(m.Handler()))
+	r.GET("/api/users/:id", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	for _, path := range []string{"/api/users/1", "/api/users/2", "/does-not-exist"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		r.ServeHTTP(w, req)
+	}
+
+	body := scrape(t, r)
+	assert.Contains(t, body, `app_http_requests_total{method="GET",route="/api/users/:id",status="204"} 2`,
+		"Requests should be labelled by route template, not raw path")
+	assert.Contains(t, body, `app_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, body, `app_http_request_duration_seconds_count{method="GET",route="/api/users/:id",status="204"} 2`)
+	assert.Contains(t, body, `app_http_requests_in_flight{method="GET",route="/metrics"} 1`)
+	assert.False(t, strings.Contains(body, `route="/api/users/1"`))
+}
+
+func TestGormQueryMetrics(t *testing.T) {
+	// A dry run builds statements and runs callbacks without touching a database
+	db := testutils.NewDryRunDB()
+	m := metrics.New()
+	require.NoError(t, db.Use(m.GormPlugin()))
+
+	var users []models.User
+	db.Where("email = ?", "metrics@example.com").Find(&users)
+	db.Create(&models.User{Name: "Metrics", Email: "metrics@example.com"})
+
+	r := gin.New()
+	r.GET("/metrics", gin.WrapH(m.Handler()))
+	body := scrape(t, r)
+	assert.Contains(t, body, `app_db_query_duration_seconds_count{operation="query",status="ok",table="users"} 1`)
+	assert.Contains(t, body, `app_db_query_duration_seconds_count{operation="create",status="ok",table="users"} 1`)
+}
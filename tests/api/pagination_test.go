@@ -0,0 +1,124 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/handlers"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/utils"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupPaginationRouter(n int) *gin.Engine {
+	users := make([]models.User, n)
+	for i := range users {
+		users[i] = models.User{Name: fmt.Sprintf("User %d", i+1), Email: fmt.Sprintf("user%d@example.com", i+1)}
+	}
+	userHandler := handlers.NewUserHandler(services.NewUserService(testutils.NewFakeUserRepository(users...), testutils.NewTestPasswords()))
+
+	r, _, _ := testutils.SetupTestRouter(false)
+	r.GET("/api/users", userHandler.List)
+	return r
+}
+
+func listUsers(t *testing.T, r *gin.Engine, query string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/users?"+query, nil)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		return w, nil
+	}
+	var response utils.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return w, response.Data.(map[string]interface{})
+}
+
+func TestCursorPagination(t *testing.T) {
+	r := setupPaginationRouter(25)
+
+	var seen []float64
+	query := "after=&limit=10"
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 5, "Pagination should terminate")
+		w, data := listUsers(t, r, query)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		for _, u := range data["users"].([]interface{}) {
+			seen = append(seen, u.(map[string]interface{})["id"].(float64))
+		}
+		pagination := data["pagination"].(map[string]interface{})
+		assert.NotContains(t, pagination, "total_items", "Counting is opt-in in cursor mode")
+		if !pagination["has_more"].(bool) {
+			assert.Empty(t, w.Header().Get("Link"))
+			break
+		}
+		assert.Contains(t, w.Header().Get("Link"), `rel="next"`)
+		query = "limit=10&after=" + url.QueryEscape(pagination["next_cursor"].(string))
+	}
+
+	require.Len(t, seen, 25)
+	for i, id := range seen {
+		assert.Equal(t, float64(i+1), id, "Users should be returned once each, in ID order")
+	}
+}
+
+func TestCursorPaginationCounts(t *testing.T) {
+	r := setupPaginationRouter(3)
+
+	_, data := listUsers(t, r, "after=&count=exact")
+	pagination := data["pagination"].(map[string]interface{})
+	assert.Equal(t, float64(3), pagination["total_items"])
+	assert.Equal(t, false, pagination["total_estimated"])
+
+	_, data = listUsers(t, r, "after=&count=estimate")
+	assert.Equal(t, true, data["pagination"].(map[string]interface{})["total_estimated"])
+}
+
+func TestPaginationLimits(t *testing.T) {
+	r := setupPaginationRouter(3)
+
+	_, data := listUsers(t, r, "page=1&limit=1000")
+	assert.Equal(t, float64(services.MaxPageLimit), data["pagination"].(map[string]interface{})["per_page"], "Limit should be capped")
+
+	_, data = listUsers(t, r, "after=&limit=1000")
+	assert.Equal(t, float64(services.MaxPageLimit), data["pagination"].(map[string]interface{})["per_page"], "Limit should be capped")
+
+	tests := []struct {
+		query, code, field string
+	}{
+		{"page=0", "validation_failed", "page"},
+		{"limit=-5", "validation_failed", "limit"},
+		{"after=&limit=0", "validation_failed", "limit"},
+		{"after=&count=maybe", "validation_failed", "count"},
+		{"after=not-a-cursor", "invalid_cursor", ""},
+		{"password_hash[eq]=x", "validation_failed", "password_hash[eq]"},
+		{"sort=-created_at&after=" + nextCursor(t, r, "after=&limit=1"), "invalid_cursor", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w, _ := listUsers(t, r, tt.query)
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			problem := decodeProblem(t, w)
+			assert.Equal(t, tt.code, problem.Code)
+			if tt.field != "" {
+				require.Len(t, problem.Errors, 1)
+				assert.Equal(t, tt.field, problem.Errors[0].Field)
+			}
+		})
+	}
+}
+
+func nextCursor(t *testing.T, r *gin.Engine, query string) string {
+	_, data := listUsers(t, r, query)
+	return url.QueryEscape(data["pagination"].(map[string]interface{})["next_cursor"].(string))
+}
@@ -0,0 +1,151 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/handlers"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/middleware"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupPasswordRouter(t *testing.T) (*gin.Engine, *testutils.FakeUserRepository) {
+	t.Helper()
+	hash, err := testutils.NewTestPasswordHasher().Hash(context.Background(), "correct-horse-battery")
+	require.NoError(t, err)
+	repo := testutils.NewFakeUserRepository(models.User{Name: "Jane Doe", Email: "jane@example.com", PasswordHash: hash})
+	passwords := services.NewPasswordService(repo, testutils.NewTestJWTManager(),
+		services.PasswordServiceOptions{Passwords: testutils.NewTestPasswords()})
+	h := handlers.NewPasswordHandler(passwords)
+	userHandler := handlers.NewUserHandler(services.NewUserService(repo, testutils.NewTestPasswords()))
+
+	r, _, _ := testutils.SetupTestRouter(false)
+	r.POST("/api/users", userHandler.Create)
+	r.POST("/api/auth/password/forgot", h.Forgot)
+	r.POST("/api/auth/password/reset", h.Reset)
+	r.PUT("/api/users/:id/password", h.Change)
+	return r, repo
+}
+
+func TestCreateUserAppliesPasswordPolicy(t *testing.T) {
+	r, repo := setupPasswordRouter(t)
+
+	w := webhookRequest(r, "POST", "/api/users", map[string]string{"name": "John Doe", "email": "john@example.com", "password": "Password2024!"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "weak_password")
+	assert.Contains(t, w.Body.String(), "too_common")
+
+	w = webhookRequest(r, "POST", "/api/users", map[string]string{"name": "John Doe", "email": "john@example.com", "password": "correct-horse-battery"})
+	var created map[string]interface{}
+	decodeData(t, w, &created)
+	stored, err := repo.GetByEmail(context.Background(), "john@example.com")
+	require.NoError(t, err)
+	assert.NotContains(t, w.Body.String(), stored.PasswordHash, "Password hash must never be serialized")
+	assert.NotContains(t, w.Body.String(), "correct-horse-battery")
+	assert.NotContains(t, created, "password")
+}
+
+func TestForgotPasswordEndpoint(t *testing.T) {
+	r, repo := setupPasswordRouter(t)
+
+	known := webhookRequest(r, "POST", "/api/auth/password/forgot", map[string]string{"email": "jane@example.com"})
+	unknown := webhookRequest(r, "POST", "/api/auth/password/forgot", map[string]string{"email": "nobody@example.com"})
+	assert.Equal(t, http.StatusOK, known.Code)
+	assert.Equal(t, known.Body.String(), unknown.Body.String(), "Responses do not reveal which emails have accounts")
+	queued := repo.JobStore().Jobs()
+	require.Len(t, queued, 1)
+	assert.Equal(t, services.SendPasswordResetEmailArgs{}.Kind(), queued[0].Kind)
+
+	assert.Equal(t, http.StatusBadRequest, webhookRequest(r, "POST", "/api/auth/password/forgot", map[string]string{"email": "nope"}).Code)
+}
+
+func TestResetPasswordEndpoint(t *testing.T) {
+	r, repo := setupPasswordRouter(t)
+	ctx := context.Background()
+	user, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+
+	// Issue a token the way the worker does
+	token, claims, err := testutils.NewTestJWTManager().GeneratePasswordResetToken(user)
+	require.NoError(t, err)
+	require.NoError(t, repo.PasswordResets().Create(ctx, &models.PasswordResetToken{
+		UserID: user.ID, TokenID: claims.ID, Email: user.Email, ExpiresAt: claims.ExpiresAt.Time,
+	}))
+
+	w := webhookRequest(r, "POST", "/api/auth/password/reset", map[string]string{"token": token, "password": "short"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "too_short")
+
+	decodeData(t, webhookRequest(r, "POST", "/api/auth/password/reset", map[string]string{"token": token, "password": "new-horse-battery"}), nil)
+
+	w = webhookRequest(r, "POST", "/api/auth/password/reset", map[string]string{"token": token, "password": "new-horse-battery"})
+	assert.Equal(t, http.StatusBadRequest, w.Code, "Tokens are single use")
+	assert.Contains(t, w.Body.String(), "invalid_reset_token")
+}
+
+func TestChangePasswordEndpoint(t *testing.T) {
+	r, repo := setupPasswordRouter(t)
+
+	w := webhookRequest(r, "PUT", "/api/users/1/password", map[string]string{"current_password": "wrong-horse-battery", "new_password": "new-horse-battery"})
+	assert.Equal(t, http.StatusBadRequest, w.Code, "A wrong current password is a validation error")
+	assert.Contains(t, w.Body.String(), "incorrect_password")
+	assert.Contains(t, w.Body.String(), `"field":"current_password"`)
+	assert.Equal(t, http.StatusBadRequest, webhookRequest(r, "PUT", "/api/users/1/password", map[string]string{"new_password": "new-horse-battery"}).Code,
+		"The current password is required")
+	assert.Equal(t, http.StatusNotFound, webhookRequest(r, "PUT", "/api/users/2/password",
+		map[string]string{"current_password": "correct-horse-battery", "new_password": "new-horse-battery"}).Code)
+
+	w = webhookRequest(r, "PUT", "/api/users/1/password", map[string]string{"current_password": "correct-horse-battery", "new_password": "new-horse-battery"})
+	var changed map[string]interface{}
+	decodeData(t, w, &changed)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	stored, err := repo.GetByID(context.Background(), 1)
+	require.NoError(t, err)
+	assert.NotContains(t, w.Body.String(), stored.PasswordHash, "Password hash must never be serialized")
+	ok, _, err := testutils.NewTestPasswordHasher().Verify(context.Background(), stored.PasswordHash, "new-horse-battery")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestChangePasswordIsSelfOnly(t *testing.T) {
+	hash, err := testutils.NewTestPasswordHasher().Hash(context.Background(), "correct-horse-battery")
+	require.NoError(t, err)
+	repo := testutils.NewFakeUserRepository(
+		models.User{Name: "Jane Doe", Email: "jane@example.com", PasswordHash: hash},
+		models.User{Name: "John Doe", Email: "john@example.com", PasswordHash: hash},
+	)
+	tokens := testutils.NewTestJWTManager()
+	h := handlers.NewPasswordHandler(services.NewPasswordService(repo, tokens,
+		services.PasswordServiceOptions{Passwords: testutils.NewTestPasswords()}))
+
+	r, _, _ := testutils.SetupTestRouter(false)
+	r.PUT("/api/users/:id/password", middleware.Auth(tokens), middleware.RequireSelf(), h.Change)
+
+	john, err := repo.GetByID(context.Background(), 2)
+	require.NoError(t, err)
+	token, _, err := tokens.GenerateAccessToken(john)
+	require.NoError(t, err)
+	change := func(path string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"current_password": "correct-horse-battery", "new_password": "new-horse-battery"})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := change("/api/users/1/password")
+	assert.Equal(t, http.StatusForbidden, w.Code, "Knowing another user's password is not enough")
+	assert.Contains(t, w.Body.String(), "not_self")
+	assert.Equal(t, http.StatusOK, change("/api/users/2/password").Code)
+}
@@ -0,0 +1,134 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/handlers"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/utils"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupPatchRouter() *gin.Engine {
+	repo := testutils.NewFakeUserRepository(
+		models.User{Name: "Jane Doe", Email: "jane@example.com"},
+		models.User{Name: "John Doe", Email: "john@example.com"},
+	)
+	userHandler := handlers.NewUserHandler(services.NewUserService(repo, testutils.NewTestPasswords()))
+
+	r, _, _ := testutils.SetupTestRouter(false)
+	r.PUT("/api/users/:id", userHandler.Update)
+	r.PATCH("/api/users/:id", userHandler.Patch)
+	return r
+}
+
+func sendPatch(r *gin.Engine, contentType, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/api/users/1", strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func patchedUser(t *testing.T, w *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response utils.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response.Data.(map[string]interface{})
+}
+
+func TestMergePatch(t *testing.T) {
+	r := setupPatchRouter()
+
+	user := patchedUser(t, sendPatch(r, handlers.MergePatchContentType, `{"name":"Jane Smith"}`))
+	assert.Equal(t, "Jane Smith", user["name"])
+	assert.Equal(t, "jane@example.com", user["email"], "Fields absent from a merge patch are kept")
+
+	t.Run("Null Clears A Field", func(t *testing.T) {
+		w := sendPatch(r, handlers.MergePatchContentType, `{"name":null}`)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		problem := decodeProblem(t, w)
+		require.Len(t, problem.Errors, 1)
+		assert.Equal(t, "name", problem.Errors[0].Field, "The patched user is validated like a PUT body")
+		assert.Equal(t, "required", problem.Errors[0].Code)
+	})
+
+	t.Run("Read-Only Fields", func(t *testing.T) {
+		w := sendPatch(r, handlers.MergePatchContentType, `{"id":99,"password_hash":"x"}`)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		problem := decodeProblem(t, w)
+		require.NotEmpty(t, problem.Errors)
+		assert.Equal(t, "unknown_field", problem.Errors[0].Code)
+	})
+
+	t.Run("Not An Object", func(t *testing.T) {
+		w := sendPatch(r, handlers.MergePatchContentType, `["name"]`)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "malformed_patch", decodeProblem(t, w).Code)
+	})
+
+	t.Run("Email Conflict", func(t *testing.T) {
+		w := sendPatch(r, handlers.MergePatchContentType, `{"email":"john@example.com"}`)
+		require.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, services.ErrEmailInUse.Code, decodeProblem(t, w).Code)
+	})
+}
+
+func TestJSONPatch(t *testing.T) {
+	r := setupPatchRouter()
+
+	user := patchedUser(t, sendPatch(r, handlers.JSONPatchContentType,
+		`[{"op":"test","path":"/email","value":"jane@example.com"},{"op":"replace","path":"/email","value":"jane.doe@example.com"}]`))
+	assert.Equal(t, "jane.doe@example.com", user["email"])
+	assert.Equal(t, "Jane Doe", user["name"])
+
+	t.Run("Failed Test Operation", func(t *testing.T) {
+		w := sendPatch(r, handlers.JSONPatchContentType,
+			`[{"op":"test","path":"/email","value":"jane@example.com"},{"op":"remove","path":"/name"}]`)
+		require.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, "patch_test_failed", decodeProblem(t, w).Code)
+	})
+
+	t.Run("Invalid Path", func(t *testing.T) {
+		w := sendPatch(r, handlers.JSONPatchContentType, `[{"op":"replace","path":"/nickname","value":"JD"}]`)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "malformed_patch", decodeProblem(t, w).Code)
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		w := sendPatch(r, handlers.JSONPatchContentType, `{"op":"replace"}`)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "malformed_patch", decodeProblem(t, w).Code)
+	})
+}
+
+func TestPatchUnsupportedMediaType(t *testing.T) {
+	r := setupPatchRouter()
+
+	w := sendPatch(r, "application/json", `{"name":"Jane Smith"}`)
+	require.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Contains(t, w.Header().Get("Accept-Patch"), handlers.MergePatchContentType)
+}
+
+func TestPutIsFullReplacement(t *testing.T) {
+	r := setupPatchRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/users/1", strings.NewReader(`{"name":"Jane Smith"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code, "PUT without every field is rejected rather than partially applied")
+	problem := decodeProblem(t, w)
+	require.Len(t, problem.Errors, 1)
+	assert.Equal(t, "email", problem.Errors[0].Field)
+}
@@ -0,0 +1,120 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/middleware"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/identity"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/ratelimit"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingStore struct{}
+
+func (failingStore) Allow(context.Context, string, ratelimit.Policy) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("store unavailable")
+}
+
+func rateLimitedRequest(r *gin.Engine, path string, headers ...string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", path, nil)
+	req.RemoteAddr = "203.0.113.7:4711"
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimit(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	policy := ratelimit.Policy{Limit: 2, Period: time.Minute}
+
+	r, _, _ := testutils.SetupTestRouter(false)
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	asUser := func(c *gin.Context) {
+		if id := c.GetHeader("X-Test-User"); id != "" {
+			ctx := identity.WithPrincipal(c.Request.Context(), &identity.Principal{UserID: uint(len(id))})
+			c.Request = c.Request.WithContext(ctx)
+		}
+	}
+	r.GET("/ip", middleware.RateLimit(store, "ip", policy, middleware.ByIP), ok)
+	r.GET("/key", middleware.RateLimit(store, "key", policy, middleware.ByAPIKey), ok)
+	r.GET("/user", asUser, middleware.RateLimit(store, "user", policy, middleware.ByUser), ok)
+	r.GET("/broken", middleware.RateLimit(failingStore{}, "broken", policy, middleware.ByIP), ok)
+
+	t.Run("Headers And 429", func(t *testing.T) {
+		w := rateLimitedRequest(r, "/ip")
+		require.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "2;w=60", w.Header().Get("RateLimit-Policy"))
+		assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "30", w.Header().Get("RateLimit-Reset"))
+
+		require.Equal(t, http.StatusNoContent, rateLimitedRequest(r, "/ip").Code)
+
+		w = rateLimitedRequest(r, "/ip")
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "30", w.Header().Get("Retry-After"))
+		assert.Equal(t, "rate_limited", decodeProblem(t, w).Code)
+	})
+
+	t.Run("Policies Are Scoped By Name", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, rateLimitedRequest(r, "/key").Code, "Another group has its own buckets")
+	})
+
+	t.Run("API Keys Get Their Own Bucket", func(t *testing.T) {
+		rateLimitedRequest(r, "/key")
+		assert.Equal(t, http.StatusTooManyRequests, rateLimitedRequest(r, "/key").Code)
+		assert.Equal(t, http.StatusNoContent, rateLimitedRequest(r, "/key", middleware.APIKeyHeader, "secret").Code)
+	})
+
+	t.Run("Users Get Their Own Bucket", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			require.Equal(t, http.StatusNoContent, rateLimitedRequest(r, "/user", "X-Test-User", "a").Code)
+		}
+		assert.Equal(t, http.StatusTooManyRequests, rateLimitedRequest(r, "/user", "X-Test-User", "a").Code)
+		assert.Equal(t, http.StatusNoContent, rateLimitedRequest(r, "/user", "X-Test-User", "bb").Code,
+			"Another user behind the same IP is not limited")
+	})
+
+	t.Run("Store Failure Fails Open", func(t *testing.T) {
+		w := rateLimitedRequest(r, "/broken")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+	})
+}
+
+func TestRateLimitIgnoresUntrustedForwardedFor(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	policy := ratelimit.Policy{Limit: 2, Period: time.Minute}
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+
+	r, _, _ := testutils.SetupTestRouter(false)
+	r.GET("/ip", middleware.RateLimit(store, "ip", policy, middleware.ByIP), ok)
+	for _, spoofed := range []string{"198.51.100.1", "198.51.100.2"} {
+		require.Equal(t, http.StatusNoContent, rateLimitedRequest(r, "/ip", "X-Forwarded-For", spoofed).Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, rateLimitedRequest(r, "/ip", "X-Forwarded-For", "198.51.100.3").Code,
+		"A new X-Forwarded-For does not give a new bucket")
+
+	// Behind a trusted proxy the forwarded address is the client
+	proxied := gin.New()
+	require.NoError(t, proxied.SetTrustedProxies([]string{"203.0.113.7"}))
+	proxied.Use(middleware.Errors())
+	proxied.GET("/ip", middleware.RateLimit(store, "proxied", policy, middleware.ByIP), ok)
+	for i := 0; i < 2; i++ {
+		require.Equal(t, http.StatusNoContent, rateLimitedRequest(proxied, "/ip", "X-Forwarded-For", "198.51.100.1").Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, rateLimitedRequest(proxied, "/ip", "X-Forwarded-For", "198.51.100.1").Code)
+	assert.Equal(t, http.StatusNoContent, rateLimitedRequest(proxied, "/ip", "X-Forwarded-For", "198.51.100.2").Code)
+}
@@ -0,0 +1,79 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/middleware"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/logger"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/requestid"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/utils"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func setupRequestIDRouter() (*gin.Engine, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	log := &logger.Logger{SugaredLogger: zap.New(core).Sugar()}
+
+	r, _, _ := testutils.SetupTestRouter(false)
+	r.Use(middleware.RequestID(log))
+	r.Use(middleware.Logger(log))
+	r.GET("/fail", func(c *gin.Context) {
+		logger.FromContext(c.Request.Context()).Info("Handler log line")
+		utils.ErrorResponse(c, http.StatusBadRequest, "Something went wrong")
+	})
+	return r, logs
+}
+
+func TestRequestIDGenerated(t *testing.T) {
+	r, logs := setupRequestIDRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/fail", nil)
+	r.ServeHTTP(w, req)
+
+	id := w.Header().Get(requestid.Header)
+	require.NotEmpty(t, id)
+	assert.True(t, requestid.IsValid(id))
+
+	var problem utils.Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, id, problem.RequestID, "Error responses should carry the request ID")
+
+	require.Equal(t, 2, logs.Len())
+	for _, entry := range logs.All() {
+		assert.Equal(t, id, entry.ContextMap()["request_id"], "Log line %q should carry the request ID", entry.Message)
+	}
+}
+
+func TestRequestIDPropagated(t *testing.T) {
+	r, _ := setupRequestIDRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/fail", nil)
+	req.Header.Set(requestid.Header, "upstream-trace-42")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, "upstream-trace-42", w.Header().Get(requestid.Header))
+}
+
+func TestRequestIDRejectsUnsafeValues(t *testing.T) {
+	r, _ := setupRequestIDRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/fail", nil)
+	req.Header.Set(requestid.Header, "bad id\twith\"quotes")
+	r.ServeHTTP(w, req)
+
+	id := w.Header().Get(requestid.Header)
+	assert.NotEqual(t, "bad id\twith\"quotes", id)
+	assert.True(t, requestid.IsValid(id))
+}
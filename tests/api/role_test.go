@@ -0,0 +1,115 @@
+package api_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/handlers"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/middleware"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleBasedAccess(t *testing.T) {
+	r, userService, db := testutils.SetupTestRouter(true)
+	if db == nil {
+		t.Fatal("Database connection required for role tests")
+	}
+	testutils.CleanupDatabase(db)
+	defer testutils.CleanupDatabase(db)
+
+	_, tokens := testutils.SetupTestAuth(db)
+	roleService := testutils.SetupTestRoles(db)
+	userHandler := handlers.NewUserHandler(userService)
+	roleHandler := handlers.NewRoleHandler(roleService)
+
+	users := r.Group("/api/users", middleware.Auth(tokens))
+	users.DELETE("/:id", middleware.RequirePermission(roleService, models.PermUsersDelete), userHandler.Delete)
+	users.GET("", middleware.RequirePermission(roleService, models.PermUsersRead),
+		middleware.RequirePermissionWhen(roleService, models.PermUsersDelete, handlers.RequestsDeletedUsers), userHandler.List)
+	users.GET("/:id", middleware.RequireSelfOrPermission(roleService, models.PermUsersRead), userHandler.Get)
+	users.POST("/:id/roles", middleware.RequirePermission(roleService, models.PermRolesAssign), roleHandler.Assign)
+
+	admin := models.User{Name: "Admin", Email: "admin@example.com"}
+	member := models.User{Name: "Member", Email: "member@example.com"}
+	victim := models.User{Name: "Victim", Email: "victim@example.com"}
+	require.NoError(t, db.Create(&admin).Error)
+	require.NoError(t, db.Create(&member).Error)
+	require.NoError(t, db.Create(&victim).Error)
+
+	var adminRole models.Role
+	require.NoError(t, db.Where("name = ?", models.RoleAdmin).First(&adminRole).Error)
+	require.NoError(t, db.Model(&admin).Association("Roles").Append(&adminRole))
+
+	bearer := func(u *models.User) string {
+		token, _, err := tokens.GenerateAccessToken(u)
+		require.NoError(t, err)
+		return "Bearer " + token
+	}
+
+	t.Run("Member Cannot Delete Others", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", fmt.Sprintf("/api/users/%d", victim.ID), nil)
+		req.Header.Set("Authorization", bearer(&member))
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Member Can Read Self But Not Others", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/users/%d", member.ID), nil)
+		req.Header.Set("Authorization", bearer(&member))
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", fmt.Sprintf("/api/users/%d", victim.ID), nil)
+		req.Header.Set("Authorization", bearer(&member))
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Admin Assigns Role", func(t *testing.T) {
+		w := postJSON(r, fmt.Sprintf("/api/users/%d/roles", member.ID), map[string]string{"role": models.RoleViewer}, "Authorization", bearer(&admin))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		// Viewer grants users:read, so the member can now read other users
+		w = httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/users/%d", victim.ID), nil)
+		req.Header.Set("Authorization", bearer(&member))
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Viewer Cannot List Deleted Users", func(t *testing.T) {
+		list := func(u *models.User, query string) int {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/api/users"+query, nil)
+			req.Header.Set("Authorization", bearer(u))
+			r.ServeHTTP(w, req)
+			return w.Code
+		}
+		assert.Equal(t, http.StatusOK, list(&member, ""))
+		assert.Equal(t, http.StatusOK, list(&member, "?deleted=exclude"))
+		assert.Equal(t, http.StatusForbidden, list(&member, "?deleted=include"))
+		assert.Equal(t, http.StatusForbidden, list(&member, "?deleted=exclude&deleted=only"), "The last value counts")
+		assert.Equal(t, http.StatusOK, list(&admin, "?deleted=only"))
+	})
+
+	t.Run("Assign Unknown Role", func(t *testing.T) {
+		w := postJSON(r, fmt.Sprintf("/api/users/%d/roles", member.ID), map[string]string{"role": "nope"}, "Authorization", bearer(&admin))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Admin Deletes User", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", fmt.Sprintf("/api/users/%d", victim.ID), nil)
+		req.Header.Set("Authorization", bearer(&admin))
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
@@ -0,0 +1,100 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/handlers"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/middleware"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/persistence"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// setupTestTracing installs an in-memory exporter as the global tracer
+// provider and restores the previous provider when the test ends.
+func setupTestTracing(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		_ = provider.Shutdown(context.Background())
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	return exporter
+}
+
+func spansByName(spans tracetest.SpanStubs) map[string]tracetest.SpanStub {
+	byName := map[string]tracetest.SpanStub{}
+	for _, s := range spans {
+		byName[s.Name] = s
+	}
+	return byName
+}
+
+func TestTracingServerAndServiceSpans(t *testing.T) {
+	exporter := setupTestTracing(t)
+
+	repo := testutils.NewFakeUserRepository(models.User{Name: "Traced User", Email: "traced@example.com"})
+	userHandler := handlers.NewUserHandler(services.NewUserService(repo, testutils.NewTestPasswords()))
+
+	r, _, _ := testutils.SetupTestRouter(false)
+	r.Use(middleware.Tracing())
+	r.GET("/api/users/:id", userHandler.Get)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/users/1", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	spans := spansByName(exporter.GetSpans())
+	server, ok := spans["GET /api/users/:id"]
+	require.True(t, ok, "Server span should be named after the route template")
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+	assert.Equal(t, traceID, server.SpanContext.TraceID().String(), "Server span should continue the incoming trace")
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+
+	service, ok := spans["UserService.GetUserByID"]
+	require.True(t, ok)
+	assert.Equal(t, server.SpanContext.SpanID(), service.Parent.SpanID(), "Service span should be a child of the server span")
+}
+
+func TestTracingRepositorySpans(t *testing.T) {
+	exporter := setupTestTracing(t)
+
+	// A dry run builds statements without a database, which is enough to exercise span creation
+	db := testutils.NewDryRunDB()
+	userHandler := handlers.NewUserHandler(services.NewUserService(persistence.NewGormUserRepository(db), testutils.NewTestPasswords()))
+
+	r, _, _ := testutils.SetupTestRouter(false)
+	r.Use(middleware.Tracing())
+	r.GET("/api/users/:id", userHandler.Get)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/users/1", nil)
+	r.ServeHTTP(w, req)
+
+	spans := spansByName(exporter.GetSpans())
+	service, ok := spans["UserService.GetUserByID"]
+	require.True(t, ok)
+	repo, ok := spans["GormUserRepository.GetByID"]
+	require.True(t, ok)
+	assert.Equal(t, trace.SpanKindClient, repo.SpanKind)
+	assert.Equal(t, service.SpanContext.SpanID(), repo.Parent.SpanID(), "Repository span should be a child of the service span")
+}
@@ -0,0 +1,96 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/handlers"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTrashRouter() *gin.Engine {
+	repo := testutils.NewFakeUserRepository(
+		models.User{Name: "Jane Doe", Email: "jane@example.com"},
+		models.User{Name: "John Roe", Email: "john@example.com"},
+	)
+	userHandler := handlers.NewUserHandler(services.NewUserService(repo, testutils.NewTestPasswords()))
+
+	r, _, _ := testutils.SetupTestRouter(false)
+	r.GET("/api/users", userHandler.List)
+	r.POST("/api/users", userHandler.Create)
+	r.GET("/api/users/:id", userHandler.Get)
+	r.DELETE("/api/users/:id", userHandler.Delete)
+	r.GET("/api/users/deleted", userHandler.ListDeleted)
+	r.POST("/api/users/deleted/:id/restore", userHandler.Restore)
+	r.DELETE("/api/users/deleted/:id", userHandler.Purge)
+	return r
+}
+
+func trashRequest(r *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, nil)
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func listedEmails(t *testing.T, w *httptest.ResponseRecorder) []string {
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data struct {
+			Users []models.User `json:"users"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	emails := []string{}
+	for _, u := range response.Data.Users {
+		emails = append(emails, u.Email)
+	}
+	return emails
+}
+
+func TestUserTrash(t *testing.T) {
+	r := setupTrashRouter()
+	require.Equal(t, http.StatusOK, trashRequest(r, "DELETE", "/api/users/1").Code)
+
+	t.Run("Deleted Users Are Listed Separately", func(t *testing.T) {
+		assert.Equal(t, []string{"john@example.com"}, listedEmails(t, trashRequest(r, "GET", "/api/users")))
+		assert.Equal(t, []string{"jane@example.com"}, listedEmails(t, trashRequest(r, "GET", "/api/users/deleted")))
+		assert.Equal(t, http.StatusNotFound, trashRequest(r, "GET", "/api/users/1").Code)
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		w := trashRequest(r, "POST", "/api/users/deleted/1/restore")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"2"`, w.Header().Get("ETag"), "Restoring should bump the version")
+		assert.Equal(t, http.StatusOK, trashRequest(r, "GET", "/api/users/1").Code)
+
+		w = trashRequest(r, "POST", "/api/users/deleted/1/restore")
+		assert.Equal(t, http.StatusNotFound, w.Code, "A live user is not in the trash")
+		assert.Equal(t, "deleted_user_not_found", decodeProblem(t, w).Code)
+	})
+
+	t.Run("Restore Fails When Email Was Reused", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, trashRequest(r, "DELETE", "/api/users/2").Code)
+		w := postJSON(r, "/api/users", map[string]string{"name": "New John", "email": "john@example.com", "password": "correct-horse-battery"})
+		require.Equal(t, http.StatusOK, w.Code, "A deleted user's email can be registered again")
+
+		w = trashRequest(r, "POST", "/api/users/deleted/2/restore")
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, "email_in_use", decodeProblem(t, w).Code)
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, trashRequest(r, "DELETE", "/api/users/deleted/1").Code, "Only deleted users can be purged")
+
+		require.Equal(t, http.StatusOK, trashRequest(r, "DELETE", "/api/users/deleted/2").Code)
+		assert.Empty(t, listedEmails(t, trashRequest(r, "GET", "/api/users/deleted")))
+		assert.Equal(t, http.StatusNotFound, trashRequest(r, "POST", "/api/users/deleted/2/restore").Code)
+	})
+}
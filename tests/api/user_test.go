@@ -221,11 +221,13 @@ func TestCreateUser_Conflict(t *testing.T) {
 
 	assert.Equal(t, http.StatusConflict, wConflict.Code)
 
-	var response utils.Response
-	err := json.Unmarshal(wConflict.Body.Bytes(), &response)
+	assert.Equal(t, utils.ProblemContentType, wConflict.Header().Get("Content-Type"))
+	var problem utils.Problem
+	err := json.Unmarshal(wConflict.Body.Bytes(), &problem)
 	assert.NoError(t, err)
-	assert.False(t, response.Success)
-	assert.Equal(t, services.ErrUserEmailExists.Error(), response.Message)
+	assert.Equal(t, http.StatusConflict, problem.Status)
+	assert.Equal(t, services.ErrUserEmailExists.Code, problem.Code)
+	assert.Equal(t, services.ErrUserEmailExists.Message, problem.Detail)
 }
 
 func TestGetUser_NotFound(t *testing.T) {
@@ -244,9 +246,9 @@ func TestGetUser_NotFound(t *testing.T) {
 
     assert.Equal(t, http.StatusNotFound, w.Code)
 
-    var response utils.Response
-    err := json.Unmarshal(w.Body.Bytes(), &response)
+    var problem utils.Problem
+    err := json.Unmarshal(w.Body.Bytes(), &problem)
     assert.NoError(t, err)
-    assert.False(t, response.Success)
-    assert.Equal(t, services.ErrUserNotFound.Error(), response.Message) // Check specific error message
+    assert.Equal(t, services.ErrUserNotFound.Code, problem.Code) // Check specific error code
+    assert.Equal(t, services.ErrUserNotFound.Message, problem.Detail)
 }
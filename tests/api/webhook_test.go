@@ -0,0 +1,167 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/handlers"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/events"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupWebhookRouter() (*gin.Engine, services.WebhookService, *testutils.FakeWebhookRepository) {
+	repo := testutils.NewFakeWebhookRepository()
+	svc := services.NewWebhookService(repo, testutils.NewTestWebhookAddresses())
+	h := handlers.NewWebhookHandler(svc)
+
+	r, _, _ := testutils.SetupTestRouter(false)
+	r.POST("/api/webhooks", h.Create)
+	r.GET("/api/webhooks", h.List)
+	r.GET("/api/webhooks/:id", h.Get)
+	r.PUT("/api/webhooks/:id", h.Update)
+	r.DELETE("/api/webhooks/:id", h.Delete)
+	r.GET("/api/webhooks/:id/deliveries", h.ListDeliveries)
+	r.POST("/api/webhooks/:id/deliveries/:delivery_id/redeliver", h.Redeliver)
+	return r, svc, repo
+}
+
+func webhookRequest(r *gin.Engine, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decodeData(t *testing.T, w *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &struct {
+		Data interface{} `json:"data"`
+	}{Data: v}))
+}
+
+func TestWebhookCRUD(t *testing.T) {
+	r, _, _ := setupWebhookRouter()
+
+	w := webhookRequest(r, "POST", "/api/webhooks", map[string]interface{}{
+		"url":         "https://partner.example.com/hooks",
+		"event_types": []string{events.TypeUserCreated},
+		"description": "Partner sync",
+	})
+	var created map[string]interface{}
+	decodeData(t, w, &created)
+	assert.Regexp(t, `^whsec_`, created["secret"], "The secret is returned on create")
+	assert.Equal(t, true, created["active"], "Subscriptions are active by default")
+	id := created["id"]
+	require.Equal(t, float64(1), id)
+
+	var fetched map[string]interface{}
+	decodeData(t, webhookRequest(r, "GET", "/api/webhooks/1", nil), &fetched)
+	assert.Equal(t, "Partner sync", fetched["description"])
+	assert.NotContains(t, fetched, "secret", "The secret is never returned again")
+
+	var updated models.WebhookSubscription
+	decodeData(t, webhookRequest(r, "PUT", "/api/webhooks/1", map[string]interface{}{
+		"url":         "https://partner.example.com/v2/hooks",
+		"event_types": []string{"*"},
+		"active":      false,
+	}), &updated)
+	assert.Equal(t, "https://partner.example.com/v2/hooks", updated.URL)
+	assert.Equal(t, []string{"*"}, updated.EventTypes)
+	assert.False(t, updated.Active)
+	assert.Empty(t, updated.Description, "PUT replaces every setting")
+
+	var list []map[string]interface{}
+	decodeData(t, webhookRequest(r, "GET", "/api/webhooks", nil), &list)
+	require.Len(t, list, 1)
+	assert.NotContains(t, list[0], "secret")
+
+	assert.Equal(t, http.StatusOK, webhookRequest(r, "DELETE", "/api/webhooks/1", nil).Code)
+	w = webhookRequest(r, "GET", "/api/webhooks/1", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "webhook_not_found", decodeProblem(t, w).Code)
+}
+
+func TestWebhookValidation(t *testing.T) {
+	r, _, _ := setupWebhookRouter()
+
+	w := webhookRequest(r, "POST", "/api/webhooks", map[string]interface{}{"url": "https://example.com"})
+	assert.Equal(t, http.StatusBadRequest, w.Code, "event_types is required")
+
+	w = webhookRequest(r, "POST", "/api/webhooks", map[string]interface{}{
+		"url":         "javascript:alert(1)",
+		"event_types": []string{"user.renamed"},
+	})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	problem := decodeProblem(t, w)
+	require.Len(t, problem.Errors, 2)
+	assert.Equal(t, "url", problem.Errors[0].Field)
+	assert.Equal(t, "event_types", problem.Errors[1].Field)
+
+	w = webhookRequest(r, "GET", "/api/webhooks/abc", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "invalid_webhook_id", decodeProblem(t, w).Code)
+}
+
+func TestWebhookDeliveryLogAndRedelivery(t *testing.T) {
+	r, svc, repo := setupWebhookRouter()
+	ctx := context.Background()
+
+	_, err := svc.CreateSubscription(ctx, services.WebhookInput{
+		URL: "https://partner.example.com/hooks", EventTypes: []string{"*"}, Active: true,
+	})
+	require.NoError(t, err)
+	for _, id := range []string{"00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000002"} {
+		require.NoError(t, svc.Enqueue(ctx, events.Envelope{ID: id, Type: events.TypeUserDeleted, Payload: []byte(`{}`)}))
+	}
+	d := repo.Deliveries()[0]
+	d.Status, d.Attempts, d.LastError = models.WebhookDeliveryDead, 10, "receiver responded with 500 Internal Server Error"
+	require.NoError(t, repo.UpdateDelivery(ctx, &d))
+
+	var page struct {
+		Deliveries []models.WebhookDelivery `json:"deliveries"`
+		Pagination struct {
+			HasMore    bool `json:"has_more"`
+			NextBefore uint `json:"next_before"`
+		} `json:"pagination"`
+	}
+	w := webhookRequest(r, "GET", "/api/webhooks/1/deliveries?limit=1", nil)
+	decodeData(t, w, &page)
+	require.Len(t, page.Deliveries, 1)
+	assert.Equal(t, uint(2), page.Deliveries[0].ID)
+	assert.True(t, page.Pagination.HasMore)
+	assert.Equal(t, `</api/webhooks/1/deliveries?before=2&limit=1>; rel="next"`, w.Header().Get("Link"))
+
+	decodeData(t, webhookRequest(r, "GET", "/api/webhooks/1/deliveries?status=dead", nil), &page)
+	require.Len(t, page.Deliveries, 1)
+	assert.Equal(t, d.ID, page.Deliveries[0].ID)
+	assert.Equal(t, 10, page.Deliveries[0].Attempts)
+	assert.Contains(t, page.Deliveries[0].LastError, "500")
+
+	var redelivered models.WebhookDelivery
+	decodeData(t, webhookRequest(r, "POST", "/api/webhooks/1/deliveries/1/redeliver", nil), &redelivered)
+	assert.Equal(t, models.WebhookDeliveryPending, redelivered.Status)
+	assert.Zero(t, redelivered.Attempts)
+	assert.Equal(t, models.WebhookDeliveryPending, repo.Deliveries()[0].Status)
+
+	w = webhookRequest(r, "POST", "/api/webhooks/1/deliveries/9/redeliver", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "webhook_delivery_not_found", decodeProblem(t, w).Code)
+
+	w = webhookRequest(r, "GET", "/api/webhooks/1/deliveries?status=failed", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,148 @@
+package auth_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/config"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newHasher(t *testing.T, algorithm string, tweak ...func(*config.Config)) *auth.PasswordHasher {
+	t.Helper()
+	cfg := &config.Config{
+		PasswordHashAlgorithm:     algorithm,
+		PasswordBcryptCost:        4,
+		PasswordHashConcurrency:   2,
+		PasswordArgon2Memory:      64,
+		PasswordArgon2Iterations:  1,
+		PasswordArgon2Parallelism: 1,
+	}
+	for _, f := range tweak {
+		f(cfg)
+	}
+	h, err := auth.NewPasswordHasher(cfg)
+	require.NoError(t, err)
+	return h
+}
+
+// verify is Verify with a live context, which never fails.
+func verify(t *testing.T, h *auth.PasswordHasher, hash, password string) (ok, rehash bool) {
+	t.Helper()
+	ok, rehash, err := h.Verify(context.Background(), hash, password)
+	require.NoError(t, err)
+	return ok, rehash
+}
+
+func TestPasswordHasherRoundTrip(t *testing.T) {
+	for _, algorithm := range []string{auth.Argon2id, auth.Bcrypt} {
+		t.Run(algorithm, func(t *testing.T) {
+			h := newHasher(t, algorithm)
+			hash, err := h.Hash(context.Background(), "correct-horse-battery")
+			require.NoError(t, err)
+			assert.NotContains(t, hash, "correct-horse-battery")
+
+			ok, rehash := verify(t, h, hash, "correct-horse-battery")
+			assert.True(t, ok)
+			assert.False(t, rehash, "A hash made with the current settings is kept")
+			ok, _ = verify(t, h, hash, "wrong-horse-battery")
+			assert.False(t, ok)
+
+			again, err := h.Hash(context.Background(), "correct-horse-battery")
+			require.NoError(t, err)
+			assert.NotEqual(t, hash, again, "Every hash is salted")
+		})
+	}
+}
+
+func TestArgon2idHashFormat(t *testing.T) {
+	hash, err := newHasher(t, auth.Argon2id).Hash(context.Background(), "correct-horse-battery")
+	require.NoError(t, err)
+	assert.Regexp(t, `^\$argon2id\$v=19\$m=64,t=1,p=1\$[A-Za-z0-9+/]{22}\$[A-Za-z0-9+/]{43}$`, hash)
+}
+
+func TestPasswordHasherRequestsRehash(t *testing.T) {
+	argon2Hash, err := newHasher(t, auth.Argon2id).Hash(context.Background(), "correct-horse-battery")
+	require.NoError(t, err)
+	bcryptHash, err := newHasher(t, auth.Bcrypt).Hash(context.Background(), "correct-horse-battery")
+	require.NoError(t, err)
+
+	stronger := newHasher(t, auth.Argon2id, func(c *config.Config) { c.PasswordArgon2Iterations = 2 })
+	ok, rehash := verify(t, stronger, argon2Hash, "correct-horse-battery")
+	assert.True(t, ok)
+	assert.True(t, rehash, "Changed argon2id parameters")
+
+	ok, rehash = verify(t, stronger, bcryptHash, "correct-horse-battery")
+	assert.True(t, ok, "Hashes of the other algorithm still verify")
+	assert.True(t, rehash, "Changed algorithm")
+
+	costlier := newHasher(t, auth.Bcrypt, func(c *config.Config) { c.PasswordBcryptCost = 5 })
+	_, rehash = verify(t, costlier, bcryptHash, "correct-horse-battery")
+	assert.True(t, rehash, "Changed bcrypt cost")
+
+	ok, rehash = verify(t, stronger, argon2Hash, "wrong-horse-battery")
+	assert.False(t, ok)
+	assert.False(t, rehash, "Only a verified password is rehashed")
+}
+
+func TestPasswordHasherRejectsMalformedHashes(t *testing.T) {
+	h := newHasher(t, auth.Argon2id)
+	for _, hash := range []string{
+		"",
+		"correct-horse-battery",
+		"$argon2id$v=19$m=64,t=1,p=1$c2FsdA",
+		"$argon2id$v=18$m=64,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$a2V5",
+		"$argon2id$v=19$m=64,t=0,p=1$c2FsdHNhbHRzYWx0c2FsdA$a2V5",
+		"$argon2id$v=19$m=64,t=1,p=1$not base64!$a2V5",
+		"$argon2id$v=19$m=64,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$",
+	} {
+		ok, _ := verify(t, h, hash, "correct-horse-battery")
+		assert.False(t, ok, hash)
+	}
+}
+
+func TestPasswordHasherLimitsLength(t *testing.T) {
+	bcryptHasher := newHasher(t, auth.Bcrypt)
+	assert.Equal(t, 72, bcryptHasher.MaxLength(), "bcrypt ignores bytes past 72")
+	_, err := bcryptHasher.Hash(context.Background(), strings.Repeat("a", 73))
+	assert.Error(t, err)
+
+	argon2Hasher := newHasher(t, auth.Argon2id)
+	_, err = argon2Hasher.Hash(context.Background(), strings.Repeat("a", 73))
+	assert.NoError(t, err)
+	_, err = argon2Hasher.Hash(context.Background(), strings.Repeat("a", argon2Hasher.MaxLength()+1))
+	assert.Error(t, err)
+}
+
+func TestPasswordHasherHonorsContext(t *testing.T) {
+	h := newHasher(t, auth.Argon2id)
+	hash, err := h.Hash(context.Background(), "correct-horse-battery")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = h.Hash(ctx, "correct-horse-battery")
+	assert.ErrorIs(t, err, context.Canceled)
+	ok, _, err := h.Verify(ctx, hash, "correct-horse-battery")
+	assert.ErrorIs(t, err, context.Canceled, "A cancelled request does not wait for a hashing slot")
+	assert.False(t, ok)
+}
+
+func TestNewPasswordHasherValidatesConfig(t *testing.T) {
+	for name, cfg := range map[string]config.Config{
+		"unknown algorithm":  {PasswordHashAlgorithm: "md5", PasswordHashConcurrency: 1},
+		"bcrypt cost":        {PasswordHashAlgorithm: auth.Bcrypt, PasswordBcryptCost: 2, PasswordHashConcurrency: 1},
+		"argon2 iterations":  {PasswordHashAlgorithm: auth.Argon2id, PasswordArgon2Memory: 64, PasswordArgon2Parallelism: 1, PasswordHashConcurrency: 1},
+		"argon2 memory":      {PasswordHashAlgorithm: auth.Argon2id, PasswordArgon2Memory: 4, PasswordArgon2Iterations: 1, PasswordArgon2Parallelism: 1, PasswordHashConcurrency: 1},
+		"argon2 parallelism": {PasswordHashAlgorithm: auth.Argon2id, PasswordArgon2Memory: 64, PasswordArgon2Iterations: 1, PasswordHashConcurrency: 1},
+		"concurrency":        {PasswordHashAlgorithm: auth.Bcrypt, PasswordBcryptCost: 4},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := auth.NewPasswordHasher(&cfg)
+			assert.Error(t, err)
+		})
+	}
+}
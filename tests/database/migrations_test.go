@@ -0,0 +1,59 @@
+package database_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/database"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedMigrationsAreValid(t *testing.T) {
+	migs, err := database.LoadMigrations(migrations.FS)
+	require.NoError(t, err)
+	require.NotEmpty(t, migs)
+
+	for i, m := range migs {
+		assert.NotEmpty(t, m.UpSQL, "migration %d_%s needs an up script", m.Version, m.Name)
+		assert.NotEmpty(t, m.DownSQL, "migration %d_%s needs a down script", m.Version, m.Name)
+		if i > 0 {
+			assert.Greater(t, m.Version, migs[i-1].Version, "migrations must be sorted by version")
+		}
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	t.Run("Sorted With Optional Down", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"000002_second.up.sql":  {Data: []byte("SELECT 2")},
+			"000001_first.up.sql":   {Data: []byte("SELECT 1")},
+			"000001_first.down.sql": {Data: []byte("SELECT -1")},
+			"README.md":             {Data: []byte("ignored")},
+		}
+		migs, err := database.LoadMigrations(fsys)
+		require.NoError(t, err)
+		require.Len(t, migs, 2)
+		assert.Equal(t, int64(1), migs[0].Version)
+		assert.Equal(t, "first", migs[0].Name)
+		assert.Equal(t, "SELECT -1", migs[0].DownSQL)
+		assert.Equal(t, "second", migs[1].Name)
+		assert.Empty(t, migs[1].DownSQL)
+	})
+
+	t.Run("Missing Up File", func(t *testing.T) {
+		fsys := fstest.MapFS{"000001_first.down.sql": {Data: []byte("SELECT 1")}}
+		_, err := database.LoadMigrations(fsys)
+		assert.Error(t, err)
+	})
+
+	t.Run("Duplicate Version", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"000001_first.up.sql": {Data: []byte("SELECT 1")},
+			"000001_other.up.sql": {Data: []byte("SELECT 1")},
+		}
+		_, err := database.LoadMigrations(fsys)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,124 @@
+package events_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/events"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/publishers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func envelope(t *testing.T, id string, e events.Event) events.Envelope {
+	payload, err := json.Marshal(e)
+	require.NoError(t, err)
+	return events.Envelope{
+		ID:            id,
+		Type:          e.EventType(),
+		AggregateType: events.AggregateUser,
+		AggregateID:   e.AggregateID(),
+		OccurredAt:    time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Payload:       payload,
+	}
+}
+
+func TestBusDispatchesBySubscription(t *testing.T) {
+	bus := events.NewBus()
+	var created, all []string
+	bus.Subscribe(events.TypeUserCreated, func(ctx context.Context, env events.Envelope) error {
+		created = append(created, env.ID)
+		return nil
+	})
+	bus.Subscribe(events.AllEvents, func(ctx context.Context, env events.Envelope) error {
+		all = append(all, env.ID)
+		return nil
+	})
+
+	ctx := context.Background()
+	require.NoError(t, bus.Publish(ctx, envelope(t, "1", events.UserCreated{UserID: 1})))
+	require.NoError(t, bus.Publish(ctx, envelope(t, "2", events.UserDeleted{UserID: 1})))
+
+	assert.Equal(t, []string{"1"}, created)
+	assert.Equal(t, []string{"1", "2"}, all)
+}
+
+func TestBusRunsEveryHandlerAndJoinsErrors(t *testing.T) {
+	bus := events.NewBus()
+	errFirst := errors.New("first failed")
+	calledSecond := false
+	bus.Subscribe(events.TypeUserCreated, func(ctx context.Context, env events.Envelope) error { return errFirst })
+	bus.Subscribe(events.TypeUserCreated, func(ctx context.Context, env events.Envelope) error {
+		calledSecond = true
+		return nil
+	})
+
+	err := bus.Publish(context.Background(), envelope(t, "1", events.UserCreated{UserID: 1}))
+	assert.ErrorIs(t, err, errFirst)
+	assert.True(t, calledSecond, "A failing handler does not starve the others")
+}
+
+func TestFanoutPublishesToAll(t *testing.T) {
+	var buf bytes.Buffer
+	bus := events.NewBus()
+	busCalls := 0
+	bus.Subscribe(events.AllEvents, func(ctx context.Context, env events.Envelope) error {
+		busCalls++
+		return nil
+	})
+
+	publisher := events.Fanout(bus, publishers.NewNDJSONPublisher(&buf))
+	require.NoError(t, publisher.Publish(context.Background(), envelope(t, "1", events.UserCreated{UserID: 1})))
+	assert.Equal(t, 1, busCalls)
+	assert.Equal(t, 1, bytes.Count(buf.Bytes(), []byte("\n")))
+}
+
+func TestDecode(t *testing.T) {
+	e, err := events.Decode(envelope(t, "1", events.UserEmailChanged{UserID: 7, OldEmail: "a@example.com", NewEmail: "b@example.com"}))
+	require.NoError(t, err)
+	assert.Equal(t, &events.UserEmailChanged{UserID: 7, OldEmail: "a@example.com", NewEmail: "b@example.com"}, e)
+
+	_, err = events.Decode(events.Envelope{Type: "user.renamed"})
+	assert.Error(t, err)
+}
+
+func TestNDJSONFilePublisher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	publisher, f, err := publishers.OpenNDJSONFile(path)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, publisher.Publish(ctx, envelope(t, "1", events.UserCreated{UserID: 1, Name: "Jane", Email: "jane@example.com"})))
+	require.NoError(t, publisher.Publish(ctx, envelope(t, "2", events.UserDeleted{UserID: 1, Email: "jane@example.com"})))
+	require.NoError(t, f.Close())
+
+	// Reopening appends rather than truncates
+	publisher, f, err = publishers.OpenNDJSONFile(path)
+	require.NoError(t, err)
+	require.NoError(t, publisher.Publish(ctx, envelope(t, "3", events.UserCreated{UserID: 2})))
+	require.NoError(t, f.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	var got []events.Envelope
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var env events.Envelope
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &env))
+		got = append(got, env)
+	}
+	require.Len(t, got, 3)
+	assert.Equal(t, "2", got[1].ID)
+	assert.Equal(t, events.TypeUserDeleted, got[1].Type)
+	deleted, err := events.Decode(got[1])
+	require.NoError(t, err)
+	assert.Equal(t, "jane@example.com", deleted.(*events.UserDeleted).Email)
+}
@@ -0,0 +1,114 @@
+package idempotency_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/idempotency"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupPostgresStore(t *testing.T) *gorm.DB {
+	t.Helper()
+	_, _, db := testutils.SetupTestRouter(true)
+	if db == nil {
+		t.Fatal("Database connection required for idempotency tests")
+	}
+	testutils.CleanupDatabase(db)
+	t.Cleanup(func() { testutils.CleanupDatabase(db) })
+	return db
+}
+
+func TestPostgresStoreLock(t *testing.T) {
+	store := idempotency.NewPostgresStore(setupPostgresStore(t), time.Hour, time.Minute)
+	ctx := context.Background()
+
+	lease, rec, err := store.Lock(ctx, "user:1:create", "fp-1")
+	require.NoError(t, err)
+	require.NotNil(t, lease, "A new key is owned by the caller")
+	assert.Nil(t, rec)
+
+	again, rec, err := store.Lock(ctx, "user:1:create", "fp-1")
+	require.NoError(t, err)
+	assert.Nil(t, again, "A locked key is not claimed twice")
+	require.NotNil(t, rec)
+	assert.False(t, rec.Completed())
+	assert.Equal(t, "fp-1", rec.Fingerprint)
+
+	require.NoError(t, store.Complete(ctx, lease, idempotency.Record{
+		Status: http.StatusCreated,
+		Header: http.Header{"Content-Type": {"application/json"}},
+		Body:   []byte(`{"id":1}`),
+	}))
+	_, rec, err = store.Lock(ctx, "user:1:create", "fp-2")
+	require.NoError(t, err)
+	require.NotNil(t, rec)
+	assert.Equal(t, http.StatusCreated, rec.Status)
+	assert.Equal(t, "application/json", rec.Header.Get("Content-Type"))
+	assert.Equal(t, `{"id":1}`, string(rec.Body))
+	assert.Equal(t, "fp-1", rec.Fingerprint, "The stored fingerprint is the first request's")
+
+	assert.ErrorIs(t, store.Release(ctx, lease), idempotency.ErrLockLost, "Completed keys are not released")
+	_, rec, err = store.Lock(ctx, "user:1:create", "fp-1")
+	require.NoError(t, err)
+	assert.NotNil(t, rec)
+
+	lease, _, err = store.Lock(ctx, "user:1:update", "fp-1")
+	require.NoError(t, err)
+	require.NoError(t, store.Release(ctx, lease))
+	lease, _, err = store.Lock(ctx, "user:1:update", "fp-1")
+	require.NoError(t, err)
+	assert.NotNil(t, lease, "A released key can be claimed again")
+}
+
+func TestPostgresStoreReclaimsAbandonedAndExpiredKeys(t *testing.T) {
+	db := setupPostgresStore(t)
+	ctx := context.Background()
+
+	// Every lock counts as abandoned as soon as it is taken
+	abandoning := idempotency.NewPostgresStore(db, time.Hour, 0)
+	first, _, err := abandoning.Lock(ctx, "abandoned", "fp-1")
+	require.NoError(t, err)
+	second, _, err := abandoning.Lock(ctx, "abandoned", "fp-1")
+	require.NoError(t, err)
+	require.NotNil(t, second, "An abandoned lock is reclaimed")
+	assert.ErrorIs(t, abandoning.Complete(ctx, first, idempotency.Record{Status: http.StatusOK}), idempotency.ErrLockLost,
+		"The request that lost the lock cannot complete the key")
+	assert.ErrorIs(t, abandoning.Release(ctx, first), idempotency.ErrLockLost)
+	require.NoError(t, abandoning.Complete(ctx, second, idempotency.Record{Status: http.StatusCreated}))
+
+	// Keys expire as soon as they are stored
+	expiring := idempotency.NewPostgresStore(db, 0, time.Hour)
+	lease, _, err := expiring.Lock(ctx, "expired", "fp-1")
+	require.NoError(t, err)
+	require.NoError(t, expiring.Complete(ctx, lease, idempotency.Record{Status: http.StatusOK}))
+	lease, _, err = expiring.Lock(ctx, "expired", "fp-2")
+	require.NoError(t, err)
+	assert.NotNil(t, lease, "An expired key is reclaimed")
+}
+
+func TestPostgresStoreConcurrentLocks(t *testing.T) {
+	store := idempotency.NewPostgresStore(setupPostgresStore(t), time.Hour, time.Minute)
+
+	var wg sync.WaitGroup
+	var owners atomic.Int32
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lease, _, err := store.Lock(context.Background(), "contended", "fp-1")
+			if assert.NoError(t, err) && lease != nil {
+				owners.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), owners.Load(), "Exactly one request owns a key")
+}
@@ -0,0 +1,104 @@
+package jobs_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/jobs"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type greetArgs struct {
+	Name string `json:"name"`
+}
+
+func (greetArgs) Kind() string { return "test.greet" }
+
+func TestNewJobDefaults(t *testing.T) {
+	before := time.Now()
+	job, err := jobs.NewJob(greetArgs{Name: "Ada"}, jobs.Options{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "test.greet", job.Kind)
+	assert.JSONEq(t, `{"name":"Ada"}`, string(job.Args))
+	assert.Equal(t, jobs.DefaultQueue, job.Queue)
+	assert.Equal(t, jobs.StateAvailable, job.State)
+	assert.Equal(t, jobs.DefaultMaxAttempts, job.MaxAttempts)
+	assert.False(t, job.RunAt.Before(before), "Jobs run as soon as possible by default")
+	assert.Nil(t, job.UniqueKey)
+}
+
+func TestNewJobScheduling(t *testing.T) {
+	at := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	job, err := jobs.NewJob(greetArgs{}, jobs.Options{Queue: "mail", RunAt: at, MaxAttempts: 3, UniqueKey: "greet:ada"})
+	require.NoError(t, err)
+	assert.Equal(t, "mail", job.Queue)
+	assert.Equal(t, at, job.RunAt)
+	assert.Equal(t, 3, job.MaxAttempts)
+	require.NotNil(t, job.UniqueKey)
+	assert.Equal(t, "greet:ada", *job.UniqueKey)
+
+	job, err = jobs.NewJob(greetArgs{}, jobs.Options{RunAt: at, Delay: time.Hour})
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), job.RunAt, time.Minute, "Delay takes precedence over RunAt")
+}
+
+func TestEnqueueSkipsDuplicateUniqueJobs(t *testing.T) {
+	store := testutils.NewFakeJobStore()
+	ctx := context.Background()
+	opts := jobs.Options{UniqueKey: "greet"}
+
+	first, err := jobs.Enqueue(ctx, store, greetArgs{Name: "Ada"}, opts)
+	require.NoError(t, err)
+	require.NotNil(t, first)
+
+	dup, err := jobs.Enqueue(ctx, store, greetArgs{Name: "Grace"}, opts)
+	require.NoError(t, err)
+	assert.Nil(t, dup, "A unique job is skipped while another with its key waits")
+	assert.Len(t, store.Jobs(), 1)
+
+	// Once the first job has finished the key is free again
+	claimed, err := store.Claim(ctx, "w1", []string{jobs.DefaultQueue}, 1, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, store.Complete(ctx, &claimed[0]))
+	again, err := jobs.Enqueue(ctx, store, greetArgs{Name: "Grace"}, opts)
+	require.NoError(t, err)
+	assert.NotNil(t, again)
+}
+
+func TestRegistryDecodesArgs(t *testing.T) {
+	registry := jobs.NewRegistry()
+	var got greetArgs
+	jobs.Register(registry, func(ctx context.Context, job *jobs.Job, args greetArgs) error {
+		got = args
+		return nil
+	})
+	assert.Equal(t, []string{"test.greet"}, registry.Kinds())
+	assert.Panics(t, func() {
+		jobs.Register(registry, func(ctx context.Context, job *jobs.Job, args greetArgs) error { return nil })
+	}, "Registering a kind twice is a programming error")
+
+	handler, ok := registry.Handler("test.greet")
+	require.True(t, ok)
+	require.NoError(t, handler(context.Background(), &jobs.Job{Args: json.RawMessage(`{"name":"Ada"}`)}))
+	assert.Equal(t, "Ada", got.Name)
+
+	err := handler(context.Background(), &jobs.Job{Args: json.RawMessage(`[1]`)})
+	assert.True(t, jobs.IsPermanent(err), "Undecodable arguments are never worth retrying")
+
+	_, ok = registry.Handler("test.unknown")
+	assert.False(t, ok)
+}
+
+func TestPermanent(t *testing.T) {
+	cause := errors.New("bad input")
+	err := jobs.Permanent(cause)
+	assert.True(t, jobs.IsPermanent(err))
+	assert.ErrorIs(t, err, cause)
+	assert.False(t, jobs.IsPermanent(cause))
+}
@@ -0,0 +1,330 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/metrics"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/jobs"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/jobs/runner"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newWorker returns a worker named w1 running greet jobs with handler
+// against a fresh fake store.
+func newWorker(handler func(ctx context.Context, job *jobs.Job, args greetArgs) error) (*runner.Worker, *testutils.FakeJobStore, *metrics.Metrics) {
+	store := testutils.NewFakeJobStore()
+	registry := jobs.NewRegistry()
+	jobs.Register(registry, handler)
+	m := metrics.New()
+	return runner.NewWorker(store, registry, runner.WorkerOptions{
+		ID:           "w1",
+		Concurrency:  4,
+		PollInterval: 10 * time.Millisecond,
+		Lease:        time.Minute,
+	}, m), store, m
+}
+
+func enqueue(t *testing.T, store jobs.Inserter, opts jobs.Options) *jobs.Job {
+	t.Helper()
+	job, err := jobs.Enqueue(context.Background(), store, greetArgs{Name: "Ada"}, opts)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	return job
+}
+
+func stored(t *testing.T, store *testutils.FakeJobStore, id uint) jobs.Job {
+	t.Helper()
+	job, ok := store.Get(id)
+	require.True(t, ok)
+	return job
+}
+
+func TestWorkerRunsJobs(t *testing.T) {
+	var names []string
+	worker, store, m := newWorker(func(ctx context.Context, job *jobs.Job, args greetArgs) error {
+		names = append(names, args.Name)
+		return nil
+	})
+	job := enqueue(t, store, jobs.Options{})
+	enqueue(t, store, jobs.Options{Queue: "other"})
+	enqueue(t, store, jobs.Options{Delay: time.Hour})
+
+	n, err := worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n, "Only due jobs on the worker's queues are claimed")
+	assert.Equal(t, []string{"Ada"}, names)
+
+	done := stored(t, store, job.ID)
+	assert.Equal(t, jobs.StateSucceeded, done.State)
+	assert.Equal(t, 1, done.Attempts)
+	assert.NotNil(t, done.FinishedAt)
+	assert.Empty(t, done.LockedBy)
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.JobsProcessed.WithLabelValues(jobs.DefaultQueue, "test.greet", runner.OutcomeSucceeded)))
+}
+
+func TestWorkerRetriesWithBackoff(t *testing.T) {
+	worker, store, m := newWorker(func(ctx context.Context, job *jobs.Job, args greetArgs) error {
+		return errors.New("smtp unavailable")
+	})
+	job := enqueue(t, store, jobs.Options{MaxAttempts: 3})
+	now := time.Now()
+	store.Now = func() time.Time { return now }
+
+	_, err := worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	retried := stored(t, store, job.ID)
+	assert.Equal(t, jobs.StateAvailable, retried.State)
+	assert.Equal(t, "smtp unavailable", retried.LastError)
+	assert.WithinDuration(t, time.Now().Add(10*time.Second), retried.RunAt, time.Second)
+
+	n, err := worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n, "A retried job waits for its backoff")
+
+	now = now.Add(11 * time.Second)
+	_, err = worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	retried = stored(t, store, job.ID)
+	assert.Equal(t, 2, retried.Attempts)
+	assert.WithinDuration(t, time.Now().Add(20*time.Second), retried.RunAt, time.Second, "The backoff doubles")
+
+	now = now.Add(time.Minute)
+	_, err = worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	failed := stored(t, store, job.ID)
+	assert.Equal(t, jobs.StateFailed, failed.State, "The job fails once it runs out of attempts")
+	assert.Equal(t, 3, failed.Attempts)
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.JobsProcessed.WithLabelValues(jobs.DefaultQueue, "test.greet", runner.OutcomeRetried)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.JobsProcessed.WithLabelValues(jobs.DefaultQueue, "test.greet", runner.OutcomeFailed)))
+}
+
+func TestWorkerFailsPermanentErrors(t *testing.T) {
+	worker, store, _ := newWorker(func(ctx context.Context, job *jobs.Job, args greetArgs) error {
+		return jobs.Permanent(errors.New("unknown recipient"))
+	})
+	job := enqueue(t, store, jobs.Options{})
+
+	_, err := worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	failed := stored(t, store, job.ID)
+	assert.Equal(t, jobs.StateFailed, failed.State)
+	assert.Equal(t, 1, failed.Attempts)
+	assert.Equal(t, "unknown recipient", failed.LastError)
+}
+
+func TestWorkerRecoversPanics(t *testing.T) {
+	worker, store, _ := newWorker(func(ctx context.Context, job *jobs.Job, args greetArgs) error {
+		panic("boom")
+	})
+	job := enqueue(t, store, jobs.Options{})
+
+	_, err := worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	retried := stored(t, store, job.ID)
+	assert.Equal(t, jobs.StateAvailable, retried.State)
+	assert.Contains(t, retried.LastError, "boom")
+}
+
+func TestWorkerFailsUnknownKinds(t *testing.T) {
+	store := testutils.NewFakeJobStore()
+	worker := runner.NewWorker(store, jobs.NewRegistry(), runner.WorkerOptions{ID: "w1", Lease: time.Minute}, nil)
+	job := enqueue(t, store, jobs.Options{})
+
+	_, err := worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	failed := stored(t, store, job.ID)
+	assert.Equal(t, jobs.StateFailed, failed.State)
+	assert.Contains(t, failed.LastError, "no handler registered")
+}
+
+func TestExpiredLeaseIsTakenOver(t *testing.T) {
+	var runs atomic.Int32
+	worker, store, _ := newWorker(func(ctx context.Context, job *jobs.Job, args greetArgs) error {
+		runs.Add(1)
+		return nil
+	})
+	job := enqueue(t, store, jobs.Options{MaxAttempts: 2})
+	now := time.Now()
+	store.Now = func() time.Time { return now }
+
+	// A worker claims the job and dies
+	_, err := store.Claim(context.Background(), "crashed", []string{jobs.DefaultQueue}, 1, time.Minute)
+	require.NoError(t, err)
+	n, err := worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n, "A job is not taken over while its lease holds")
+
+	now = now.Add(2 * time.Minute)
+	n, err = worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, int32(1), runs.Load())
+	done := stored(t, store, job.ID)
+	assert.Equal(t, jobs.StateSucceeded, done.State)
+	assert.Equal(t, 2, done.Attempts, "The lost attempt counts")
+}
+
+func TestExpiredLeaseOnFinalAttemptFails(t *testing.T) {
+	var runs atomic.Int32
+	worker, store, _ := newWorker(func(ctx context.Context, job *jobs.Job, args greetArgs) error {
+		runs.Add(1)
+		return nil
+	})
+	job := enqueue(t, store, jobs.Options{MaxAttempts: 1})
+	now := time.Now()
+	store.Now = func() time.Time { return now }
+
+	_, err := store.Claim(context.Background(), "crashed", []string{jobs.DefaultQueue}, 1, time.Minute)
+	require.NoError(t, err)
+	now = now.Add(2 * time.Minute)
+	_, err = worker.RunOnce(context.Background())
+	require.NoError(t, err)
+
+	assert.Zero(t, runs.Load(), "A job that exhausted its attempts is not run again")
+	failed := stored(t, store, job.ID)
+	assert.Equal(t, jobs.StateFailed, failed.State)
+	assert.Contains(t, failed.LastError, "lease expired")
+}
+
+func TestWorkerDoesNotFinishJobsItLost(t *testing.T) {
+	var store *testutils.FakeJobStore
+	var now time.Time
+	worker, store, m := newWorker(func(ctx context.Context, job *jobs.Job, args greetArgs) error {
+		// The handler stalls past its lease and another worker takes over
+		now = now.Add(2 * time.Minute)
+		_, err := store.Claim(ctx, "w2", []string{jobs.DefaultQueue}, 1, time.Minute)
+		return err
+	})
+	job := enqueue(t, store, jobs.Options{})
+	now = time.Now()
+	store.Now = func() time.Time { return now }
+
+	_, err := worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	running := stored(t, store, job.ID)
+	assert.Equal(t, jobs.StateRunning, running.State, "The result of a lost job is discarded")
+	assert.Equal(t, "w2", running.LockedBy)
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.JobsProcessed.WithLabelValues(jobs.DefaultQueue, "test.greet", runner.OutcomeLeaseLost)))
+}
+
+func TestWorkerDoesNotFinishJobsItReclaimed(t *testing.T) {
+	var store *testutils.FakeJobStore
+	var now time.Time
+	var reclaimed []jobs.Job
+	worker, store, m := newWorker(func(ctx context.Context, job *jobs.Job, args greetArgs) error {
+		// The handler stalls past its lease and the same worker claims the
+		// job again; this older claim must not finish it
+		now = now.Add(2 * time.Minute)
+		var err error
+		reclaimed, err = store.Claim(ctx, "w1", []string{jobs.DefaultQueue}, 1, time.Minute)
+		return err
+	})
+	job := enqueue(t, store, jobs.Options{})
+	now = time.Now()
+	store.Now = func() time.Time { return now }
+
+	_, err := worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Len(t, reclaimed, 1)
+	running := stored(t, store, job.ID)
+	assert.Equal(t, jobs.StateRunning, running.State, "The result of the older claim is discarded")
+	assert.Equal(t, 2, running.Attempts)
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.JobsProcessed.WithLabelValues(jobs.DefaultQueue, "test.greet", runner.OutcomeLeaseLost)))
+
+	require.NoError(t, store.Complete(context.Background(), &reclaimed[0]), "The newer claim still holds the lease")
+	assert.Equal(t, jobs.StateSucceeded, stored(t, store, job.ID).State)
+}
+
+func TestWorkerRunEnqueuesPeriodicJobs(t *testing.T) {
+	ran := make(chan string, 1)
+	store := testutils.NewFakeJobStore()
+	registry := jobs.NewRegistry()
+	jobs.Register(registry, func(ctx context.Context, job *jobs.Job, args greetArgs) error {
+		ran <- args.Name
+		return nil
+	})
+	worker := runner.NewWorker(store, registry, runner.WorkerOptions{
+		ID:           "w1",
+		PollInterval: 10 * time.Millisecond,
+		Lease:        time.Minute,
+		Periodic:     []runner.Periodic{{Args: greetArgs{Name: "Ada"}, Interval: time.Hour}},
+	}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		worker.Run(ctx)
+	}()
+
+	select {
+	case name := <-ran:
+		assert.Equal(t, "Ada", name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("periodic job did not run")
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after cancellation")
+	}
+
+	queued := store.Jobs()
+	require.Len(t, queued, 1, "The job is enqueued once per interval")
+	require.NotNil(t, queued[0].UniqueKey)
+	assert.Equal(t, "test.greet", *queued[0].UniqueKey, "Periodic jobs are unique by kind")
+}
+
+func TestDepthCollector(t *testing.T) {
+	store := testutils.NewFakeJobStore()
+	enqueue(t, store, jobs.Options{})
+	enqueue(t, store, jobs.Options{})
+	enqueue(t, store, jobs.Options{Queue: "mail", Delay: time.Hour})
+
+	m := metrics.New()
+	require.NoError(t, m.Register(runner.NewDepthCollector(store, time.Second)))
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body := w.Body.String()
+	assert.Contains(t, body, `app_jobs_queue_depth{queue="default",state="available"} 2`)
+	assert.Contains(t, body, `app_jobs_queue_depth{queue="mail",state="available"} 1`)
+	assert.Contains(t, body, `app_jobs_oldest_due_age_seconds{queue="default"}`)
+	assert.NotContains(t, body, `app_jobs_oldest_due_age_seconds{queue="mail"}`, "Jobs scheduled for later are not overdue")
+}
+
+func TestHeartbeatCancelsHandlerOnLostLease(t *testing.T) {
+	store := testutils.NewFakeJobStore()
+	registry := jobs.NewRegistry()
+	cancelled := make(chan error, 1)
+	jobs.Register(registry, func(ctx context.Context, job *jobs.Job, args greetArgs) error {
+		// Another worker takes the job over while this one is still running it
+		store.Now = func() time.Time { return time.Now().Add(time.Minute) }
+		if _, err := store.Claim(ctx, "w2", []string{jobs.DefaultQueue}, 1, time.Minute); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			cancelled <- context.Cause(ctx)
+		case <-time.After(5 * time.Second):
+			cancelled <- nil
+		}
+		return ctx.Err()
+	})
+	worker := runner.NewWorker(store, registry, runner.WorkerOptions{ID: "w1", Lease: 3 * time.Second}, nil)
+	job := enqueue(t, store, jobs.Options{})
+
+	_, err := worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.ErrorIs(t, <-cancelled, jobs.ErrLeaseLost, "The heartbeat cancels a handler whose lease was taken over")
+	assert.Equal(t, "w2", stored(t, store, job.ID).LockedBy)
+}
@@ -0,0 +1,196 @@
+package mailer_test
+
+import (
+	"bufio"
+	"context"
+	"mime"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/mailer"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/jobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var welcome = mailer.Message{
+	To:      []string{"Jane Doe <jane@example.com>"},
+	Subject: "Wélcome",
+	Text:    "Hello Jane,\nwelcome aboard.\n",
+}
+
+func TestFileMailerWritesEML(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	m, err := mailer.NewFileMailer(dir, "Lean Backend <no-reply@example.com>")
+	require.NoError(t, err)
+	require.NoError(t, m.Send(context.Background(), welcome))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	raw, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "welcome aboard.\r\n", "Bodies use CRLF line endings")
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	require.NoError(t, err)
+	assert.Equal(t, `"Lean Backend" <no-reply@example.com>`, msg.Header.Get("From"))
+	assert.Equal(t, `"Jane Doe" <jane@example.com>`, msg.Header.Get("To"))
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Wélcome", subject)
+	assert.Regexp(t, `^<[0-9a-f]+@example\.com>$`, msg.Header.Get("Message-Id"))
+	_, err = msg.Header.Date()
+	assert.NoError(t, err)
+}
+
+func TestMailersRejectInvalidMessages(t *testing.T) {
+	ctx := context.Background()
+	m := mailer.NewMemoryMailer()
+	assert.Error(t, m.Send(ctx, mailer.Message{Subject: "No recipients"}))
+	assert.Error(t, m.Send(ctx, mailer.Message{To: []string{"not an address"}}))
+	assert.Empty(t, m.Messages())
+
+	f, err := mailer.NewFileMailer(t.TempDir(), "not an address")
+	require.NoError(t, err)
+	assert.Error(t, f.Send(ctx, welcome), "The sender address is validated")
+}
+
+func TestMemoryMailerRecordsMessages(t *testing.T) {
+	m := mailer.NewMemoryMailer()
+	require.NoError(t, m.Send(context.Background(), welcome))
+	require.NoError(t, m.Send(context.Background(), mailer.Message{To: []string{"bob@example.com"}, Subject: "Second"}))
+
+	sent := m.Messages()
+	require.Len(t, sent, 2)
+	assert.Equal(t, welcome, sent[0])
+	assert.Equal(t, "Second", sent[1].Subject)
+}
+
+// fakeSMTPServer accepts a single plain-text SMTP session and sends the
+// envelope and data it received on the returned channel.
+// fakeSMTPServer accepts one message, answering RCPT with rcptReply. It
+// does not offer STARTTLS.
+func fakeSMTPServer(t *testing.T, rcptReply string) (string, <-chan []string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tp := textproto.NewConn(conn)
+		var lines []string
+		_ = tp.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			verb := strings.ToUpper(strings.Fields(line + " ")[0])
+			switch verb {
+			case "EHLO", "HELO":
+				_ = tp.PrintfLine("250 localhost")
+			case "MAIL":
+				lines = append(lines, line)
+				_ = tp.PrintfLine("250 OK")
+			case "RCPT":
+				lines = append(lines, line)
+				_ = tp.PrintfLine("%s", rcptReply)
+			case "DATA":
+				_ = tp.PrintfLine("354 Go ahead")
+				data, err := tp.ReadDotLines()
+				if err != nil {
+					return
+				}
+				lines = append(lines, data...)
+				_ = tp.PrintfLine("250 Queued")
+			case "QUIT":
+				_ = tp.PrintfLine("221 Bye")
+				received <- lines
+				return
+			default:
+				_ = tp.PrintfLine("502 Not implemented")
+			}
+		}
+	}()
+	return ln.Addr().String(), received
+}
+
+func TestSMTPMailerDelivers(t *testing.T) {
+	addr, received := fakeSMTPServer(t, "250 OK")
+	host, port, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+
+	m := mailer.NewSMTPMailer(mailer.SMTPConfig{Host: host, Port: port, From: "Lean Backend <no-reply@example.com>"})
+	require.NoError(t, m.Send(context.Background(), welcome))
+
+	lines := <-received
+	require.GreaterOrEqual(t, len(lines), 2)
+	assert.Equal(t, "MAIL FROM:<no-reply@example.com>", lines[0])
+	assert.Equal(t, "RCPT TO:<jane@example.com>", lines[1])
+	assert.Contains(t, lines, "Hello Jane,")
+	assert.Contains(t, lines, "welcome aboard.")
+}
+
+func TestSMTPMailerHonoursContext(t *testing.T) {
+	// Accepts connections but never greets
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			_, _ = bufio.NewReader(conn).ReadString('\n')
+			conn.Close()
+		}
+	}()
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	m := mailer.NewSMTPMailer(mailer.SMTPConfig{Host: host, Port: port, From: "no-reply@example.com"})
+	start := time.Now()
+	assert.Error(t, m.Send(ctx, welcome))
+	assert.Less(t, time.Since(start), 5*time.Second, "A stalled server does not block past the deadline")
+}
+
+func TestSMTPMailerRequiresTLS(t *testing.T) {
+	addr, _ := fakeSMTPServer(t, "250 OK")
+	host, port, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+
+	m := mailer.NewSMTPMailer(mailer.SMTPConfig{Host: host, Port: port, From: "no-reply@example.com", RequireTLS: true})
+	err = m.Send(context.Background(), welcome)
+	require.Error(t, err, "Mail is not sent in plaintext")
+	assert.Contains(t, err.Error(), "STARTTLS")
+}
+
+func TestSMTPMailerRejectionsArePermanent(t *testing.T) {
+	for reply, permanent := range map[string]bool{
+		"550 No such user":            true,
+		"450 Mailbox busy, try later": false,
+	} {
+		t.Run(reply, func(t *testing.T) {
+			addr, _ := fakeSMTPServer(t, reply)
+			host, port, err := net.SplitHostPort(addr)
+			require.NoError(t, err)
+
+			m := mailer.NewSMTPMailer(mailer.SMTPConfig{Host: host, Port: port, From: "no-reply@example.com"})
+			err = m.Send(context.Background(), welcome)
+			require.Error(t, err)
+			assert.Equal(t, permanent, jobs.IsPermanent(err))
+		})
+	}
+}
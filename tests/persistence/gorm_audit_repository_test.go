@@ -0,0 +1,34 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/persistence"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditListQuery(t *testing.T) {
+	db := testutils.NewDryRunDB()
+	captured := testutils.CaptureSQL(db)
+
+	actor := uint(3)
+	_, err := persistence.NewGormAuditRepository(db).List(context.Background(), repositories.AuditQuery{
+		ActorID: &actor,
+		Action:  "user.updated",
+		Since:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, 100, 20)
+	require.NoError(t, err)
+
+	assert.Contains(t, captured.SQL, `FROM "audit_log"`)
+	assert.Contains(t, captured.SQL, "actor_id = $1")
+	assert.Contains(t, captured.SQL, "action = $2")
+	assert.Contains(t, captured.SQL, "created_at >= $3")
+	assert.Contains(t, captured.SQL, "id < $4", "Pages continue below the before cursor")
+	assert.Contains(t, captured.SQL, "ORDER BY id DESC")
+	assert.NotContains(t, captured.SQL, "target_id", "Unset filters add no conditions")
+}
@@ -0,0 +1,62 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/persistence"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailVerificationUseQuery(t *testing.T) {
+	db := testutils.NewDryRunDB()
+	captured := testutils.CaptureSQL(db)
+
+	token, err := persistence.NewGormEmailVerificationRepository(db).Use(context.Background(), "abc")
+	require.NoError(t, err)
+	assert.Nil(t, token, "Nothing was updated in a dry run")
+
+	assert.Contains(t, captured.SQL, `UPDATE "email_verification_tokens" SET "used_at"=$1`)
+	assert.Contains(t, captured.SQL, "token_id = $2 AND used_at IS NULL AND expires_at > $3",
+		"Only an unused, unexpired token is marked used")
+	assert.Contains(t, captured.SQL, "RETURNING *")
+}
+
+func TestEmailVerificationUseReturnsTheToken(t *testing.T) {
+	_, _, db := testutils.SetupTestRouter(true)
+	if db == nil {
+		t.Fatal("Database connection required for email verification tests")
+	}
+	testutils.CleanupDatabase(db)
+	defer testutils.CleanupDatabase(db)
+
+	user := models.User{Name: "Jane Doe", Email: "jane@example.com"}
+	require.NoError(t, db.Create(&user).Error)
+	repo := persistence.NewGormEmailVerificationRepository(db)
+	ctx := context.Background()
+	require.NoError(t, repo.Create(ctx, &models.EmailVerificationToken{
+		UserID: user.ID, TokenID: "live", Email: user.Email, ExpiresAt: time.Now().Add(time.Hour),
+	}))
+	require.NoError(t, repo.Create(ctx, &models.EmailVerificationToken{
+		UserID: user.ID, TokenID: "expired", Email: user.Email, ExpiresAt: time.Now().Add(-time.Minute),
+	}))
+
+	token, err := repo.Use(ctx, "live")
+	require.NoError(t, err)
+	require.NotNil(t, token)
+	assert.Equal(t, user.ID, token.UserID, "RETURNING fills in the stored row")
+	assert.Equal(t, user.Email, token.Email)
+	assert.NotNil(t, token.UsedAt)
+
+	token, err = repo.Use(ctx, "live")
+	require.NoError(t, err)
+	assert.Nil(t, token, "Tokens are single use")
+
+	token, err = repo.Use(ctx, "expired")
+	require.NoError(t, err)
+	assert.Nil(t, token)
+}
@@ -0,0 +1,147 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/persistence"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/jobs"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type noteArgs struct {
+	Text string `json:"text"`
+}
+
+func (noteArgs) Kind() string { return "test.note" }
+
+func TestGormJobStoreSQL(t *testing.T) {
+	db := testutils.NewDryRunDB()
+	captured := testutils.CaptureSQL(db)
+	store := persistence.NewGormJobStore(db)
+
+	tests := []struct {
+		name     string
+		run      func(ctx context.Context) error
+		wantErr  error
+		contains []string
+	}{
+		{
+			name: "Enqueue",
+			run: func(ctx context.Context) error {
+				_, err := jobs.Enqueue(ctx, store, noteArgs{Text: "hello"}, jobs.Options{UniqueKey: "note:hello"})
+				return err
+			},
+			// The conflict target must match the partial unique index
+			contains: []string{
+				`INSERT INTO "jobs"`,
+				`ON CONFLICT ("unique_key")`,
+				"WHERE unique_key IS NOT NULL AND state IN ('available', 'running') DO NOTHING",
+			},
+		},
+		{
+			name: "Complete",
+			run: func(ctx context.Context) error {
+				return store.Complete(ctx, &jobs.Job{ID: 7, LockedBy: "w1"})
+			},
+			// Dry runs affect no rows
+			wantErr:  jobs.ErrLeaseLost,
+			contains: []string{`UPDATE "jobs" SET`, "id = $", "state = $", "locked_by = $", "attempts = $"},
+		},
+		{
+			name: "Extend",
+			run: func(ctx context.Context) error {
+				return store.Extend(ctx, &jobs.Job{ID: 7, LockedBy: "w1"}, time.Minute)
+			},
+			wantErr:  jobs.ErrLeaseLost,
+			contains: []string{`"locked_until"=now() + make_interval(secs => $`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run(context.Background())
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			for _, want := range tt.contains {
+				assert.Contains(t, captured.SQL, want)
+			}
+		})
+	}
+}
+
+func TestGormJobStoreClaim(t *testing.T) {
+	_, _, db := testutils.SetupTestRouter(true)
+	if db == nil {
+		t.Fatal("Database connection required for job tests")
+	}
+	testutils.CleanupDatabase(db)
+	defer testutils.CleanupDatabase(db)
+
+	store := persistence.NewGormJobStore(db)
+	ctx := context.Background()
+	enqueue := func(text string, opts jobs.Options) *jobs.Job {
+		job, err := jobs.Enqueue(ctx, store, noteArgs{Text: text}, opts)
+		require.NoError(t, err)
+		return job
+	}
+	older := enqueue("older", jobs.Options{RunAt: time.Now().Add(-2 * time.Minute)})
+	newer := enqueue("newer", jobs.Options{RunAt: time.Now().Add(-time.Minute)})
+	enqueue("later", jobs.Options{Delay: time.Hour})
+	enqueue("mail", jobs.Options{Queue: "mail"})
+
+	t.Run("Skips Rows Locked By Another Claim", func(t *testing.T) {
+		tx := db.Begin()
+		defer tx.Rollback()
+		require.NoError(t, tx.Exec("SELECT id FROM jobs WHERE id = ? FOR UPDATE", older.ID).Error)
+
+		// A claim that waited for the lock would run into the timeout
+		claimCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		claimed, err := store.Claim(claimCtx, "w1", []string{jobs.DefaultQueue}, 10, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1, "Jobs not yet due and jobs of other queues stay")
+		assert.Equal(t, newer.ID, claimed[0].ID)
+		assert.Equal(t, jobs.StateRunning, claimed[0].State)
+		assert.Equal(t, "w1", claimed[0].LockedBy)
+		assert.Equal(t, 1, claimed[0].Attempts)
+		require.NotNil(t, claimed[0].LockedUntil)
+	})
+
+	t.Run("Leases Claimed Jobs", func(t *testing.T) {
+		claimed, err := store.Claim(ctx, "w2", []string{jobs.DefaultQueue, "mail"}, 10, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, claimed, 2, "The job leased to w1 is not claimed again")
+		assert.Equal(t, older.ID, claimed[0].ID, "Claimed oldest first")
+		assert.Equal(t, "mail", claimed[1].Queue)
+	})
+
+	t.Run("Expired Leases Are Reclaimed", func(t *testing.T) {
+		job := enqueue("expiring", jobs.Options{})
+		first, err := store.Claim(ctx, "w1", []string{jobs.DefaultQueue}, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, first, 1)
+		assert.Equal(t, job.ID, first[0].ID)
+
+		second, err := store.Claim(ctx, "w1", []string{jobs.DefaultQueue}, 10, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, second, 1)
+		assert.Equal(t, 2, second[0].Attempts)
+
+		assert.ErrorIs(t, store.Complete(ctx, &first[0]), jobs.ErrLeaseLost, "The older claim of the same worker lost the job")
+		require.NoError(t, store.Complete(ctx, &second[0]))
+		var stored jobs.Job
+		require.NoError(t, db.First(&stored, job.ID).Error)
+		assert.Equal(t, jobs.StateSucceeded, stored.State)
+	})
+
+	t.Run("Unique Jobs Are Skipped While Waiting", func(t *testing.T) {
+		require.NotNil(t, enqueue("once", jobs.Options{UniqueKey: "note:once", Delay: time.Hour}))
+		assert.Nil(t, enqueue("once", jobs.Options{UniqueKey: "note:once"}))
+	})
+}
@@ -0,0 +1,97 @@
+package persistence_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/persistence"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGormOutboxClaimDue(t *testing.T) {
+	_, _, db := testutils.SetupTestRouter(true)
+	if db == nil {
+		t.Fatal("Database connection required for outbox tests")
+	}
+	testutils.CleanupDatabase(db)
+	defer testutils.CleanupDatabase(db)
+
+	repo := persistence.NewGormOutboxRepository(db)
+	ctx := context.Background()
+	msgs := make([]*models.OutboxMessage, 3)
+	for i := range msgs {
+		msgs[i] = &models.OutboxMessage{EventType: "user.created", AggregateType: "user", AggregateID: uint(i + 1), Payload: json.RawMessage(`{}`)}
+	}
+	require.NoError(t, repo.Add(ctx, msgs...))
+
+	publish := func(claimed []models.OutboxMessage) []uint {
+		ids := make([]uint, len(claimed))
+		now := time.Now()
+		for i := range claimed {
+			ids[i] = claimed[i].ID
+			claimed[i].PublishedAt = &now
+		}
+		return ids
+	}
+	published := func(id uint) bool {
+		var msg models.OutboxMessage
+		require.NoError(t, db.First(&msg, id).Error)
+		return msg.PublishedAt != nil
+	}
+
+	t.Run("Skips Rows Locked By Another Claim", func(t *testing.T) {
+		tx := db.Begin()
+		defer tx.Rollback()
+		require.NoError(t, tx.Exec("SELECT id FROM outbox_events WHERE id = ? FOR UPDATE", msgs[0].ID).Error)
+
+		// A claim that waited for the lock would run into the timeout
+		claimCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		var ids []uint
+		n, err := repo.ClaimDue(claimCtx, 10, time.Minute, func(claimed []models.OutboxMessage) error {
+			ids = publish(claimed)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, []uint{msgs[1].ID, msgs[2].ID}, ids, "Claimed oldest first")
+		assert.True(t, published(msgs[1].ID))
+		assert.False(t, published(msgs[0].ID))
+	})
+
+	t.Run("Leases Claimed Rows", func(t *testing.T) {
+		var ids []uint
+		n, err := repo.ClaimDue(ctx, 10, time.Minute, func(claimed []models.OutboxMessage) error {
+			ids = publish(claimed)
+			// A second relay sees nothing while the lease lasts
+			again, err := repo.ClaimDue(ctx, 10, time.Minute, func([]models.OutboxMessage) error { return nil })
+			require.NoError(t, err)
+			assert.Zero(t, again)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, []uint{msgs[0].ID}, ids)
+		assert.True(t, published(msgs[0].ID))
+	})
+
+	t.Run("Reclaimed Rows Keep Their New Holder's Result", func(t *testing.T) {
+		late := &models.OutboxMessage{EventType: "user.deleted", AggregateType: "user", AggregateID: 4, Payload: json.RawMessage(`{}`)}
+		require.NoError(t, repo.Add(ctx, late))
+
+		n, err := repo.ClaimDue(ctx, 10, time.Minute, func(claimed []models.OutboxMessage) error {
+			// The lease ran out and another relay claimed the message
+			require.NoError(t, db.Exec("UPDATE outbox_events SET available_at = now() + interval '1 hour' WHERE id = ?", late.ID).Error)
+			publish(claimed)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.False(t, published(late.ID))
+	})
+}
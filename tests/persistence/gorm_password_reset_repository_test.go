@@ -0,0 +1,90 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/persistence"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordResetQueries(t *testing.T) {
+	db := testutils.NewDryRunDB()
+	captured := testutils.CaptureSQL(db)
+	repo := persistence.NewGormPasswordResetRepository(db)
+
+	tests := []struct {
+		name     string
+		run      func(ctx context.Context) error
+		contains []string
+	}{
+		{
+			name: "Use",
+			run: func(ctx context.Context) error {
+				token, err := repo.Use(ctx, "abc")
+				assert.Nil(t, token, "Nothing was updated in a dry run")
+				return err
+			},
+			// Only an unused, unexpired token is marked used
+			contains: []string{
+				`UPDATE "password_reset_tokens" SET "used_at"=$1`,
+				"token_id = $2 AND used_at IS NULL AND expires_at > $3",
+				"RETURNING *",
+			},
+		},
+		{
+			name: "Use All For User",
+			run: func(ctx context.Context) error {
+				return repo.UseAllForUser(ctx, 7)
+			},
+			contains: []string{`UPDATE "password_reset_tokens" SET "used_at"=$1`, "user_id = $2 AND used_at IS NULL"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.run(context.Background()))
+			for _, want := range tt.contains {
+				assert.Contains(t, captured.SQL, want)
+			}
+		})
+	}
+}
+
+func TestPasswordResetUseReturnsTheToken(t *testing.T) {
+	_, _, db := testutils.SetupTestRouter(true)
+	if db == nil {
+		t.Fatal("Database connection required for password reset tests")
+	}
+	testutils.CleanupDatabase(db)
+	defer testutils.CleanupDatabase(db)
+
+	user := models.User{Name: "Jane Doe", Email: "jane@example.com"}
+	require.NoError(t, db.Create(&user).Error)
+	repo := persistence.NewGormPasswordResetRepository(db)
+	ctx := context.Background()
+	require.NoError(t, repo.Create(ctx, &models.PasswordResetToken{
+		UserID: user.ID, TokenID: "live", Email: user.Email, ExpiresAt: time.Now().Add(time.Hour),
+	}))
+	require.NoError(t, repo.Create(ctx, &models.PasswordResetToken{
+		UserID: user.ID, TokenID: "expired", Email: user.Email, ExpiresAt: time.Now().Add(-time.Minute),
+	}))
+
+	token, err := repo.Use(ctx, "live")
+	require.NoError(t, err)
+	require.NotNil(t, token)
+	assert.Equal(t, user.ID, token.UserID, "RETURNING fills in the stored row")
+	assert.Equal(t, user.Email, token.Email)
+	assert.NotNil(t, token.UsedAt)
+
+	token, err = repo.Use(ctx, "live")
+	require.NoError(t, err)
+	assert.Nil(t, token, "Tokens are single use")
+
+	token, err = repo.Use(ctx, "expired")
+	require.NoError(t, err)
+	assert.Nil(t, token)
+}
@@ -0,0 +1,60 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/persistence"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateIsConditionalOnVersion(t *testing.T) {
+	db := testutils.NewDryRunDB()
+	captured := testutils.CaptureSQL(db)
+
+	user := &models.User{ID: 7, Name: "Jane", Email: "jane@example.com", Version: 3}
+	err := persistence.NewGormUserRepository(db).Update(context.Background(), user)
+
+	// A dry run affects no rows, which is exactly what a lost race looks like
+	assert.ErrorIs(t, err, repositories.ErrVersionConflict)
+	assert.Equal(t, int64(3), user.Version, "The caller's copy is untouched on conflict")
+	assert.Contains(t, captured.SQL, `"version"=$`)
+	assert.Contains(t, captured.SQL, `WHERE version = $`)
+	assert.Contains(t, captured.SQL, `"id" = $`)
+	assert.NotContains(t, captured.SQL, `"created_at"=`, "Creation time is never rewritten")
+}
+
+func TestReplacePasswordHashIsConditional(t *testing.T) {
+	db := testutils.NewDryRunDB()
+	captured := testutils.CaptureSQL(db)
+
+	require.NoError(t, persistence.NewGormUserRepository(db).ReplacePasswordHash(context.Background(), 7, "old", "new"))
+	assert.Contains(t, captured.SQL, `SET "password_hash"=$1`)
+	assert.Contains(t, captured.SQL, "id = $2 AND password_hash = $3", "A hash changed meanwhile is not overwritten")
+	assert.NotContains(t, captured.SQL, `"version"`, "Rehashing is not a user change")
+	assert.NotContains(t, captured.SQL, `"updated_at"`)
+}
+
+func TestRestoreReportsTakenEmail(t *testing.T) {
+	_, _, db := testutils.SetupTestRouter(true)
+	if db == nil {
+		t.Fatal("Database connection required for user repository tests")
+	}
+	testutils.CleanupDatabase(db)
+	defer testutils.CleanupDatabase(db)
+
+	repo := persistence.NewGormUserRepository(db)
+	ctx := context.Background()
+	deleted := &models.User{Name: "Jane", Email: "jane@example.com"}
+	require.NoError(t, repo.Create(ctx, deleted))
+	require.NoError(t, repo.Delete(ctx, deleted.ID, deleted.Version))
+	require.NoError(t, repo.Create(ctx, &models.User{Name: "Newcomer", Email: "jane@example.com"}))
+
+	stored, err := repo.GetDeletedByID(ctx, deleted.ID)
+	require.NoError(t, err)
+	assert.ErrorIs(t, repo.Restore(ctx, stored), repositories.ErrEmailTaken)
+}
@@ -0,0 +1,145 @@
+package persistence_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/persistence"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookQueries(t *testing.T) {
+	db := testutils.NewDryRunDB()
+	captured := testutils.CaptureSQL(db)
+	repo := persistence.NewGormWebhookRepository(db)
+
+	tests := []struct {
+		name     string
+		run      func(ctx context.Context) error
+		contains []string
+		vars     []any
+	}{
+		{
+			name: "Subscribers",
+			run: func(ctx context.Context) error {
+				_, err := repo.ListSubscribers(ctx, "user.created")
+				return err
+			},
+			contains: []string{
+				`FROM "webhook_subscriptions"`,
+				"active AND (event_types @> to_jsonb($1::text) OR event_types @> to_jsonb($2::text))",
+			},
+			vars: []any{"user.created", "*"},
+		},
+		{
+			name: "Deliveries",
+			run: func(ctx context.Context) error {
+				_, err := repo.ListDeliveries(ctx, 7, "dead", 100, 20)
+				return err
+			},
+			contains: []string{`FROM "webhook_deliveries"`, "subscription_id = $1", "status = $2", "id < $3", "ORDER BY id DESC"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.run(context.Background()))
+			for _, want := range tt.contains {
+				assert.Contains(t, captured.SQL, want)
+			}
+			if tt.vars != nil {
+				assert.Equal(t, tt.vars, captured.Vars)
+			}
+		})
+	}
+}
+
+func TestGormWebhookClaimDueDeliveries(t *testing.T) {
+	_, _, db := testutils.SetupTestRouter(true)
+	if db == nil {
+		t.Fatal("Database connection required for webhook tests")
+	}
+	testutils.CleanupDatabase(db)
+	defer testutils.CleanupDatabase(db)
+
+	repo := persistence.NewGormWebhookRepository(db)
+	ctx := context.Background()
+	active := &models.WebhookSubscription{URL: "https://example.com/hook", EventTypes: []string{"*"}, Secret: "s", Active: true}
+	paused := &models.WebhookSubscription{URL: "https://example.com/paused", EventTypes: []string{"*"}, Secret: "s"}
+	require.NoError(t, repo.CreateSubscription(ctx, active))
+	require.NoError(t, repo.CreateSubscription(ctx, paused))
+
+	due := time.Now().Add(-time.Minute)
+	delivery := func(sub *models.WebhookSubscription, eventID string) *models.WebhookDelivery {
+		return &models.WebhookDelivery{
+			SubscriptionID: sub.ID, EventID: eventID, EventType: "user.created",
+			Payload: json.RawMessage(`{}`), Status: models.WebhookDeliveryPending, NextAttemptAt: due,
+		}
+	}
+	first := delivery(active, "00000000-0000-0000-0000-000000000001")
+	second := delivery(active, "00000000-0000-0000-0000-000000000002")
+	ofPaused := delivery(paused, "00000000-0000-0000-0000-000000000003")
+	require.NoError(t, repo.AddDeliveries(ctx, first, second, ofPaused))
+
+	succeed := func(claimed []models.WebhookDelivery) []uint {
+		ids := make([]uint, len(claimed))
+		for i := range claimed {
+			ids[i] = claimed[i].ID
+			claimed[i].Status = models.WebhookDeliverySucceeded
+			claimed[i].Attempts++
+		}
+		return ids
+	}
+	status := func(id uint) string {
+		var d models.WebhookDelivery
+		require.NoError(t, db.First(&d, id).Error)
+		return d.Status
+	}
+
+	t.Run("Skips Locked Rows And Paused Subscriptions", func(t *testing.T) {
+		tx := db.Begin()
+		defer tx.Rollback()
+		require.NoError(t, tx.Exec("SELECT id FROM webhook_deliveries WHERE id = ? FOR UPDATE", first.ID).Error)
+
+		// A claim that waited for the lock would run into the timeout
+		claimCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		var ids []uint
+		n, err := repo.ClaimDueDeliveries(claimCtx, 10, time.Minute, func(claimed []models.WebhookDelivery) error {
+			for _, d := range claimed {
+				require.NotNil(t, d.Subscription)
+				assert.Equal(t, active.URL, d.Subscription.URL)
+			}
+			ids = succeed(claimed)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, []uint{second.ID}, ids)
+		assert.Equal(t, models.WebhookDeliverySucceeded, status(second.ID))
+		assert.Equal(t, models.WebhookDeliveryPending, status(ofPaused.ID))
+	})
+
+	t.Run("Reclaimed Rows Keep Their New Holder's Result", func(t *testing.T) {
+		var ids []uint
+		n, err := repo.ClaimDueDeliveries(ctx, 10, time.Minute, func(claimed []models.WebhookDelivery) error {
+			// A second dispatcher sees nothing while the lease lasts
+			again, err := repo.ClaimDueDeliveries(ctx, 10, time.Minute, func([]models.WebhookDelivery) error { return nil })
+			require.NoError(t, err)
+			assert.Zero(t, again)
+
+			// The lease ran out and another dispatcher claimed the delivery
+			require.NoError(t, db.Exec("UPDATE webhook_deliveries SET next_attempt_at = now() + interval '1 hour' WHERE id = ?", first.ID).Error)
+			ids = succeed(claimed)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, []uint{first.ID}, ids)
+		assert.Equal(t, models.WebhookDeliveryPending, status(first.ID))
+	})
+}
@@ -0,0 +1,66 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/persistence"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupDryRunRepository returns a repository whose queries are built but
+// never executed, and a pointer to the last statement it produced.
+func setupDryRunRepository() (repositories.UserRepository, *testutils.CapturedSQL) {
+	db := testutils.NewDryRunDB()
+	return persistence.NewGormUserRepository(db), testutils.CaptureSQL(db)
+}
+
+func TestUserQueryFilters(t *testing.T) {
+	repo, captured := setupDryRunRepository()
+
+	_, err := repo.ListAfter(context.Background(), repositories.UserQuery{
+		Filters: []repositories.UserFilter{
+			{Field: repositories.UserFieldName, Op: repositories.OpPrefix, Values: []any{"50%_off"}},
+			{Field: repositories.UserFieldEmail, Op: repositories.OpIn, Values: []any{"a@example.com", "b@example.com"}},
+		},
+		Search:  "smith",
+		Deleted: repositories.DeletedOnly,
+	}, nil, 10)
+	require.NoError(t, err)
+
+	assert.Contains(t, captured.SQL, "users.deleted_at IS NOT NULL")
+	assert.Contains(t, captured.SQL, `users.name LIKE $1 ESCAPE '\'`)
+	assert.Contains(t, captured.SQL, "users.email IN ($2,$3)")
+	assert.Contains(t, captured.SQL, "users.name ILIKE $4")
+	assert.NotContains(t, captured.SQL, "users.deleted_at IS NULL", "Deleted users should be selected")
+	assert.Equal(t, []any{`50\%\_off%`, "a@example.com", "b@example.com", "%smith%", "%smith%", 10}, captured.Vars,
+		"Wildcards in user input should be escaped")
+}
+
+func TestUserQueryKeyset(t *testing.T) {
+	repo, captured := setupDryRunRepository()
+
+	_, err := repo.ListAfter(context.Background(), repositories.UserQuery{
+		Sort: []repositories.SortKey{
+			{Field: repositories.UserFieldName, Desc: true},
+			{Field: repositories.UserFieldID},
+		},
+	}, []any{"Jane", uint(7)}, 10)
+	require.NoError(t, err)
+
+	assert.Contains(t, captured.SQL, "((users.name < $1) OR (users.name = $2 AND users.id > $3))")
+	assert.Contains(t, captured.SQL, "ORDER BY users.name DESC,users.id")
+	assert.Equal(t, []any{"Jane", "Jane", uint(7), 10}, captured.Vars)
+}
+
+func TestUserQueryRejectsUnknownFields(t *testing.T) {
+	repo, _ := setupDryRunRepository()
+
+	_, err := repo.ListAfter(context.Background(), repositories.UserQuery{
+		Sort: []repositories.SortKey{{Field: "password_hash"}},
+	}, nil, 10)
+	assert.Error(t, err, "Fields outside the column whitelist must never reach SQL")
+}
@@ -0,0 +1,139 @@
+package ratelimit_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/ratelimit"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicy(t *testing.T) {
+	p, err := ratelimit.ParsePolicy("10/1m")
+	require.NoError(t, err)
+	assert.Equal(t, ratelimit.Policy{Limit: 10, Period: time.Minute, Burst: 10}, p)
+
+	p, err = ratelimit.ParsePolicy("100/1h,burst=5")
+	require.NoError(t, err)
+	assert.Equal(t, ratelimit.Policy{Limit: 100, Period: time.Hour, Burst: 5}, p)
+
+	for _, disabled := range []string{"", "0"} {
+		p, err := ratelimit.ParsePolicy(disabled)
+		require.NoError(t, err)
+		assert.False(t, p.Enabled())
+	}
+
+	for _, invalid := range []string{"10", "x/1m", "0/1m", "10/forever", "10/1m,burst=0", "10/1m,5"} {
+		_, err := ratelimit.ParsePolicy(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestMemoryStoreTokenBucket(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	store := ratelimit.NewMemoryStore()
+	store.Now = func() time.Time { return now }
+	policy := ratelimit.Policy{Limit: 3, Period: 3 * time.Second, Burst: 3}
+	ctx := context.Background()
+
+	for i := 2; i >= 0; i-- {
+		res, err := store.Allow(ctx, "client", policy)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, i, res.Remaining)
+	}
+
+	res, err := store.Allow(ctx, "client", policy)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed, "The burst is exhausted")
+	assert.Equal(t, 0, res.Remaining)
+	assert.Equal(t, time.Second, res.RetryAfter)
+	assert.Equal(t, 3*time.Second, res.Reset)
+
+	other, err := store.Allow(ctx, "other-client", policy)
+	require.NoError(t, err)
+	assert.True(t, other.Allowed, "Buckets are independent per key")
+
+	now = now.Add(time.Second)
+	res, err = store.Allow(ctx, "client", policy)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed, "One token refills per interval")
+
+	now = now.Add(time.Hour)
+	res, err = store.Allow(ctx, "client", policy)
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Remaining, "An idle bucket refills up to the burst, not beyond")
+}
+
+func TestPostgresStoreGCRA(t *testing.T) {
+	_, _, db := testutils.SetupTestRouter(true)
+	if db == nil {
+		t.Fatal("Database connection required for rate limit tests")
+	}
+	testutils.CleanupDatabase(db)
+	defer testutils.CleanupDatabase(db)
+
+	store := ratelimit.NewPostgresStore(db)
+	// A minute per token dwarfs the time between statements
+	policy := ratelimit.Policy{Limit: 3, Period: 3 * time.Minute, Burst: 3}
+	ctx := context.Background()
+
+	for i := 2; i >= 0; i-- {
+		res, err := store.Allow(ctx, "client", policy)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, i, res.Remaining)
+	}
+
+	res, err := store.Allow(ctx, "client", policy)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed, "The burst is exhausted")
+	assert.Equal(t, 0, res.Remaining)
+	assert.InDelta(t, time.Minute, res.RetryAfter, float64(time.Second))
+	assert.InDelta(t, 3*time.Minute, res.Reset, float64(time.Second))
+
+	var tat time.Time
+	require.NoError(t, db.Raw("SELECT tat FROM rate_limit_buckets WHERE key = ?", "client").Scan(&tat).Error)
+	res, err = store.Allow(ctx, "client", policy)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	var after time.Time
+	require.NoError(t, db.Raw("SELECT tat FROM rate_limit_buckets WHERE key = ?", "client").Scan(&after).Error)
+	assert.True(t, tat.Equal(after), "Denied requests do not move the bucket")
+
+	other, err := store.Allow(ctx, "other-client", policy)
+	require.NoError(t, err)
+	assert.True(t, other.Allowed, "Buckets are independent per key")
+}
+
+func TestPostgresStoreConcurrentRequests(t *testing.T) {
+	_, _, db := testutils.SetupTestRouter(true)
+	if db == nil {
+		t.Fatal("Database connection required for rate limit tests")
+	}
+	testutils.CleanupDatabase(db)
+	defer testutils.CleanupDatabase(db)
+
+	store := ratelimit.NewPostgresStore(db)
+	policy := ratelimit.Policy{Limit: 5, Period: 5 * time.Minute, Burst: 5}
+
+	var wg sync.WaitGroup
+	var allowed atomic.Int32
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := store.Allow(context.Background(), "client", policy)
+			if assert.NoError(t, err) && res.Allowed {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(5), allowed.Load(), "The upsert admits exactly the burst, however requests interleave")
+}
@@ -0,0 +1,100 @@
+package server_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/config"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/logger"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig() *config.Config {
+	return &config.Config{
+		ServerReadTimeout:  5 * time.Second,
+		ServerWriteTimeout: 5 * time.Second,
+		ShutdownTimeout:    2 * time.Second,
+	}
+}
+
+func TestGracefulShutdownDrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		_, _ = io.WriteString(w, "done")
+	})
+
+	var closed []string
+	srv := server.New(testConfig(), handler, logger.NewLogger("debug"))
+	srv.OnShutdown("first", func(ctx context.Context) error { closed = append(closed, "first"); return nil })
+	srv.OnShutdown("second", func(ctx context.Context) error { closed = append(closed, "second"); return nil })
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() { runErr <- srv.Serve(ctx, ln) }()
+
+	type result struct {
+		body string
+		err  error
+	}
+	resp := make(chan result, 1)
+	go func() {
+		res, err := http.Get("http://" + ln.Addr().String())
+		if err != nil {
+			resp <- result{err: err}
+			return
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		resp <- result{body: string(body)}
+	}()
+
+	<-started
+	cancel() // Simulate SIGTERM while the request is in flight
+
+	r := <-resp
+	require.NoError(t, r.err)
+	assert.Equal(t, "done", r.body, "In-flight request should complete during shutdown")
+
+	assert.NoError(t, <-runErr)
+	assert.Equal(t, []string{"second", "first"}, closed, "Dependencies should close in reverse order")
+
+	_, err = net.DialTimeout("tcp", ln.Addr().String(), 100*time.Millisecond)
+	assert.Error(t, err, "Listener should be closed after shutdown")
+}
+
+func TestShutdownDeadlineExceeded(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+	defer close(release)
+
+	cfg := testConfig()
+	cfg.ShutdownTimeout = 50 * time.Millisecond
+	srv := server.New(cfg, handler, logger.NewLogger("debug"))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() { runErr <- srv.Serve(ctx, ln) }()
+	go func() { _, _ = http.Get("http://" + ln.Addr().String()) }()
+
+	<-started
+	cancel()
+	assert.Error(t, <-runErr, "Exceeding the drain deadline should be reported")
+}
@@ -0,0 +1,208 @@
+package services_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/identity"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/requestid"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func auditActions(entries []models.AuditEntry) []string {
+	actions := make([]string, len(entries))
+	for i, e := range entries {
+		actions[i] = e.Action
+	}
+	return actions
+}
+
+func TestUserMutationsAreAudited(t *testing.T) {
+	repo := testutils.NewFakeUserRepository()
+	svc := services.NewUserService(repo, testutils.NewTestPasswords())
+	ctx := identity.WithPrincipal(context.Background(), &identity.Principal{UserID: 42, Email: "admin@example.com"})
+	ctx = requestid.WithContext(ctx, "req-1")
+	ctx = identity.WithClientIP(ctx, "203.0.113.7")
+
+	user, err := svc.CreateUser(ctx, &models.User{Name: "Jane Doe", Email: "jane@example.com", PasswordHash: "hash"})
+	require.NoError(t, err)
+	_, err = svc.UpdateUser(ctx, user.ID, &models.User{Name: "Jane Roe", Email: "jane@example.com"})
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteUser(ctx, user.ID, 0))
+	_, err = svc.RestoreUser(ctx, user.ID)
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteUser(ctx, user.ID, 0))
+	require.NoError(t, svc.PurgeUser(ctx, user.ID))
+
+	entries := repo.AuditLog().Entries()
+	assert.Equal(t, []string{
+		models.AuditUserCreated, models.AuditUserUpdated, models.AuditUserDeleted,
+		models.AuditUserRestored, models.AuditUserDeleted, models.AuditUserPurged,
+	}, auditActions(entries))
+
+	for _, e := range entries {
+		assert.Equal(t, models.AuditActorUser, e.ActorType)
+		require.NotNil(t, e.ActorID)
+		assert.Equal(t, uint(42), *e.ActorID)
+		assert.Equal(t, models.AuditTargetUser, e.TargetType)
+		require.NotNil(t, e.TargetID)
+		assert.Equal(t, user.ID, *e.TargetID)
+		assert.Equal(t, "req-1", e.RequestID)
+		assert.Equal(t, "203.0.113.7", e.IP)
+	}
+
+	created := entries[0].Changes
+	assert.Equal(t, models.AuditChange{Before: nil, After: "jane@example.com"}, created["email"])
+	assert.Equal(t, models.AuditChange{Before: nil, After: "[redacted]"}, created["password"], "Password hashes are never recorded")
+
+	assert.Equal(t, models.AuditChanges{
+		"name": {Before: "Jane Doe", After: "Jane Roe"},
+	}, entries[1].Changes, "Only changed fields are recorded")
+
+	assert.Nil(t, entries[2].Changes["deleted_at"].Before)
+	assert.NotNil(t, entries[2].Changes["deleted_at"].After)
+	assert.Nil(t, entries[3].Changes["deleted_at"].After)
+	assert.Equal(t, models.AuditChange{Before: "Jane Roe", After: nil}, entries[5].Changes["name"])
+
+	result, err := services.NewAuditService(repo.Audit()).VerifyAuditChain(ctx)
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, int64(6), result.Checked)
+	assert.Equal(t, entries[5].Hash, result.Head)
+}
+
+func TestAuditActorWithoutPrincipal(t *testing.T) {
+	repo := testutils.NewFakeUserRepository()
+	svc := services.NewUserService(repo, testutils.NewTestPasswords())
+
+	// Open registration comes from a request, but nobody is signed in
+	_, err := svc.CreateUser(identity.WithClientIP(context.Background(), "203.0.113.7"),
+		&models.User{Name: "Jane Doe", Email: "jane@example.com"})
+	require.NoError(t, err)
+	// The retention purge runs outside any request
+	require.NoError(t, svc.DeleteUser(context.Background(), 1, 0))
+
+	entries := repo.AuditLog().Entries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, models.AuditActorAnonymous, entries[0].ActorType)
+	assert.Nil(t, entries[0].ActorID)
+	assert.Equal(t, models.AuditActorSystem, entries[1].ActorType)
+}
+
+func TestFailedMutationsAreNotAudited(t *testing.T) {
+	repo := testutils.NewFakeUserRepository(models.User{Name: "Jane Doe", Email: "jane@example.com"})
+	svc := services.NewUserService(repo, testutils.NewTestPasswords())
+	ctx := context.Background()
+
+	_, err := svc.UpdateUser(ctx, 1, &models.User{Name: "Jane Roe", Email: "jane@example.com", Version: 7})
+	assert.Equal(t, services.ErrVersionMismatch, err)
+
+	report, err := svc.ImportUsers(ctx, slices.Values([]services.ImportRow{
+		{Line: 2, Name: "New User", Email: "new@example.com"},
+		{Line: 3, Name: "Jane Again", Email: "jane@example.com"},
+	}), services.ImportOptions{Mode: services.ImportModeAll})
+	require.NoError(t, err)
+	assert.True(t, report.RolledBack)
+
+	assert.Empty(t, repo.AuditLog().Entries(), "Rolled back changes leave no audit entries")
+}
+
+func TestImportAndRetentionAreAudited(t *testing.T) {
+	repo := testutils.NewFakeUserRepository(models.User{Name: "Old User", Email: "old@example.com"})
+	svc := services.NewUserService(repo, testutils.NewTestPasswords())
+	ctx := context.Background()
+
+	report, err := svc.ImportUsers(ctx, slices.Values([]services.ImportRow{
+		{Line: 2, Name: "New One", Email: "one@example.com"},
+		{Line: 3, Name: "New Two", Email: "two@example.com"},
+	}), services.ImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Imported)
+
+	require.NoError(t, svc.DeleteUser(ctx, 1, 0))
+	repo.SetDeletedAt(1, time.Now().Add(-48*time.Hour))
+	purged, err := svc.PurgeDeletedUsers(ctx, time.Now().Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	entries := repo.AuditLog().Entries()
+	assert.Equal(t, []string{
+		models.AuditUserImported, models.AuditUserImported, models.AuditUserDeleted, models.AuditUserPurged,
+	}, auditActions(entries))
+	assert.Equal(t, uint(2), *entries[0].TargetID, "Imported users are audited by ID")
+	assert.Equal(t, models.AuditChange{Before: "old@example.com", After: nil}, entries[3].Changes["email"])
+}
+
+func TestAuditChainDetectsTampering(t *testing.T) {
+	repo := testutils.NewFakeUserRepository()
+	svc := services.NewUserService(repo, testutils.NewTestPasswords())
+	audit := services.NewAuditService(repo.Audit())
+	ctx := context.Background()
+
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		_, err := svc.CreateUser(ctx, &models.User{Name: "User", Email: email})
+		require.NoError(t, err)
+	}
+
+	t.Run("Modified Entry", func(t *testing.T) {
+		repo.AuditLog().Tamper(2, func(e *models.AuditEntry) {
+			e.Changes["email"] = models.AuditChange{After: "mallory@example.com"}
+		})
+		result, err := audit.VerifyAuditChain(ctx)
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+		require.NotNil(t, result.BrokenAt)
+		assert.Equal(t, uint(2), *result.BrokenAt)
+		assert.Equal(t, int64(1), result.Checked)
+	})
+
+	t.Run("Resealed Entry", func(t *testing.T) {
+		// Recomputing the edited entry's hash breaks the link from the next one
+		repo.AuditLog().Tamper(2, func(e *models.AuditEntry) {
+			require.NoError(t, e.Seal(e.PrevHash))
+		})
+		result, err := audit.VerifyAuditChain(ctx)
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+		require.NotNil(t, result.BrokenAt)
+		assert.Equal(t, uint(3), *result.BrokenAt)
+	})
+}
+
+func TestListAuditEntries(t *testing.T) {
+	repo := testutils.NewFakeUserRepository()
+	svc := services.NewUserService(repo, testutils.NewTestPasswords())
+	audit := services.NewAuditService(repo.Audit())
+	ctx := context.Background()
+
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		_, err := svc.CreateUser(ctx, &models.User{Name: "User", Email: email})
+		require.NoError(t, err)
+	}
+	require.NoError(t, svc.DeleteUser(ctx, 2, 0))
+
+	page, err := audit.ListEntries(ctx, services.AuditPageQuery{Limit: 3})
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 3)
+	assert.Equal(t, uint(4), page.Entries[0].ID, "Newest entries come first")
+	assert.Equal(t, uint(2), page.NextBefore)
+
+	page, err = audit.ListEntries(ctx, services.AuditPageQuery{Before: page.NextBefore, Limit: 3})
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 1)
+	assert.Zero(t, page.NextBefore)
+
+	target := uint(2)
+	page, err = audit.ListEntries(ctx, services.AuditPageQuery{
+		Query: repositories.AuditQuery{TargetID: &target}, Limit: 10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.AuditUserDeleted, models.AuditUserCreated}, auditActions(page.Entries))
+}
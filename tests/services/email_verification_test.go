@@ -0,0 +1,184 @@
+package services_test
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/events"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/mailer"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/jobs"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/jobs/runner"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type verificationFixture struct {
+	repo         *testutils.FakeUserRepository
+	users        services.UserService
+	verification services.EmailVerificationService
+	mail         *mailer.MemoryMailer
+	worker       *runner.Worker
+}
+
+func newVerificationFixture(users ...models.User) *verificationFixture {
+	f := &verificationFixture{repo: testutils.NewFakeUserRepository(users...), mail: mailer.NewMemoryMailer()}
+	f.users = services.NewUserService(f.repo, testutils.NewTestPasswords())
+	tokens := testutils.NewTestJWTManager()
+	f.verification = services.NewEmailVerificationService(f.repo, tokens, services.EmailVerificationOptions{
+		Mailer:    f.mail,
+		VerifyURL: "https://app.example.com/api/verify-email",
+	})
+	registry := jobs.NewRegistry()
+	passwords := services.NewPasswordService(f.repo, tokens, services.PasswordServiceOptions{})
+	services.RegisterUserJobs(registry, f.users, f.verification, passwords)
+	f.worker = runner.NewWorker(f.repo.JobStore(), registry, runner.WorkerOptions{ID: "w1", Concurrency: 10, Lease: time.Minute}, nil)
+	return f
+}
+
+// deliver runs the queued jobs and returns the messages sent so far.
+func (f *verificationFixture) deliver(t *testing.T) []mailer.Message {
+	t.Helper()
+	_, err := f.worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	for _, j := range f.repo.JobStore().Jobs() {
+		require.Equal(t, jobs.StateSucceeded, j.State, j.LastError)
+	}
+	return f.mail.Messages()
+}
+
+var verifyLink = regexp.MustCompile(`https://app\.example\.com/api/verify-email\?token=(\S+)`)
+
+func tokenFrom(t *testing.T, msg mailer.Message) string {
+	t.Helper()
+	m := verifyLink.FindStringSubmatch(msg.Text)
+	require.NotNil(t, m, msg.Text)
+	token, err := url.QueryUnescape(m[1])
+	require.NoError(t, err)
+	return token
+}
+
+func TestEmailVerificationFlow(t *testing.T) {
+	f := newVerificationFixture()
+	ctx := context.Background()
+
+	user, err := f.users.CreateUser(ctx, &models.User{Name: "Jane Doe", Email: "jane@example.com"})
+	require.NoError(t, err)
+	assert.Nil(t, user.EmailVerifiedAt)
+	require.Len(t, f.repo.JobStore().Jobs(), 1, "Creating a user queues a verification email")
+
+	sent := f.deliver(t)
+	require.Len(t, sent, 1)
+	assert.Equal(t, []string{"jane@example.com"}, sent[0].To)
+	token := tokenFrom(t, sent[0])
+	require.Len(t, f.repo.VerificationTokens().Tokens(), 1)
+
+	verified, err := f.verification.VerifyEmail(ctx, token)
+	require.NoError(t, err)
+	require.NotNil(t, verified.EmailVerifiedAt)
+	assert.Equal(t, user.Version+1, verified.Version)
+
+	_, err = f.verification.VerifyEmail(ctx, token)
+	assert.ErrorIs(t, err, services.ErrInvalidVerificationToken, "Tokens are single use")
+	assert.ErrorIs(t, f.verification.SendVerification(ctx, user.ID), services.ErrEmailAlreadyVerified)
+
+	assert.Equal(t, []string{events.TypeUserCreated, events.TypeUserEmailVerified}, outboxTypes(f.repo.OutboxLog().Messages()))
+	entries := f.repo.AuditLog().Entries()
+	assert.Equal(t, models.AuditUserEmailVerified, entries[len(entries)-1].Action)
+}
+
+func TestEmailChangeRequiresReverification(t *testing.T) {
+	f := newVerificationFixture()
+	ctx := context.Background()
+	user, err := f.users.CreateUser(ctx, &models.User{Name: "Jane Doe", Email: "jane@example.com"})
+	require.NoError(t, err)
+	first := tokenFrom(t, f.deliver(t)[0])
+	_, err = f.verification.VerifyEmail(ctx, first)
+	require.NoError(t, err)
+
+	// Renaming keeps the address verified
+	renamed, err := f.users.UpdateUser(ctx, user.ID, &models.User{Name: "Jane Roe", Email: "jane@example.com"})
+	require.NoError(t, err)
+	assert.NotNil(t, renamed.EmailVerifiedAt)
+	assert.Len(t, f.repo.JobStore().Jobs(), 1)
+
+	changed, err := f.users.UpdateUser(ctx, user.ID, &models.User{Name: "Jane Roe", Email: "roe@example.com"})
+	require.NoError(t, err)
+	assert.Nil(t, changed.EmailVerifiedAt, "A new address is unverified")
+	require.Len(t, f.repo.JobStore().Jobs(), 2, "Changing the email queues a verification email")
+
+	sent := f.deliver(t)
+	require.Len(t, sent, 2)
+	assert.Equal(t, []string{"roe@example.com"}, sent[1].To)
+	verified, err := f.verification.VerifyEmail(ctx, tokenFrom(t, sent[1]))
+	require.NoError(t, err)
+	assert.Equal(t, "roe@example.com", verified.Email)
+	assert.NotNil(t, verified.EmailVerifiedAt)
+}
+
+func TestVerificationTokenForOldAddressIsRejected(t *testing.T) {
+	f := newVerificationFixture()
+	ctx := context.Background()
+	user, err := f.users.CreateUser(ctx, &models.User{Name: "Jane Doe", Email: "jane@example.com"})
+	require.NoError(t, err)
+	token := tokenFrom(t, f.deliver(t)[0])
+
+	_, err = f.users.UpdateUser(ctx, user.ID, &models.User{Name: "Jane Doe", Email: "roe@example.com"})
+	require.NoError(t, err)
+	_, err = f.verification.VerifyEmail(ctx, token)
+	assert.ErrorIs(t, err, services.ErrInvalidVerificationToken)
+
+	stored, err := f.users.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Nil(t, stored.EmailVerifiedAt)
+}
+
+func TestOutdatedVerificationEmailsAreSkipped(t *testing.T) {
+	f := newVerificationFixture(models.User{Name: "Jane Doe", Email: "jane@example.com"})
+	ctx := context.Background()
+
+	require.NoError(t, f.verification.SendVerification(ctx, 1))
+	require.NoError(t, f.verification.SendVerification(ctx, 1))
+	require.Len(t, f.repo.JobStore().Jobs(), 1, "A pending email to the same address is not queued twice")
+
+	// The address changes before the worker gets to the first email
+	_, err := f.users.UpdateUser(ctx, 1, &models.User{Name: "Jane Doe", Email: "roe@example.com"})
+	require.NoError(t, err)
+
+	sent := f.deliver(t)
+	require.Len(t, sent, 1)
+	assert.Equal(t, []string{"roe@example.com"}, sent[0].To)
+}
+
+func TestVerifyEmailRejectsInvalidTokens(t *testing.T) {
+	f := newVerificationFixture(models.User{Name: "Jane Doe", Email: "jane@example.com"})
+	ctx := context.Background()
+
+	_, err := f.verification.VerifyEmail(ctx, "not-a-token")
+	assert.ErrorIs(t, err, services.ErrInvalidVerificationToken)
+
+	user, err := f.users.GetUserByID(ctx, 1)
+	require.NoError(t, err)
+	access, _, err := testutils.NewTestJWTManager().GenerateAccessToken(user)
+	require.NoError(t, err)
+	_, err = f.verification.VerifyEmail(ctx, access)
+	assert.ErrorIs(t, err, services.ErrInvalidVerificationToken, "Access tokens do not verify emails")
+
+	// A correctly signed token that was never issued by the worker
+	unissued, _, err := testutils.NewTestJWTManager().GenerateEmailVerificationToken(user)
+	require.NoError(t, err)
+	_, err = f.verification.VerifyEmail(ctx, unissued)
+	assert.ErrorIs(t, err, services.ErrInvalidVerificationToken)
+}
+
+func TestDeliverVerificationWithoutMailerFailsPermanently(t *testing.T) {
+	repo := testutils.NewFakeUserRepository(models.User{Name: "Jane Doe", Email: "jane@example.com"})
+	svc := services.NewEmailVerificationService(repo, testutils.NewTestJWTManager(), services.EmailVerificationOptions{})
+	err := svc.DeliverVerification(context.Background(), services.SendVerificationEmailArgs{UserID: 1, Email: "jane@example.com"})
+	assert.True(t, jobs.IsPermanent(err))
+}
@@ -0,0 +1,150 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/events"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/requestid"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func outboxTypes(msgs []models.OutboxMessage) []string {
+	types := make([]string, len(msgs))
+	for i, m := range msgs {
+		types[i] = m.EventType
+	}
+	return types
+}
+
+func TestUserChangesWriteOutboxEvents(t *testing.T) {
+	repo := testutils.NewFakeUserRepository()
+	svc := services.NewUserService(repo, testutils.NewTestPasswords())
+	ctx := requestid.WithContext(context.Background(), "req-events")
+
+	user, err := svc.CreateUser(ctx, &models.User{Name: "Jane Doe", Email: "jane@example.com"})
+	require.NoError(t, err)
+	_, err = svc.UpdateUser(ctx, user.ID, &models.User{Name: "Jane Roe", Email: "jane@example.com"})
+	require.NoError(t, err)
+	_, err = svc.UpdateUser(ctx, user.ID, &models.User{Name: "Jane Roe", Email: "roe@example.com"})
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteUser(ctx, user.ID, 0))
+	_, err = svc.RestoreUser(ctx, user.ID)
+	require.NoError(t, err)
+
+	msgs := repo.OutboxLog().Messages()
+	assert.Equal(t, []string{events.TypeUserCreated, events.TypeUserEmailChanged, events.TypeUserDeleted}, outboxTypes(msgs),
+		"Name changes and restores raise no events")
+	for _, m := range msgs {
+		assert.Equal(t, events.AggregateUser, m.AggregateType)
+		assert.Equal(t, user.ID, m.AggregateID)
+		assert.Equal(t, "req-events", m.RequestID)
+	}
+
+	changed, err := events.Decode(events.Envelope{Type: msgs[1].EventType, Payload: msgs[1].Payload})
+	require.NoError(t, err)
+	assert.Equal(t, &events.UserEmailChanged{UserID: user.ID, OldEmail: "jane@example.com", NewEmail: "roe@example.com"}, changed)
+}
+
+func TestRolledBackChangesWriteNoEvents(t *testing.T) {
+	repo := testutils.NewFakeUserRepository(models.User{Name: "Jane Doe", Email: "jane@example.com"})
+	svc := services.NewUserService(repo, testutils.NewTestPasswords())
+
+	report, err := svc.ImportUsers(context.Background(), slices.Values([]services.ImportRow{
+		{Line: 2, Name: "New User", Email: "new@example.com"},
+		{Line: 3, Name: "Jane Again", Email: "jane@example.com"},
+	}), services.ImportOptions{Mode: services.ImportModeAll})
+	require.NoError(t, err)
+	require.True(t, report.RolledBack)
+	assert.Empty(t, repo.OutboxLog().Messages())
+
+	report, err = svc.ImportUsers(context.Background(), slices.Values([]services.ImportRow{
+		{Line: 2, Name: "New User", Email: "new@example.com"},
+	}), services.ImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{events.TypeUserCreated}, outboxTypes(repo.OutboxLog().Messages()))
+}
+
+func TestOutboxRelayPublishes(t *testing.T) {
+	repo := testutils.NewFakeUserRepository()
+	svc := services.NewUserService(repo, testutils.NewTestPasswords())
+	ctx := context.Background()
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		_, err := svc.CreateUser(ctx, &models.User{Name: "User", Email: email})
+		require.NoError(t, err)
+	}
+
+	bus := events.NewBus()
+	var received []events.Envelope
+	bus.Subscribe(events.TypeUserCreated, func(ctx context.Context, env events.Envelope) error {
+		received = append(received, env)
+		return nil
+	})
+	relay := services.NewOutboxRelay(repo.Outbox(), bus, services.OutboxRelayOptions{BatchSize: 2})
+
+	n, err := relay.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n, "A round claims at most BatchSize messages")
+	n, err = relay.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	n, err = relay.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "Published messages are not claimed again")
+
+	require.Len(t, received, 3)
+	created, err := events.Decode(received[0])
+	require.NoError(t, err)
+	assert.Equal(t, "a@example.com", created.(*events.UserCreated).Email)
+	assert.NotEmpty(t, received[0].ID)
+	for _, m := range repo.OutboxLog().Messages() {
+		assert.NotNil(t, m.PublishedAt)
+		assert.Equal(t, 1, m.Attempts)
+	}
+}
+
+func TestOutboxRelayRetriesWithBackoff(t *testing.T) {
+	repo := testutils.NewFakeUserRepository()
+	outbox := repo.OutboxLog()
+	_, err := services.NewUserService(repo, testutils.NewTestPasswords()).CreateUser(context.Background(), &models.User{Name: "Jane Doe", Email: "jane@example.com"})
+	require.NoError(t, err)
+
+	fail := true
+	publisher := events.PublisherFunc(func(ctx context.Context, env events.Envelope) error {
+		if fail {
+			return errors.New("broker unavailable")
+		}
+		return nil
+	})
+	relay := services.NewOutboxRelay(outbox, publisher, services.OutboxRelayOptions{BatchSize: 10})
+
+	n, err := relay.RunOnce(context.Background())
+	require.NoError(t, err, "Publish failures are recorded on the message, not returned")
+	assert.Equal(t, 1, n)
+	msg := outbox.Messages()[0]
+	assert.Nil(t, msg.PublishedAt)
+	assert.Equal(t, 1, msg.Attempts)
+	assert.Equal(t, "broker unavailable", msg.LastError)
+	assert.WithinDuration(t, time.Now().Add(time.Second), msg.AvailableAt, 500*time.Millisecond)
+
+	n, err = relay.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n, "The message waits out its backoff")
+
+	fail = false
+	outbox.Now = func() time.Time { return time.Now().Add(2 * time.Second) }
+	n, err = relay.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	msg = outbox.Messages()[0]
+	assert.NotNil(t, msg.PublishedAt)
+	assert.Equal(t, 2, msg.Attempts)
+	assert.Empty(t, msg.LastError)
+}
@@ -0,0 +1,363 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/config"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/apperror"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/auth"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/mailer"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/jobs"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/jobs/runner"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fieldCodes(t *testing.T, err error) []string {
+	t.Helper()
+	var appErr *apperror.Error
+	require.True(t, errors.As(err, &appErr), err)
+	codes := make([]string, len(appErr.Fields))
+	for i, f := range appErr.Fields {
+		codes[i] = f.Code
+	}
+	return codes
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	policy := services.PasswordPolicy{}
+	user := &models.User{Name: "Jane Doe", Email: "jdoe1984@example.com"}
+
+	for password, code := range map[string]string{
+		"short-pass":         "too_short",
+		"Password2024!":      "too_common",
+		"qwertyuiop12":       "too_common",
+		"aaaaaaaaaaaa":       "too_common",
+		"123456789012":       "too_common",
+		"7890123456789":      "too_common",
+		"lkjihgfedcba":       "too_common",
+		"my name is jane!!":  "personal_info",
+		"JDOE1984-is-secret": "personal_info",
+	} {
+		problems := policy.Validate("password", password, user)
+		require.Len(t, problems, 1, password)
+		assert.Equal(t, code, problems[0].Code, password)
+		assert.Equal(t, "password", problems[0].Field)
+	}
+
+	assert.Empty(t, policy.Validate("password", "correct-horse-battery", user))
+	assert.Empty(t, policy.Validate("password", "my name is jane!!", nil), "Personal info needs a user")
+	assert.Empty(t, services.PasswordPolicy{MinLength: 8}.Validate("password", "tr0ub4dor", nil))
+}
+
+func TestCreateUserHashesPassword(t *testing.T) {
+	repo := testutils.NewFakeUserRepository()
+	svc := services.NewUserService(repo, testutils.NewTestPasswords())
+	ctx := context.Background()
+
+	user, err := svc.CreateUser(ctx, &models.User{Name: "Jane Doe", Email: "jane@example.com", Password: "correct-horse-battery"})
+	require.NoError(t, err)
+	assert.Empty(t, user.Password, "The plaintext is not kept")
+	ok, _, err := testutils.NewTestPasswordHasher().Verify(context.Background(), user.PasswordHash, "correct-horse-battery")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = svc.CreateUser(ctx, &models.User{Name: "John Doe", Email: "john@example.com", Password: "password"})
+	assert.ErrorIs(t, err, services.ErrWeakPassword)
+	assert.Equal(t, []string{"too_short"}, fieldCodes(t, err))
+	_, err = svc.CreateUser(ctx, &models.User{Name: "John Doe", Email: "john@example.com"})
+	assert.NoError(t, err, "Users may be created without a password")
+}
+
+func TestImportRejectsPasswordsPerRow(t *testing.T) {
+	repo := testutils.NewFakeUserRepository()
+	svc := services.NewUserService(repo, testutils.NewTestPasswords())
+	rows := slices.Values([]services.ImportRow{
+		{Line: 2, Name: "Ada Lovelace", Email: "ada@example.com", Password: "correct-horse-battery"},
+		{Line: 3, Name: "Grace Hopper", Email: "grace@example.com", Password: "qwerty123456"},
+		{Line: 4, Name: "Alan Turing", Email: "alan@example.com", Password: strings.Repeat("x", 1025)},
+	})
+
+	report, err := svc.ImportUsers(context.Background(), rows, services.ImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Imported)
+	require.Len(t, report.Errors, 2)
+	assert.Equal(t, "too_common", report.Errors[0].Errors[0].Code)
+	codes := []string{}
+	for _, e := range report.Errors[1].Errors {
+		codes = append(codes, e.Code)
+	}
+	assert.Contains(t, codes, "too_long", "An overlong password fails its row, not the import")
+}
+
+type passwordFixture struct {
+	repo      *testutils.FakeUserRepository
+	refresh   *testutils.FakeRefreshTokenRepository
+	passwords services.PasswordService
+	mail      *mailer.MemoryMailer
+	worker    *runner.Worker
+	user      *models.User
+}
+
+func newPasswordFixture(t *testing.T) *passwordFixture {
+	t.Helper()
+	hash, err := testutils.NewTestPasswordHasher().Hash(context.Background(), "correct-horse-battery")
+	require.NoError(t, err)
+	f := &passwordFixture{
+		repo: testutils.NewFakeUserRepository(models.User{Name: "Jane Doe", Email: "jane@example.com", PasswordHash: hash}),
+		mail: mailer.NewMemoryMailer(),
+	}
+	f.refresh = f.repo.SessionTokens()
+	f.user, err = f.repo.GetByID(context.Background(), 1)
+	require.NoError(t, err)
+	require.NoError(t, f.refresh.Create(context.Background(), &models.RefreshToken{
+		UserID: f.user.ID, TokenID: "session", ExpiresAt: time.Now().Add(time.Hour),
+	}))
+
+	tokens := testutils.NewTestJWTManager()
+	f.passwords = services.NewPasswordService(f.repo, tokens, services.PasswordServiceOptions{
+		Passwords: testutils.NewTestPasswords(),
+		Mailer:    f.mail,
+		ResetURL:  "https://app.example.com/reset-password",
+	})
+	registry := jobs.NewRegistry()
+	users := services.NewUserService(f.repo, testutils.NewTestPasswords())
+	services.RegisterUserJobs(registry, users, services.NewEmailVerificationService(f.repo, tokens, services.EmailVerificationOptions{}), f.passwords)
+	f.worker = runner.NewWorker(f.repo.JobStore(), registry, runner.WorkerOptions{ID: "w1", Concurrency: 10, Lease: time.Minute}, nil)
+	return f
+}
+
+// deliver runs the queued jobs and returns the messages sent so far.
+func (f *passwordFixture) deliver(t *testing.T) []mailer.Message {
+	t.Helper()
+	_, err := f.worker.RunOnce(context.Background())
+	require.NoError(t, err)
+	for _, j := range f.repo.JobStore().Jobs() {
+		require.Equal(t, jobs.StateSucceeded, j.State, j.LastError)
+	}
+	return f.mail.Messages()
+}
+
+// verify reports whether password is the user's current password.
+func (f *passwordFixture) verify(t *testing.T, password string) bool {
+	t.Helper()
+	user, err := f.repo.GetByID(context.Background(), f.user.ID)
+	require.NoError(t, err)
+	ok, _, err := testutils.NewTestPasswordHasher().Verify(context.Background(), user.PasswordHash, password)
+	require.NoError(t, err)
+	return ok
+}
+
+func (f *passwordFixture) sessionRevoked(t *testing.T) bool {
+	t.Helper()
+	token, err := f.refresh.GetByTokenID(context.Background(), "session")
+	require.NoError(t, err)
+	return token.RevokedAt != nil
+}
+
+var resetLink = regexp.MustCompile(`https://app\.example\.com/reset-password\?token=(\S+)`)
+
+func resetTokenFrom(t *testing.T, msg mailer.Message) string {
+	t.Helper()
+	m := resetLink.FindStringSubmatch(msg.Text)
+	require.NotNil(t, m, msg.Text)
+	token, err := url.QueryUnescape(m[1])
+	require.NoError(t, err)
+	return token
+}
+
+func TestPasswordResetFlow(t *testing.T) {
+	f := newPasswordFixture(t)
+	ctx := context.Background()
+
+	require.NoError(t, f.passwords.ForgotPassword(ctx, "jane@example.com"))
+	require.NoError(t, f.passwords.ForgotPassword(ctx, "jane@example.com"))
+	require.Len(t, f.repo.JobStore().Jobs(), 1, "A pending reset email is not queued twice")
+
+	sent := f.deliver(t)
+	require.Len(t, sent, 1)
+	assert.Equal(t, []string{"jane@example.com"}, sent[0].To)
+	token := resetTokenFrom(t, sent[0])
+
+	err := f.passwords.ResetPassword(ctx, token, "password")
+	assert.ErrorIs(t, err, services.ErrWeakPassword)
+	assert.False(t, f.sessionRevoked(t))
+
+	require.NoError(t, f.passwords.ResetPassword(ctx, token, "new-horse-battery"))
+	assert.True(t, f.verify(t, "new-horse-battery"))
+	assert.True(t, f.sessionRevoked(t), "Resetting the password signs the user out")
+
+	assert.ErrorIs(t, f.passwords.ResetPassword(ctx, token, "other-horse-battery"), services.ErrInvalidResetToken,
+		"Tokens are single use")
+	assert.True(t, f.verify(t, "new-horse-battery"))
+
+	entries := f.repo.AuditLog().Entries()
+	last := entries[len(entries)-1]
+	assert.Equal(t, models.AuditUserPasswordReset, last.Action)
+	assert.NotContains(t, last.Changes["password"].After, "argon2id", "The audit log never holds hashes")
+	assert.Empty(t, f.repo.OutboxLog().Messages(), "Password changes raise no domain events")
+}
+
+func TestForgotPasswordForUnknownEmail(t *testing.T) {
+	f := newPasswordFixture(t)
+	require.NoError(t, f.passwords.ForgotPassword(context.Background(), "nobody@example.com"))
+	assert.Empty(t, f.repo.JobStore().Jobs())
+}
+
+func TestResettingVoidsOtherResetTokens(t *testing.T) {
+	f := newPasswordFixture(t)
+	ctx := context.Background()
+	args := services.SendPasswordResetEmailArgs{UserID: f.user.ID, Email: f.user.Email}
+	require.NoError(t, f.passwords.DeliverPasswordReset(ctx, args))
+	require.NoError(t, f.passwords.DeliverPasswordReset(ctx, args))
+	sent := f.mail.Messages()
+	require.Len(t, sent, 2)
+
+	require.NoError(t, f.passwords.ResetPassword(ctx, resetTokenFrom(t, sent[1]), "new-horse-battery"))
+	assert.ErrorIs(t, f.passwords.ResetPassword(ctx, resetTokenFrom(t, sent[0]), "other-horse-battery"), services.ErrInvalidResetToken)
+}
+
+func TestResetPasswordRejectsInvalidTokens(t *testing.T) {
+	f := newPasswordFixture(t)
+	ctx := context.Background()
+	assert.ErrorIs(t, f.passwords.ResetPassword(ctx, "not-a-token", "new-horse-battery"), services.ErrInvalidResetToken)
+
+	// A verification token is signed with another secret
+	verification, _, err := testutils.NewTestJWTManager().GenerateEmailVerificationToken(f.user)
+	require.NoError(t, err)
+	assert.ErrorIs(t, f.passwords.ResetPassword(ctx, verification, "new-horse-battery"), services.ErrInvalidResetToken)
+
+	// A well-signed token that was never issued through DeliverPasswordReset
+	unissued, _, err := testutils.NewTestJWTManager().GeneratePasswordResetToken(f.user)
+	require.NoError(t, err)
+	assert.ErrorIs(t, f.passwords.ResetPassword(ctx, unissued, "new-horse-battery"), services.ErrInvalidResetToken)
+	assert.True(t, f.verify(t, "correct-horse-battery"))
+}
+
+func TestChangingEmailVoidsResetTokens(t *testing.T) {
+	f := newPasswordFixture(t)
+	ctx := context.Background()
+	require.NoError(t, f.passwords.DeliverPasswordReset(ctx, services.SendPasswordResetEmailArgs{UserID: f.user.ID, Email: f.user.Email}))
+	token := resetTokenFrom(t, f.mail.Messages()[0])
+
+	users := services.NewUserService(f.repo, testutils.NewTestPasswords())
+	_, err := users.UpdateUser(ctx, f.user.ID, &models.User{Name: f.user.Name, Email: "new@example.com"})
+	require.NoError(t, err)
+	assert.ErrorIs(t, f.passwords.ResetPassword(ctx, token, "new-horse-battery"), services.ErrInvalidResetToken,
+		"A link sent to the old address no longer works")
+	assert.True(t, f.verify(t, "correct-horse-battery"))
+}
+
+func TestResetPasswordChecksTheTokenEmail(t *testing.T) {
+	f := newPasswordFixture(t)
+	ctx := context.Background()
+	// A token issued for an address the user had before, but not voided
+	token, claims, err := testutils.NewTestJWTManager().GeneratePasswordResetToken(f.user)
+	require.NoError(t, err)
+	require.NoError(t, f.repo.PasswordResets().Create(ctx, &models.PasswordResetToken{
+		UserID: f.user.ID, TokenID: claims.ID, Email: "old@example.com", ExpiresAt: claims.ExpiresAt.Time,
+	}))
+	assert.ErrorIs(t, f.passwords.ResetPassword(ctx, token, "new-horse-battery"), services.ErrInvalidResetToken)
+	assert.True(t, f.verify(t, "correct-horse-battery"))
+	assert.False(t, f.sessionRevoked(t))
+}
+
+func TestOutdatedPasswordResetEmailsAreSkipped(t *testing.T) {
+	f := newPasswordFixture(t)
+	ctx := context.Background()
+	require.NoError(t, f.passwords.DeliverPasswordReset(ctx, services.SendPasswordResetEmailArgs{UserID: f.user.ID, Email: "old@example.com"}))
+	require.NoError(t, f.passwords.DeliverPasswordReset(ctx, services.SendPasswordResetEmailArgs{UserID: 42, Email: "jane@example.com"}))
+	assert.Empty(t, f.mail.Messages())
+	assert.Empty(t, f.repo.ResetTokens().Tokens())
+}
+
+func TestChangePassword(t *testing.T) {
+	f := newPasswordFixture(t)
+	ctx := context.Background()
+
+	_, err := f.passwords.ChangePassword(ctx, f.user.ID, "wrong-horse-battery", "new-horse-battery")
+	assert.ErrorIs(t, err, services.ErrIncorrectPassword)
+	assert.Equal(t, []string{"incorrect"}, fieldCodes(t, err))
+	_, err = f.passwords.ChangePassword(ctx, f.user.ID, "correct-horse-battery", "jane-doe-password")
+	assert.ErrorIs(t, err, services.ErrWeakPassword)
+	assert.Equal(t, []string{"personal_info"}, fieldCodes(t, err))
+	_, err = f.passwords.ChangePassword(ctx, 42, "correct-horse-battery", "new-horse-battery")
+	assert.ErrorIs(t, err, services.ErrUserNotFound)
+	assert.False(t, f.sessionRevoked(t))
+
+	require.NoError(t, f.passwords.DeliverPasswordReset(ctx, services.SendPasswordResetEmailArgs{UserID: f.user.ID, Email: f.user.Email}))
+	user, err := f.passwords.ChangePassword(ctx, f.user.ID, "correct-horse-battery", "new-horse-battery")
+	require.NoError(t, err)
+	assert.Equal(t, f.user.Version+1, user.Version)
+	assert.True(t, f.verify(t, "new-horse-battery"))
+	assert.True(t, f.sessionRevoked(t), "Changing the password signs the user out")
+	assert.ErrorIs(t, f.passwords.ResetPassword(ctx, resetTokenFrom(t, f.mail.Messages()[0]), "other-horse-battery"),
+		services.ErrInvalidResetToken, "Earlier reset links stop working")
+
+	entries := f.repo.AuditLog().Entries()
+	assert.Equal(t, models.AuditUserPasswordChanged, entries[len(entries)-1].Action)
+}
+
+func TestLoginRehashesOutdatedPasswords(t *testing.T) {
+	old, err := auth.NewPasswordHasher(&config.Config{PasswordHashAlgorithm: auth.Bcrypt, PasswordBcryptCost: 4, PasswordHashConcurrency: 1})
+	require.NoError(t, err)
+	hash, err := old.Hash(context.Background(), "correct-horse-battery")
+	require.NoError(t, err)
+	repo := testutils.NewFakeUserRepository(models.User{Name: "Jane Doe", Email: "jane@example.com", PasswordHash: hash})
+
+	current := testutils.NewTestPasswordHasher()
+	svc := services.NewAuthService(repo, testutils.NewFakeRefreshTokenRepository(), testutils.NewTestJWTManager(), current)
+	ctx := context.Background()
+
+	_, err = svc.Login(ctx, "jane@example.com", "wrong-horse-battery")
+	assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+	user, _ := repo.GetByID(ctx, 1)
+	assert.Equal(t, hash, user.PasswordHash, "A failed login changes nothing")
+
+	_, err = svc.Login(ctx, "jane@example.com", "correct-horse-battery")
+	require.NoError(t, err)
+	user, _ = repo.GetByID(ctx, 1)
+	assert.True(t, strings.HasPrefix(user.PasswordHash, "$argon2id$"), "The bcrypt hash is replaced")
+	assert.Equal(t, int64(1), user.Version, "Rehashing is not a user change")
+	assert.Empty(t, repo.AuditLog().Entries())
+	ok, rehash, err := current.Verify(context.Background(), user.PasswordHash, "correct-horse-battery")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.False(t, rehash)
+
+	_, err = svc.Login(ctx, "jane@example.com", "correct-horse-battery")
+	assert.NoError(t, err, "The new hash verifies")
+}
+
+func TestLoginTakesAsLongForUnknownEmails(t *testing.T) {
+	// A cost high enough for hashing to dominate the response time
+	hasher, err := auth.NewPasswordHasher(&config.Config{PasswordHashAlgorithm: auth.Bcrypt, PasswordBcryptCost: 10, PasswordHashConcurrency: 1})
+	require.NoError(t, err)
+	hash, err := hasher.Hash(context.Background(), "correct-horse-battery")
+	require.NoError(t, err)
+	repo := testutils.NewFakeUserRepository(
+		models.User{Name: "Jane Doe", Email: "jane@example.com", PasswordHash: hash},
+		models.User{Name: "No Password", Email: "nopass@example.com"},
+	)
+	svc := services.NewAuthService(repo, testutils.NewFakeRefreshTokenRepository(), testutils.NewTestJWTManager(), hasher)
+
+	login := func(email string) time.Duration {
+		start := time.Now()
+		_, err := svc.Login(context.Background(), email, "wrong-horse-battery")
+		require.ErrorIs(t, err, services.ErrInvalidCredentials)
+		return time.Since(start)
+	}
+	wrongPassword := login("jane@example.com")
+	assert.Greater(t, login("nobody@example.com"), wrongPassword/2, "Unknown emails are hashed too")
+	assert.Greater(t, login("nopass@example.com"), wrongPassword/2, "So are users without a password")
+}
@@ -0,0 +1,71 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/jobs"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/jobs/runner"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurgeDeletedUsersJob(t *testing.T) {
+	repo := testutils.NewFakeUserRepository(
+		models.User{Name: "Expired", Email: "expired@example.com"},
+		models.User{Name: "Recent", Email: "recent@example.com"},
+	)
+	svc := services.NewUserService(repo, testutils.NewTestPasswords())
+	ctx := context.Background()
+	require.NoError(t, svc.DeleteUser(ctx, 1, 0))
+	require.NoError(t, svc.DeleteUser(ctx, 2, 0))
+	repo.SetDeletedAt(1, time.Now().Add(-48*time.Hour))
+
+	registry := jobs.NewRegistry()
+	tokens := testutils.NewTestJWTManager()
+	services.RegisterUserJobs(registry, svc,
+		services.NewEmailVerificationService(repo, tokens, services.EmailVerificationOptions{}),
+		services.NewPasswordService(repo, tokens, services.PasswordServiceOptions{}))
+	store := repo.JobStore()
+	job, err := jobs.Enqueue(ctx, store, services.PurgeDeletedUsersArgs{RetentionPeriod: 24 * time.Hour}, jobs.Options{})
+	require.NoError(t, err)
+
+	worker := runner.NewWorker(store, registry, runner.WorkerOptions{ID: "w1", Lease: time.Minute}, nil)
+	n, err := worker.RunOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	done, _ := store.Get(job.ID)
+	assert.Equal(t, jobs.StateSucceeded, done.State)
+
+	trash, _, _, err := svc.ListUsers(ctx, repositories.UserQuery{Deleted: repositories.DeletedOnly}, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	assert.Equal(t, "recent@example.com", trash[0].Email)
+}
+
+func TestRolledBackTransactionEnqueuesNoJob(t *testing.T) {
+	repo := testutils.NewFakeUserRepository()
+	ctx := context.Background()
+	args := services.PurgeDeletedUsersArgs{RetentionPeriod: time.Hour}
+
+	err := repo.Transaction(ctx, func(tx repositories.UserRepository) error {
+		if _, err := jobs.Enqueue(ctx, tx.Jobs(), args, jobs.Options{}); err != nil {
+			return err
+		}
+		return errors.New("abort")
+	})
+	require.Error(t, err)
+	assert.Empty(t, repo.JobStore().Jobs(), "Jobs enqueued in a rolled-back transaction are discarded")
+
+	require.NoError(t, repo.Transaction(ctx, func(tx repositories.UserRepository) error {
+		_, err := jobs.Enqueue(ctx, tx.Jobs(), args, jobs.Options{})
+		return err
+	}))
+	assert.Len(t, repo.JobStore().Jobs(), 1)
+}
@@ -0,0 +1,92 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/apperror"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUserQuery(t *testing.T) {
+	q, err := services.ParseUserQuery(map[string][]string{
+		"name":            {"Jane"},
+		"email[prefix]":   {"jane@"},
+		"name[in]":        {"Jane,John"},
+		"created_at[gte]": {"2024-01-01"},
+		"created_at[lte]": {"2024-06-30T12:00:00Z"},
+		"deleted":         {"include"},
+		"q":               {" smith "},
+		"sort":            {"-created_at,name"},
+		"page":            {"2"}, // not a filter
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []repositories.UserFilter{
+		{Field: repositories.UserFieldCreatedAt, Op: repositories.OpGte, Values: []any{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		{Field: repositories.UserFieldCreatedAt, Op: repositories.OpLte, Values: []any{time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)}},
+		{Field: repositories.UserFieldEmail, Op: repositories.OpPrefix, Values: []any{"jane@"}},
+		{Field: repositories.UserFieldName, Op: repositories.OpEq, Values: []any{"Jane"}},
+		{Field: repositories.UserFieldName, Op: repositories.OpIn, Values: []any{"Jane", "John"}},
+	}, q.Filters)
+	assert.Equal(t, []repositories.SortKey{
+		{Field: repositories.UserFieldCreatedAt, Desc: true},
+		{Field: repositories.UserFieldName},
+	}, q.Sort)
+	assert.Equal(t, repositories.DeletedInclude, q.Deleted)
+	assert.Equal(t, "smith", q.Search)
+}
+
+func TestParseUserQueryDateBounds(t *testing.T) {
+	day := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	nextDay := day.AddDate(0, 0, 1)
+	tests := []struct {
+		key, value string
+		want       repositories.UserFilter
+	}{
+		{"created_at[gte]", "2024-06-30", repositories.UserFilter{Op: repositories.OpGte, Values: []any{day}}},
+		{"created_at[lt]", "2024-06-30", repositories.UserFilter{Op: repositories.OpLt, Values: []any{day}}},
+		{"created_at[lte]", "2024-06-30", repositories.UserFilter{Op: repositories.OpLt, Values: []any{nextDay}}},
+		{"created_at[gt]", "2024-06-30", repositories.UserFilter{Op: repositories.OpGte, Values: []any{nextDay}}},
+		{"created_at[lte]", "2024-06-30T00:00:00Z", repositories.UserFilter{Op: repositories.OpLte, Values: []any{day}}},
+		{"created_at[gt]", "2024-06-30T00:00:00Z", repositories.UserFilter{Op: repositories.OpGt, Values: []any{day}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			q, err := services.ParseUserQuery(map[string][]string{tt.key: {tt.value}})
+			require.NoError(t, err)
+			tt.want.Field = repositories.UserFieldCreatedAt
+			assert.Equal(t, []repositories.UserFilter{tt.want}, q.Filters)
+		})
+	}
+}
+
+func TestParseUserQueryErrors(t *testing.T) {
+	_, err := services.ParseUserQuery(map[string][]string{
+		"password[eq]":    {"x"},
+		"created_at[eq]":  {"2024-01-01"},
+		"created_at[gte]": {"yesterday"},
+		"deleted":         {"maybe"},
+		"sort":            {"password,name,name"},
+	})
+	require.Error(t, err)
+
+	appErr, ok := apperror.As(err)
+	require.True(t, ok)
+	assert.ErrorIs(t, err, services.ErrValidationFailed)
+
+	codes := map[string][]string{}
+	for _, f := range appErr.Fields {
+		codes[f.Field] = append(codes[f.Field], f.Code)
+	}
+	assert.Equal(t, map[string][]string{
+		"created_at[eq]":  {"unsupported_operator"},
+		"created_at[gte]": {"format"},
+		"deleted":         {"oneof"},
+		"password[eq]":    {"unknown_field"},
+		"sort":            {"unknown_field", "duplicate"},
+	}, codes)
+}
@@ -0,0 +1,41 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserRetentionPurgesExpiredUsers(t *testing.T) {
+	repo := testutils.NewFakeUserRepository(
+		models.User{Name: "Expired", Email: "expired@example.com"},
+		models.User{Name: "Recent", Email: "recent@example.com"},
+		models.User{Name: "Live", Email: "live@example.com"},
+	)
+	svc := services.NewUserService(repo, testutils.NewTestPasswords())
+	ctx := context.Background()
+
+	require.NoError(t, svc.DeleteUser(ctx, 1, 0))
+	require.NoError(t, svc.DeleteUser(ctx, 2, 0))
+	repo.SetDeletedAt(1, time.Now().Add(-48*time.Hour))
+
+	purged, err := services.NewUserRetention(svc, 24*time.Hour).RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	trash, _, _, err := svc.ListUsers(ctx, repositories.UserQuery{Deleted: repositories.DeletedOnly}, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	assert.Equal(t, "recent@example.com", trash[0].Email, "Users deleted within the retention period are kept")
+
+	assert.Equal(t, services.ErrDeletedUserNotFound, svc.PurgeUser(ctx, 1))
+	_, err = svc.GetUserByID(ctx, 3)
+	assert.NoError(t, err, "Live users are never purged")
+}
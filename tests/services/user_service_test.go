@@ -0,0 +1,80 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/persistence"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type ctxKey struct{}
+
+func TestUserServiceWithoutHTTP(t *testing.T) {
+	svc := services.NewUserService(testutils.NewFakeUserRepository(), testutils.NewTestPasswords())
+	ctx := context.Background()
+
+	created, err := svc.CreateUser(ctx, &models.User{Name: "Jane", Email: "jane@example.com"})
+	require.NoError(t, err)
+
+	_, err = svc.CreateUser(ctx, &models.User{Name: "Other", Email: "jane@example.com"})
+	assert.Equal(t, services.ErrUserEmailExists, err)
+
+	updated, err := svc.UpdateUser(ctx, created.ID, &models.User{Name: "Janet", Email: "jane@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "Janet", updated.Name)
+
+	require.NoError(t, svc.DeleteUser(ctx, created.ID, 0))
+	_, err = svc.GetUserByID(ctx, created.ID)
+	assert.Equal(t, services.ErrUserNotFound, err)
+}
+
+func TestGormRepositoryUsesCallerContext(t *testing.T) {
+	// The dry run builds statements without a database; the callback sees the statement context
+	db := testutils.NewDryRunDB()
+	var seen context.Context
+	require.NoError(t, db.Callback().Query().Before("gorm:query").Register("test:capture_ctx", func(tx *gorm.DB) {
+		seen = tx.Statement.Context
+	}))
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "marker")
+	_, err := persistence.NewGormUserRepository(db).GetByID(ctx, 1)
+	require.NoError(t, err)
+
+	require.NotNil(t, seen)
+	assert.Equal(t, "marker", seen.Value(ctxKey{}), "Queries should run with the caller's context so cancellation propagates")
+}
+
+// registeringRepository registers a user with the email just looked up, as
+// a concurrent request could between the lookup and the write that
+// depended on it.
+type registeringRepository struct {
+	*testutils.FakeUserRepository
+}
+
+func (r registeringRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	found, err := r.FakeUserRepository.GetByEmail(ctx, email)
+	if err == nil && found == nil {
+		err = r.FakeUserRepository.Create(ctx, &models.User{Name: "Newcomer", Email: email})
+	}
+	return found, err
+}
+
+func TestRestoreUserLosesRaceForEmail(t *testing.T) {
+	repo := testutils.NewFakeUserRepository(models.User{Name: "Jane", Email: "jane@example.com"})
+	ctx := context.Background()
+	require.NoError(t, services.NewUserService(repo, testutils.NewTestPasswords()).DeleteUser(ctx, 1, 0))
+
+	svc := services.NewUserService(registeringRepository{repo}, testutils.NewTestPasswords())
+	_, err := svc.RestoreUser(ctx, 1)
+	assert.ErrorIs(t, err, services.ErrEmailInUse, "The unique index catches what the check missed")
+
+	deleted, err := repo.GetDeletedByID(ctx, 1)
+	require.NoError(t, err)
+	assert.NotNil(t, deleted, "The user stays deleted")
+}
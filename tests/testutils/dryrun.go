@@ -0,0 +1,50 @@
+package testutils
+
+import (
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// NewDryRunDB returns a Postgres database handle that builds statements and
+// runs callbacks without ever connecting, for tests of the SQL repositories
+// generate. Writes skip GORM's default transaction, which would connect.
+func NewDryRunDB() *gorm.DB {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		panic("Failed to open dry-run database: " + err.Error())
+	}
+	return db
+}
+
+// CapturedSQL is the last statement a database built, with its bind values.
+type CapturedSQL struct {
+	SQL  string
+	Vars []any
+}
+
+// CaptureSQL records every query, create, update, delete and raw statement
+// db builds; the returned CapturedSQL always holds the latest one.
+func CaptureSQL(db *gorm.DB) *CapturedSQL {
+	captured := &CapturedSQL{}
+	capture := func(tx *gorm.DB) {
+		captured.SQL = tx.Statement.SQL.String()
+		captured.Vars = tx.Statement.Vars
+	}
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Query().After("gorm:query").Register("testutils:capture_sql", capture),
+		callbacks.Create().After("gorm:create").Register("testutils:capture_sql", capture),
+		callbacks.Update().After("gorm:update").Register("testutils:capture_sql", capture),
+		callbacks.Delete().After("gorm:delete").Register("testutils:capture_sql", capture),
+		callbacks.Raw().After("gorm:raw").Register("testutils:capture_sql", capture),
+	} {
+		if err != nil {
+			panic("Failed to register SQL capture: " + err.Error())
+		}
+	}
+	return captured
+}
@@ -0,0 +1,111 @@
+package testutils
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+)
+
+// FakeAuditRepository is an in-memory AuditRepository. It seals entries
+// like the GORM repository, so hash chains can be verified in tests.
+type FakeAuditRepository struct {
+	mu      sync.Mutex
+	entries []models.AuditEntry
+}
+
+func NewFakeAuditRepository() *FakeAuditRepository {
+	return &FakeAuditRepository{}
+}
+
+var _ repositories.AuditRepository = (*FakeAuditRepository)(nil)
+
+func (r *FakeAuditRepository) Append(ctx context.Context, entries ...*models.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prev := ""
+	if n := len(r.entries); n > 0 {
+		prev = r.entries[n-1].Hash
+	}
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	for _, e := range entries {
+		e.ID = uint(len(r.entries) + 1)
+		e.CreatedAt = now
+		if err := e.Seal(prev); err != nil {
+			return err
+		}
+		prev = e.Hash
+		r.entries = append(r.entries, *e)
+	}
+	return nil
+}
+
+func (r *FakeAuditRepository) List(ctx context.Context, q repositories.AuditQuery, before uint, limit int) ([]models.AuditEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entries := []models.AuditEntry{}
+	for _, e := range slices.Backward(r.entries) {
+		if len(entries) == limit {
+			break
+		}
+		if (before == 0 || e.ID < before) && auditMatches(e, q) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func auditMatches(e models.AuditEntry, q repositories.AuditQuery) bool {
+	return (q.ActorID == nil || (e.ActorID != nil && *e.ActorID == *q.ActorID)) &&
+		(q.Action == "" || e.Action == q.Action) &&
+		(q.TargetType == "" || e.TargetType == q.TargetType) &&
+		(q.TargetID == nil || (e.TargetID != nil && *e.TargetID == *q.TargetID)) &&
+		(q.Since.IsZero() || !e.CreatedAt.Before(q.Since)) &&
+		(q.Until.IsZero() || !e.CreatedAt.After(q.Until))
+}
+
+func (r *FakeAuditRepository) Chain(ctx context.Context, after uint, limit int) ([]models.AuditEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entries := []models.AuditEntry{}
+	for _, e := range r.entries {
+		if e.ID > after && len(entries) < limit {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// Entries returns every stored entry, oldest first.
+func (r *FakeAuditRepository) Entries() []models.AuditEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.entries)
+}
+
+// Tamper replaces the stored entry with the given ID, bypassing the chain,
+// to simulate an edit made directly in the database.
+func (r *FakeAuditRepository) Tamper(id uint, fn func(e *models.AuditEntry)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.entries {
+		if r.entries[i].ID == id {
+			fn(&r.entries[i])
+		}
+	}
+}
+
+func (r *FakeAuditRepository) snapshot() []models.AuditEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.entries)
+}
+
+func (r *FakeAuditRepository) restore(entries []models.AuditEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = entries
+}
@@ -0,0 +1,66 @@
+package testutils
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+)
+
+// FakeEmailVerificationRepository is an in-memory EmailVerificationRepository.
+type FakeEmailVerificationRepository struct {
+	mu     sync.Mutex
+	tokens []models.EmailVerificationToken
+	nextID uint
+}
+
+func NewFakeEmailVerificationRepository() *FakeEmailVerificationRepository {
+	return &FakeEmailVerificationRepository{}
+}
+
+var _ repositories.EmailVerificationRepository = (*FakeEmailVerificationRepository)(nil)
+
+func (r *FakeEmailVerificationRepository) Create(ctx context.Context, token *models.EmailVerificationToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	token.ID = r.nextID
+	token.CreatedAt = time.Now()
+	r.tokens = append(r.tokens, *token)
+	return nil
+}
+
+func (r *FakeEmailVerificationRepository) Use(ctx context.Context, tokenID string) (*models.EmailVerificationToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	i := slices.IndexFunc(r.tokens, func(t models.EmailVerificationToken) bool {
+		return t.TokenID == tokenID && t.UsedAt == nil && t.ExpiresAt.After(now)
+	})
+	if i < 0 {
+		return nil, nil
+	}
+	r.tokens[i].UsedAt = &now
+	token := r.tokens[i]
+	return &token, nil
+}
+
+// Tokens returns every issued token, oldest first.
+func (r *FakeEmailVerificationRepository) Tokens() []models.EmailVerificationToken {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.tokens)
+}
+
+func (r *FakeEmailVerificationRepository) snapshot() []models.EmailVerificationToken {
+	return r.Tokens()
+}
+
+func (r *FakeEmailVerificationRepository) restore(tokens []models.EmailVerificationToken) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens = tokens
+}
@@ -0,0 +1,62 @@
+package testutils
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/idempotency"
+)
+
+// FakeIdempotencyStore is an in-memory idempotency.Store. Keys never expire
+// and locks are never reclaimed.
+type FakeIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]idempotency.Record
+	locks   map[string]time.Time
+}
+
+func NewFakeIdempotencyStore() *FakeIdempotencyStore {
+	return &FakeIdempotencyStore{records: map[string]idempotency.Record{}, locks: map[string]time.Time{}}
+}
+
+var _ idempotency.Store = (*FakeIdempotencyStore)(nil)
+
+func (s *FakeIdempotencyStore) Lock(ctx context.Context, key, fingerprint string) (*idempotency.Lease, *idempotency.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec, ok := s.records[key]; ok {
+		return nil, &rec, nil
+	}
+	lease := &idempotency.Lease{Key: key, Fingerprint: fingerprint, LockedAt: time.Now()}
+	s.records[key] = idempotency.Record{Fingerprint: fingerprint}
+	s.locks[key] = lease.LockedAt
+	return lease, nil, nil
+}
+
+func (s *FakeIdempotencyStore) Complete(ctx context.Context, lease *idempotency.Lease, rec idempotency.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.holds(lease) {
+		return idempotency.ErrLockLost
+	}
+	delete(s.locks, lease.Key)
+	s.records[lease.Key] = rec
+	return nil
+}
+
+func (s *FakeIdempotencyStore) Release(ctx context.Context, lease *idempotency.Lease) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.holds(lease) {
+		return idempotency.ErrLockLost
+	}
+	delete(s.locks, lease.Key)
+	delete(s.records, lease.Key)
+	return nil
+}
+
+func (s *FakeIdempotencyStore) holds(lease *idempotency.Lease) bool {
+	lockedAt, ok := s.locks[lease.Key]
+	return ok && lockedAt.Equal(lease.LockedAt) && s.records[lease.Key].Fingerprint == lease.Fingerprint
+}
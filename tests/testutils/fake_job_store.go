@@ -0,0 +1,170 @@
+package testutils
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/jobs"
+)
+
+// FakeJobStore is an in-memory jobs.Store. Now defaults to time.Now and
+// decides which jobs are due and when leases expire.
+type FakeJobStore struct {
+	Now func() time.Time
+
+	mu     sync.Mutex
+	jobs   []jobs.Job
+	nextID uint
+}
+
+func NewFakeJobStore() *FakeJobStore {
+	return &FakeJobStore{Now: time.Now}
+}
+
+var _ jobs.Store = (*FakeJobStore)(nil)
+
+func (s *FakeJobStore) Insert(ctx context.Context, job *jobs.Job) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if job.UniqueKey != nil && slices.ContainsFunc(s.jobs, func(j jobs.Job) bool {
+		return j.UniqueKey != nil && *j.UniqueKey == *job.UniqueKey &&
+			(j.State == jobs.StateAvailable || j.State == jobs.StateRunning)
+	}) {
+		return false, nil
+	}
+	s.nextID++
+	job.ID = s.nextID
+	job.CreatedAt, job.UpdatedAt = s.Now(), s.Now()
+	s.jobs = append(s.jobs, *job)
+	return true, nil
+}
+
+func (s *FakeJobStore) Claim(ctx context.Context, worker string, queues []string, limit int, lease time.Duration) ([]jobs.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.Now()
+	var due []jobs.Job
+	for i := range s.jobs {
+		j := &s.jobs[i]
+		if len(due) == limit || !slices.Contains(queues, j.Queue) {
+			continue
+		}
+		if (j.State == jobs.StateAvailable && !j.RunAt.After(now)) ||
+			(j.State == jobs.StateRunning && j.LockedUntil.Before(now)) {
+			until := now.Add(lease)
+			j.State, j.LockedBy, j.LockedUntil = jobs.StateRunning, worker, &until
+			j.Attempts++
+			due = append(due, *j)
+		}
+	}
+	return due, nil
+}
+
+func (s *FakeJobStore) Extend(ctx context.Context, job *jobs.Job, lease time.Duration) error {
+	return s.finish(job, func(j *jobs.Job) {
+		until := s.Now().Add(lease)
+		j.LockedUntil = &until
+	})
+}
+
+func (s *FakeJobStore) Complete(ctx context.Context, job *jobs.Job) error {
+	return s.finish(job, func(j *jobs.Job) {
+		now := s.Now()
+		j.State, j.FinishedAt, j.LastError = jobs.StateSucceeded, &now, ""
+		j.LockedBy, j.LockedUntil = "", nil
+	})
+}
+
+func (s *FakeJobStore) Retry(ctx context.Context, job *jobs.Job, runAt time.Time, reason string) error {
+	return s.finish(job, func(j *jobs.Job) {
+		j.State, j.RunAt, j.LastError = jobs.StateAvailable, runAt, reason
+		j.LockedBy, j.LockedUntil = "", nil
+	})
+}
+
+func (s *FakeJobStore) Fail(ctx context.Context, job *jobs.Job, reason string) error {
+	return s.finish(job, func(j *jobs.Job) {
+		now := s.Now()
+		j.State, j.FinishedAt, j.LastError = jobs.StateFailed, &now, reason
+		j.LockedBy, j.LockedUntil = "", nil
+	})
+}
+
+// finish applies fn to the stored job if the claim that returned job still
+// holds its lease.
+func (s *FakeJobStore) finish(job *jobs.Job, fn func(j *jobs.Job)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.IndexFunc(s.jobs, func(j jobs.Job) bool {
+		return j.ID == job.ID && j.State == jobs.StateRunning && j.LockedBy == job.LockedBy && j.Attempts == job.Attempts
+	})
+	if i < 0 {
+		return jobs.ErrLeaseLost
+	}
+	fn(&s.jobs[i])
+	s.jobs[i].UpdatedAt = s.Now()
+	return nil
+}
+
+func (s *FakeJobStore) Depth(ctx context.Context) ([]jobs.QueueDepth, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.Now()
+	var depths []jobs.QueueDepth
+	for _, j := range s.jobs {
+		if j.State == jobs.StateSucceeded {
+			continue
+		}
+		i := slices.IndexFunc(depths, func(d jobs.QueueDepth) bool { return d.Queue == j.Queue && d.State == j.State })
+		if i < 0 {
+			depths = append(depths, jobs.QueueDepth{Queue: j.Queue, State: j.State})
+			i = len(depths) - 1
+		}
+		depths[i].Count++
+		if !j.RunAt.After(now) && (depths[i].OldestDue == nil || j.RunAt.Before(*depths[i].OldestDue)) {
+			runAt := j.RunAt
+			depths[i].OldestDue = &runAt
+		}
+	}
+	return depths, nil
+}
+
+func (s *FakeJobStore) DeleteFinishedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	before := len(s.jobs)
+	s.jobs = slices.DeleteFunc(s.jobs, func(j jobs.Job) bool {
+		return j.FinishedAt != nil && j.FinishedAt.Before(cutoff)
+	})
+	return int64(before - len(s.jobs)), nil
+}
+
+// Jobs returns every stored job in insertion order.
+func (s *FakeJobStore) Jobs() []jobs.Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.jobs)
+}
+
+// Get returns the stored job with id.
+func (s *FakeJobStore) Get(id uint) (jobs.Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.IndexFunc(s.jobs, func(j jobs.Job) bool { return j.ID == id })
+	if i < 0 {
+		return jobs.Job{}, false
+	}
+	return s.jobs[i], true
+}
+
+func (s *FakeJobStore) snapshot() []jobs.Job {
+	return s.Jobs()
+}
+
+func (s *FakeJobStore) restore(stored []jobs.Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = stored
+}
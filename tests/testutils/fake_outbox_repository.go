@@ -0,0 +1,103 @@
+package testutils
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/pkg/requestid"
+)
+
+// FakeOutboxRepository is an in-memory OutboxRepository. ClaimDue leases
+// messages as the Postgres repository does. Now defaults to time.Now and
+// decides which messages are due.
+type FakeOutboxRepository struct {
+	Now func() time.Time
+
+	mu       sync.Mutex
+	messages []models.OutboxMessage
+	nextID   uint
+}
+
+func NewFakeOutboxRepository() *FakeOutboxRepository {
+	return &FakeOutboxRepository{Now: time.Now}
+}
+
+var _ repositories.OutboxRepository = (*FakeOutboxRepository)(nil)
+
+func (r *FakeOutboxRepository) Add(ctx context.Context, msgs ...*models.OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.Now()
+	for _, m := range msgs {
+		r.nextID++
+		m.ID = r.nextID
+		m.EventID = requestid.New()
+		m.CreatedAt, m.AvailableAt = now, now
+		r.messages = append(r.messages, *m)
+	}
+	return nil
+}
+
+func (r *FakeOutboxRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration, fn func(msgs []models.OutboxMessage) error) (int, error) {
+	r.mu.Lock()
+	now := r.Now()
+	var due []models.OutboxMessage
+	for i, m := range r.messages {
+		if m.PublishedAt == nil && !m.AvailableAt.After(now) && len(due) < limit {
+			r.messages[i].AvailableAt = now.Add(lease)
+			due = append(due, r.messages[i])
+		}
+	}
+	r.mu.Unlock()
+	if len(due) == 0 {
+		return 0, nil
+	}
+	leases := make([]time.Time, len(due))
+	for i := range due {
+		leases[i] = due[i].AvailableAt
+	}
+
+	if err := fn(due); err != nil {
+		return 0, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for j, m := range due {
+		i := slices.IndexFunc(r.messages, func(stored models.OutboxMessage) bool { return stored.ID == m.ID })
+		if i >= 0 && r.messages[i].PublishedAt == nil && r.messages[i].AvailableAt.Equal(leases[j]) {
+			r.messages[i] = m
+		}
+	}
+	return len(due), nil
+}
+
+func (r *FakeOutboxRepository) DeletePublishedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	before := len(r.messages)
+	r.messages = slices.DeleteFunc(r.messages, func(m models.OutboxMessage) bool {
+		return m.PublishedAt != nil && m.PublishedAt.Before(cutoff)
+	})
+	return int64(before - len(r.messages)), nil
+}
+
+// Messages returns every stored message, oldest first.
+func (r *FakeOutboxRepository) Messages() []models.OutboxMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.messages)
+}
+
+func (r *FakeOutboxRepository) snapshot() []models.OutboxMessage {
+	return r.Messages()
+}
+
+func (r *FakeOutboxRepository) restore(messages []models.OutboxMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = messages
+}
@@ -0,0 +1,78 @@
+package testutils
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+)
+
+// FakePasswordResetRepository is an in-memory PasswordResetRepository.
+type FakePasswordResetRepository struct {
+	mu     sync.Mutex
+	tokens []models.PasswordResetToken
+	nextID uint
+}
+
+func NewFakePasswordResetRepository() *FakePasswordResetRepository {
+	return &FakePasswordResetRepository{}
+}
+
+var _ repositories.PasswordResetRepository = (*FakePasswordResetRepository)(nil)
+
+func (r *FakePasswordResetRepository) Create(ctx context.Context, token *models.PasswordResetToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	token.ID = r.nextID
+	token.CreatedAt = time.Now()
+	r.tokens = append(r.tokens, *token)
+	return nil
+}
+
+func (r *FakePasswordResetRepository) Use(ctx context.Context, tokenID string) (*models.PasswordResetToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	i := slices.IndexFunc(r.tokens, func(t models.PasswordResetToken) bool {
+		return t.TokenID == tokenID && t.UsedAt == nil && t.ExpiresAt.After(now)
+	})
+	if i < 0 {
+		return nil, nil
+	}
+	r.tokens[i].UsedAt = &now
+	token := r.tokens[i]
+	return &token, nil
+}
+
+func (r *FakePasswordResetRepository) UseAllForUser(ctx context.Context, userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for i := range r.tokens {
+		if r.tokens[i].UserID == userID && r.tokens[i].UsedAt == nil {
+			r.tokens[i].UsedAt = &now
+		}
+	}
+	return nil
+}
+
+// Tokens returns every issued token, oldest first.
+func (r *FakePasswordResetRepository) Tokens() []models.PasswordResetToken {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.tokens)
+}
+
+func (r *FakePasswordResetRepository) snapshot() []models.PasswordResetToken {
+	return r.Tokens()
+}
+
+func (r *FakePasswordResetRepository) restore(tokens []models.PasswordResetToken) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens = tokens
+}
@@ -0,0 +1,86 @@
+package testutils
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+)
+
+// FakeRefreshTokenRepository is an in-memory RefreshTokenRepository.
+type FakeRefreshTokenRepository struct {
+	mu     sync.Mutex
+	tokens []models.RefreshToken
+	nextID uint
+}
+
+func NewFakeRefreshTokenRepository() *FakeRefreshTokenRepository {
+	return &FakeRefreshTokenRepository{}
+}
+
+var _ repositories.RefreshTokenRepository = (*FakeRefreshTokenRepository)(nil)
+
+func (r *FakeRefreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	token.ID = r.nextID
+	token.CreatedAt = time.Now()
+	r.tokens = append(r.tokens, *token)
+	return nil
+}
+
+func (r *FakeRefreshTokenRepository) GetByTokenID(ctx context.Context, tokenID string) (*models.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := slices.IndexFunc(r.tokens, func(t models.RefreshToken) bool { return t.TokenID == tokenID })
+	if i < 0 {
+		return nil, nil
+	}
+	token := r.tokens[i]
+	return &token, nil
+}
+
+func (r *FakeRefreshTokenRepository) Revoke(ctx context.Context, tokenID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := slices.IndexFunc(r.tokens, func(t models.RefreshToken) bool { return t.TokenID == tokenID && t.RevokedAt == nil })
+	if i < 0 {
+		return false, nil
+	}
+	now := time.Now()
+	r.tokens[i].RevokedAt = &now
+	return true, nil
+}
+
+func (r *FakeRefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for i := range r.tokens {
+		if r.tokens[i].UserID == userID && r.tokens[i].RevokedAt == nil {
+			r.tokens[i].RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+// Tokens returns every recorded token, oldest first.
+func (r *FakeRefreshTokenRepository) Tokens() []models.RefreshToken {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.tokens)
+}
+
+func (r *FakeRefreshTokenRepository) snapshot() []models.RefreshToken {
+	return r.Tokens()
+}
+
+func (r *FakeRefreshTokenRepository) restore(tokens []models.RefreshToken) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens = tokens
+}
@@ -0,0 +1,341 @@
+package testutils
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/jobs"
+	"gorm.io/gorm"
+)
+
+// FakeUserRepository is an in-memory UserRepository for tests that exercise
+// services and handlers without a database. Listing honours only
+// UserQuery.Deleted and always returns users in ID order. Deletes are soft,
+// as with the GORM repository. Mutations made through UserService record
+// their audit entries, events, jobs, verification and password reset
+// tokens in the repository's FakeAuditRepository, FakeOutboxRepository,
+// FakeJobStore, FakeEmailVerificationRepository,
+// FakePasswordResetRepository and FakeRefreshTokenRepository.
+type FakeUserRepository struct {
+	mu     sync.Mutex
+	users  map[uint]models.User
+	nextID uint
+	audit  *FakeAuditRepository
+	outbox *FakeOutboxRepository
+	jobs   *FakeJobStore
+	emails *FakeEmailVerificationRepository
+	resets *FakePasswordResetRepository
+	tokens *FakeRefreshTokenRepository
+}
+
+// NewFakeUserRepository seeds the given users directly, without audit
+// entries or events.
+func NewFakeUserRepository(users ...models.User) *FakeUserRepository {
+	repo := &FakeUserRepository{users: map[uint]models.User{}, nextID: 1,
+		audit: NewFakeAuditRepository(), outbox: NewFakeOutboxRepository(),
+		jobs: NewFakeJobStore(), emails: NewFakeEmailVerificationRepository(),
+		resets: NewFakePasswordResetRepository(), tokens: NewFakeRefreshTokenRepository()}
+	for _, u := range users {
+		_ = repo.Create(context.Background(), &u)
+	}
+	return repo
+}
+
+var _ repositories.UserRepository = (*FakeUserRepository)(nil)
+
+// visible reports whether u is in scope for a listing with the given
+// deleted scope.
+func visible(u models.User, scope repositories.DeletedScope) bool {
+	switch scope {
+	case repositories.DeletedInclude:
+		return true
+	case repositories.DeletedOnly:
+		return u.DeletedAt.Valid
+	default:
+		return !u.DeletedAt.Valid
+	}
+}
+
+func (r *FakeUserRepository) List(ctx context.Context, q repositories.UserQuery, page, limit int) ([]models.User, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var all []models.User
+	for id := uint(1); id < r.nextID; id++ {
+		if u, ok := r.users[id]; ok && visible(u, q.Deleted) {
+			all = append(all, u)
+		}
+	}
+	start := (page - 1) * limit
+	if start < 0 || start >= len(all) {
+		return []models.User{}, int64(len(all)), nil
+	}
+	end := start + limit
+	if end > len(all) {
+		end = len(all)
+	}
+	return all[start:end], int64(len(all)), nil
+}
+
+func (r *FakeUserRepository) ListAfter(ctx context.Context, q repositories.UserQuery, after []any, limit int) ([]models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var afterID uint
+	if len(after) > 0 {
+		afterID = after[len(after)-1].(uint)
+	}
+	users := []models.User{}
+	for id := afterID + 1; id < r.nextID && len(users) < limit; id++ {
+		if u, ok := r.users[id]; ok && visible(u, q.Deleted) {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+func (r *FakeUserRepository) Count(ctx context.Context, q repositories.UserQuery) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n int64
+	for _, u := range r.users {
+		if visible(u, q.Deleted) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *FakeUserRepository) EstimateCount(ctx context.Context, q repositories.UserQuery) (int64, error) {
+	return r.Count(ctx, q)
+}
+
+func (r *FakeUserRepository) GetByID(ctx context.Context, id uint) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if u, ok := r.users[id]; ok && !u.DeletedAt.Valid {
+		return &u, nil
+	}
+	return nil, nil
+}
+
+func (r *FakeUserRepository) GetDeletedByID(ctx context.Context, id uint) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if u, ok := r.users[id]; ok && u.DeletedAt.Valid {
+		return &u, nil
+	}
+	return nil, nil
+}
+
+func (r *FakeUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.Email == email && !u.DeletedAt.Valid {
+			return &u, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *FakeUserRepository) Create(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user.ID = r.nextID
+	r.nextID++
+	if user.Version == 0 {
+		user.Version = 1
+	}
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *FakeUserRepository) Update(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stored, ok := r.users[user.ID]; !ok || stored.DeletedAt.Valid || stored.Version != user.Version {
+		return repositories.ErrVersionConflict
+	}
+	user.Version++
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *FakeUserRepository) ReplacePasswordHash(ctx context.Context, id uint, old, hash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stored, ok := r.users[id]; ok && !stored.DeletedAt.Valid && stored.PasswordHash == old {
+		stored.PasswordHash = hash
+		r.users[id] = stored
+	}
+	return nil
+}
+
+func (r *FakeUserRepository) Delete(ctx context.Context, id uint, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.users[id]
+	if !ok || stored.DeletedAt.Valid || stored.Version != version {
+		return repositories.ErrVersionConflict
+	}
+	stored.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	r.users[id] = stored
+	return nil
+}
+
+func (r *FakeUserRepository) Restore(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.users[user.ID]
+	if !ok || !stored.DeletedAt.Valid || stored.Version != user.Version {
+		return repositories.ErrVersionConflict
+	}
+	for _, u := range r.users {
+		if u.Email == stored.Email && !u.DeletedAt.Valid {
+			return repositories.ErrEmailTaken
+		}
+	}
+	stored.DeletedAt = gorm.DeletedAt{}
+	stored.Version++
+	r.users[user.ID] = stored
+	*user = stored
+	return nil
+}
+
+func (r *FakeUserRepository) Purge(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if u, ok := r.users[id]; ok && u.DeletedAt.Valid {
+		delete(r.users, id)
+	}
+	return nil
+}
+
+func (r *FakeUserRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time, limit int) ([]models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var purged []models.User
+	for id := uint(1); id < r.nextID && len(purged) < limit; id++ {
+		if u, ok := r.users[id]; ok && u.DeletedAt.Valid && u.DeletedAt.Time.Before(cutoff) {
+			delete(r.users, id)
+			purged = append(purged, u)
+		}
+	}
+	return purged, nil
+}
+
+// SetDeletedAt backdates a soft-deleted user, for retention tests.
+func (r *FakeUserRepository) SetDeletedAt(id uint, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if u, ok := r.users[id]; ok {
+		u.DeletedAt = gorm.DeletedAt{Time: at, Valid: true}
+		r.users[id] = u
+	}
+}
+
+func (r *FakeUserRepository) CreateBatch(ctx context.Context, users []models.User) error {
+	for i := range users {
+		if err := r.Create(ctx, &users[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *FakeUserRepository) ExistingEmails(ctx context.Context, emails []string) ([]string, error) {
+	var existing []string
+	for _, email := range emails {
+		if u, _ := r.GetByEmail(ctx, email); u != nil {
+			existing = append(existing, email)
+		}
+	}
+	return existing, nil
+}
+
+// Transaction runs fn against the same repository and restores a snapshot
+// of its state if fn fails. Transactions do not isolate concurrent callers.
+func (r *FakeUserRepository) Transaction(ctx context.Context, fn func(repo repositories.UserRepository) error) error {
+	r.mu.Lock()
+	users, nextID := maps.Clone(r.users), r.nextID
+	r.mu.Unlock()
+	entries, messages := r.audit.snapshot(), r.outbox.snapshot()
+	queued, tokens, resets := r.jobs.snapshot(), r.emails.snapshot(), r.resets.snapshot()
+	sessions := r.tokens.snapshot()
+
+	if err := fn(r); err != nil {
+		r.mu.Lock()
+		r.users, r.nextID = users, nextID
+		r.mu.Unlock()
+		r.audit.restore(entries)
+		r.outbox.restore(messages)
+		r.jobs.restore(queued)
+		r.emails.restore(tokens)
+		r.resets.restore(resets)
+		r.tokens.restore(sessions)
+		return err
+	}
+	return nil
+}
+
+// Audit returns the repository's audit log; see FakeAuditRepository.
+func (r *FakeUserRepository) Audit() repositories.AuditRepository {
+	return r.audit
+}
+
+// AuditLog returns the audit log as its concrete type, for assertions.
+func (r *FakeUserRepository) AuditLog() *FakeAuditRepository {
+	return r.audit
+}
+
+func (r *FakeUserRepository) Outbox() repositories.OutboxRepository {
+	return r.outbox
+}
+
+// OutboxLog returns the outbox as its concrete type, for assertions.
+func (r *FakeUserRepository) OutboxLog() *FakeOutboxRepository {
+	return r.outbox
+}
+
+func (r *FakeUserRepository) Jobs() jobs.Inserter {
+	return r.jobs
+}
+
+func (r *FakeUserRepository) EmailVerifications() repositories.EmailVerificationRepository {
+	return r.emails
+}
+
+// VerificationTokens returns the issued verification tokens as their
+// concrete type, for assertions.
+func (r *FakeUserRepository) VerificationTokens() *FakeEmailVerificationRepository {
+	return r.emails
+}
+
+func (r *FakeUserRepository) PasswordResets() repositories.PasswordResetRepository {
+	return r.resets
+}
+
+// ResetTokens returns the issued password reset tokens as their concrete
+// type, for assertions.
+func (r *FakeUserRepository) ResetTokens() *FakePasswordResetRepository {
+	return r.resets
+}
+
+func (r *FakeUserRepository) RefreshTokens() repositories.RefreshTokenRepository {
+	return r.tokens
+}
+
+// SessionTokens returns the refresh tokens as their concrete type, for
+// seeding sessions and for assertions.
+func (r *FakeUserRepository) SessionTokens() *FakeRefreshTokenRepository {
+	return r.tokens
+}
+
+// JobStore returns the job queue as its concrete type, for assertions and
+// for running a worker against it.
+func (r *FakeUserRepository) JobStore() *FakeJobStore {
+	return r.jobs
+}
@@ -0,0 +1,227 @@
+package testutils
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/repositories"
+)
+
+// FakeWebhookRepository is an in-memory WebhookRepository. Like
+// FakeOutboxRepository, ClaimDueDeliveries leases deliveries as the
+// Postgres repository does, and Now decides which deliveries are due.
+type FakeWebhookRepository struct {
+	Now func() time.Time
+
+	mu             sync.Mutex
+	subscriptions  []models.WebhookSubscription
+	deliveries     []models.WebhookDelivery
+	nextSubID      uint
+	nextDeliveryID uint
+}
+
+func NewFakeWebhookRepository() *FakeWebhookRepository {
+	return &FakeWebhookRepository{Now: time.Now}
+}
+
+var _ repositories.WebhookRepository = (*FakeWebhookRepository)(nil)
+
+func (r *FakeWebhookRepository) CreateSubscription(ctx context.Context, sub *models.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextSubID++
+	sub.ID = r.nextSubID
+	sub.CreatedAt, sub.UpdatedAt = r.Now(), r.Now()
+	r.subscriptions = append(r.subscriptions, cloneSubscription(*sub))
+	return nil
+}
+
+func (r *FakeWebhookRepository) GetSubscription(ctx context.Context, id uint) (*models.WebhookSubscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if i := r.subscriptionIndex(id); i >= 0 {
+		sub := cloneSubscription(r.subscriptions[i])
+		return &sub, nil
+	}
+	return nil, nil
+}
+
+func (r *FakeWebhookRepository) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	subs := make([]models.WebhookSubscription, len(r.subscriptions))
+	for i, sub := range r.subscriptions {
+		subs[i] = cloneSubscription(sub)
+	}
+	return subs, nil
+}
+
+func (r *FakeWebhookRepository) ListSubscribers(ctx context.Context, eventType string) ([]models.WebhookSubscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var subs []models.WebhookSubscription
+	for _, sub := range r.subscriptions {
+		if sub.Active && sub.Wants(eventType) {
+			subs = append(subs, cloneSubscription(sub))
+		}
+	}
+	return subs, nil
+}
+
+func (r *FakeWebhookRepository) UpdateSubscription(ctx context.Context, sub *models.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if i := r.subscriptionIndex(sub.ID); i >= 0 {
+		sub.UpdatedAt = r.Now()
+		r.subscriptions[i] = cloneSubscription(*sub)
+	}
+	return nil
+}
+
+func (r *FakeWebhookRepository) DeleteSubscription(ctx context.Context, id uint) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.subscriptionIndex(id)
+	if i < 0 {
+		return false, nil
+	}
+	r.subscriptions = slices.Delete(r.subscriptions, i, i+1)
+	r.deliveries = slices.DeleteFunc(r.deliveries, func(d models.WebhookDelivery) bool { return d.SubscriptionID == id })
+	return true, nil
+}
+
+func (r *FakeWebhookRepository) AddDeliveries(ctx context.Context, deliveries ...*models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, d := range deliveries {
+		if slices.ContainsFunc(r.deliveries, func(stored models.WebhookDelivery) bool {
+			return stored.SubscriptionID == d.SubscriptionID && stored.EventID == d.EventID
+		}) {
+			continue
+		}
+		r.nextDeliveryID++
+		d.ID = r.nextDeliveryID
+		d.CreatedAt, d.UpdatedAt = r.Now(), r.Now()
+		stored := *d
+		stored.Subscription = nil
+		r.deliveries = append(r.deliveries, stored)
+	}
+	return nil
+}
+
+func (r *FakeWebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration, fn func(deliveries []models.WebhookDelivery) error) (int, error) {
+	r.mu.Lock()
+	now := r.Now()
+	var due []models.WebhookDelivery
+	for j, d := range r.deliveries {
+		i := r.subscriptionIndex(d.SubscriptionID)
+		if d.Status != models.WebhookDeliveryPending || d.NextAttemptAt.After(now) || i < 0 || !r.subscriptions[i].Active || len(due) >= limit {
+			continue
+		}
+		r.deliveries[j].NextAttemptAt = now.Add(lease)
+		d.NextAttemptAt = r.deliveries[j].NextAttemptAt
+		sub := cloneSubscription(r.subscriptions[i])
+		d.Subscription = &sub
+		due = append(due, d)
+	}
+	r.mu.Unlock()
+	if len(due) == 0 {
+		return 0, nil
+	}
+	leases := make([]time.Time, len(due))
+	for i := range due {
+		leases[i] = due[i].NextAttemptAt
+	}
+
+	if err := fn(due); err != nil {
+		return 0, err
+	}
+	for i := range due {
+		r.mu.Lock()
+		j := slices.IndexFunc(r.deliveries, func(d models.WebhookDelivery) bool { return d.ID == due[i].ID })
+		leased := j >= 0 && r.deliveries[j].Status == models.WebhookDeliveryPending && r.deliveries[j].NextAttemptAt.Equal(leases[i])
+		r.mu.Unlock()
+		if !leased {
+			continue
+		}
+		if err := r.UpdateDelivery(ctx, &due[i]); err != nil {
+			return 0, err
+		}
+	}
+	return len(due), nil
+}
+
+func (r *FakeWebhookRepository) GetDelivery(ctx context.Context, subscriptionID, id uint) (*models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, d := range r.deliveries {
+		if d.ID == id && d.SubscriptionID == subscriptionID {
+			return &d, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *FakeWebhookRepository) ListDeliveries(ctx context.Context, subscriptionID uint, status string, before uint, limit int) ([]models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var page []models.WebhookDelivery
+	for _, d := range slices.Backward(r.deliveries) {
+		if d.SubscriptionID != subscriptionID || (status != "" && d.Status != status) || (before > 0 && d.ID >= before) {
+			continue
+		}
+		if len(page) == limit {
+			break
+		}
+		page = append(page, d)
+	}
+	return page, nil
+}
+
+func (r *FakeWebhookRepository) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := slices.IndexFunc(r.deliveries, func(d models.WebhookDelivery) bool { return d.ID == delivery.ID })
+	if i < 0 {
+		return nil
+	}
+	delivery.UpdatedAt = r.Now()
+	stored := &r.deliveries[i]
+	stored.Status = delivery.Status
+	stored.Attempts = delivery.Attempts
+	stored.NextAttemptAt = delivery.NextAttemptAt
+	stored.LastAttemptAt = delivery.LastAttemptAt
+	stored.ResponseStatus = delivery.ResponseStatus
+	stored.LastError = delivery.LastError
+	stored.UpdatedAt = delivery.UpdatedAt
+	return nil
+}
+
+func (r *FakeWebhookRepository) DeleteFinishedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	before := len(r.deliveries)
+	r.deliveries = slices.DeleteFunc(r.deliveries, func(d models.WebhookDelivery) bool {
+		return d.Status != models.WebhookDeliveryPending && d.UpdatedAt.Before(cutoff)
+	})
+	return int64(before - len(r.deliveries)), nil
+}
+
+// Deliveries returns every stored delivery, oldest first.
+func (r *FakeWebhookRepository) Deliveries() []models.WebhookDelivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.deliveries)
+}
+
+func (r *FakeWebhookRepository) subscriptionIndex(id uint) int {
+	return slices.IndexFunc(r.subscriptions, func(s models.WebhookSubscription) bool { return s.ID == id })
+}
+
+func cloneSubscription(sub models.WebhookSubscription) models.WebhookSubscription {
+	sub.EventTypes = slices.Clone(sub.EventTypes)
+	return sub
+}
@@ -1,12 +1,18 @@
 package testutils
 
 import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/api/middleware"
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/config"
-	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/services" // New import
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/auth"
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/database"
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/logger"
 	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/persistence" // New import
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/webhooks"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
@@ -22,6 +28,25 @@ func getTestConfig() *config.Config {
 		DBPass:      "postgres",
 		DBName:      "test_db", // Ensure this DB exists or can be created by the user
 		LogLevel:    "debug",
+
+		JWTAccessSecret:  "test-access-secret",
+		JWTRefreshSecret: "test-refresh-secret",
+		JWTIssuer:        "lean-backend-boilerplate-test",
+		JWTAccessTTL:     15 * time.Minute,
+		JWTRefreshTTL:    time.Hour,
+
+		EmailVerificationSecret: "test-email-secret",
+		EmailVerificationTTL:    time.Hour,
+		PasswordResetSecret:     "test-reset-secret",
+		PasswordResetTTL:        time.Hour,
+
+		// Cheap hashing parameters keep tests fast
+		PasswordHashAlgorithm:     "argon2id",
+		PasswordArgon2Memory:      64,
+		PasswordArgon2Iterations:  1,
+		PasswordArgon2Parallelism: 1,
+		PasswordBcryptCost:        4,
+		PasswordHashConcurrency:   4,
 	}
 }
 
@@ -32,6 +57,10 @@ func SetupTestRouter(needsDB bool) (*gin.Engine, services.UserService, *gorm.DB)
 
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		panic("Invalid test trusted proxies: " + err.Error())
+	}
+	r.Use(middleware.Errors())
 
 	var db *gorm.DB
 	var userService services.UserService
@@ -43,27 +72,113 @@ func SetupTestRouter(needsDB bool) (*gin.Engine, services.UserService, *gorm.DB)
 			panic("Failed to connect to test database: " + err.Error())
 		}
 
-		// Auto-migrate the database schema for tests
-		// Consider if this should be cleared before each test suite or run
-		if err := db.AutoMigrate(&models.User{}); err != nil {
+		// Bring the test database schema up to date with the migrations/ directory
+		migrator, err := database.NewMigratorForDB(db)
+		if err != nil {
+			panic("Failed to load migrations: " + err.Error())
+		}
+		if _, err := migrator.Up(context.Background(), 0); err != nil {
 			panic("Failed to migrate test database: " + err.Error())
 		}
 
 		// Initialize Repository and Service
 		userRepository := persistence.NewGormUserRepository(db)
-		userService = services.NewUserService(userRepository)
+		userService = services.NewUserService(userRepository, NewTestPasswords())
 	}
 
 	return r, userService, db
 }
 
+// SetupTestAuth returns an AuthService and the JWTManager it signs tokens with,
+// so tests can both log in through the API and mint tokens directly.
+func SetupTestAuth(db *gorm.DB) (services.AuthService, *auth.JWTManager) {
+	tokens := NewTestJWTManager()
+	authService := services.NewAuthService(
+		persistence.NewGormUserRepository(db),
+		persistence.NewGormRefreshTokenRepository(db),
+		tokens,
+		NewTestPasswordHasher(),
+	)
+	return authService, tokens
+}
+
+// NewTestJWTManager returns a JWTManager using the test secrets.
+func NewTestJWTManager() *auth.JWTManager {
+	return auth.NewJWTManager(getTestConfig())
+}
+
+// NewTestPasswordHasher returns a PasswordHasher with cheap test parameters.
+func NewTestPasswordHasher() *auth.PasswordHasher {
+	hasher, err := auth.NewPasswordHasher(getTestConfig())
+	if err != nil {
+		panic("Invalid test password hashing configuration: " + err.Error())
+	}
+	return hasher
+}
+
+// NewTestPasswords returns the password settings services use in tests:
+// the test hasher and the default policy.
+func NewTestPasswords() services.Passwords {
+	return services.Passwords{Hasher: NewTestPasswordHasher()}
+}
+
+// NewTestWebhookAddresses returns the webhook address policy for tests,
+// which also permits loopback so httptest receivers can be reached.
+func NewTestWebhookAddresses() *webhooks.AddressPolicy {
+	addresses, err := webhooks.NewAddressPolicy([]string{"127.0.0.0/8", "::1"})
+	if err != nil {
+		panic("Invalid test webhook networks: " + err.Error())
+	}
+	return addresses
+}
+
+// SetupTestRoles returns a RoleService backed by the test database.
+// Built-in roles are seeded by the migrations.
+func SetupTestRoles(db *gorm.DB) services.RoleService {
+	return services.NewRoleService(
+		persistence.NewGormRoleRepository(db),
+		persistence.NewGormUserRepository(db),
+	)
+}
+
+// cleanupTables are the tables CleanupDatabase empties. Roles and
+// permissions are seeded by the migrations and stay; user_roles goes with
+// users through CASCADE.
+var cleanupTables = []string{
+	"users",
+	"refresh_tokens",
+	"rate_limit_buckets",
+	"idempotency_keys",
+	"outbox_events",
+	"webhook_deliveries",
+	"webhook_subscriptions",
+	"jobs",
+	"email_verification_tokens",
+	"password_reset_tokens",
+}
+
 // CleanupDatabase cleans up test data from specified tables.
 // It is crucial to ensure tests are independent.
 func CleanupDatabase(db *gorm.DB) {
-	// Add other tables here if necessary
-	if err := db.Exec("TRUNCATE TABLE users RESTART IDENTITY CASCADE").Error; err != nil {
-        // If TRUNCATE fails (e.g. due to permissions or table locks), fallback or log error
-        // Fallback to DELETE for simplicity if TRUNCATE causes issues in some environments
-        db.Exec("DELETE FROM users")
-    }
+	if err := db.Exec("TRUNCATE TABLE " + strings.Join(cleanupTables, ", ") + " RESTART IDENTITY CASCADE").Error; err != nil {
+		panic("Failed to clean up test database: " + err.Error())
+	}
+	TruncateAuditLog(db)
+}
+
+// TruncateAuditLog empties audit_log. The table is append-only; its
+// truncate trigger is switched off only inside this transaction.
+func TruncateAuditLog(db *gorm.DB) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("ALTER TABLE audit_log DISABLE TRIGGER audit_log_no_truncate").Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("TRUNCATE TABLE audit_log RESTART IDENTITY").Error; err != nil {
+			return err
+		}
+		return tx.Exec("ALTER TABLE audit_log ENABLE TRIGGER audit_log_no_truncate").Error
+	})
+	if err != nil {
+		panic("Failed to truncate the audit log: " + err.Error())
+	}
 }
@@ -0,0 +1,190 @@
+package webhooks_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/domain/models"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/internal/infrastructure/webhooks"
+	"github.com/abhi9s-realm/lean-backend-boilerplate-golang/tests/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const secret = "whsec_test-secret-value"
+
+func signedHeader(secret string, at time.Time, body []byte) http.Header {
+	h := http.Header{}
+	h.Set(webhooks.HeaderTimestamp, strconv.FormatInt(at.Unix(), 10))
+	h.Set(webhooks.HeaderSignature, webhooks.Sign(secret, at, body))
+	return h
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":"1","type":"user.created"}`)
+	now := time.Unix(1714564800, 0)
+	tolerance := 5 * time.Minute
+
+	t.Run("Valid Signature", func(t *testing.T) {
+		assert.NoError(t, webhooks.Verify(secret, signedHeader(secret, now, body), body, tolerance, now))
+	})
+
+	t.Run("Modified Body", func(t *testing.T) {
+		err := webhooks.Verify(secret, signedHeader(secret, now, body), []byte(`{"id":"2"}`), tolerance, now)
+		assert.ErrorIs(t, err, webhooks.ErrInvalidSignature)
+	})
+
+	t.Run("Wrong Secret", func(t *testing.T) {
+		err := webhooks.Verify(secret, signedHeader("whsec_other", now, body), body, tolerance, now)
+		assert.ErrorIs(t, err, webhooks.ErrInvalidSignature)
+	})
+
+	t.Run("Replayed Later", func(t *testing.T) {
+		err := webhooks.Verify(secret, signedHeader(secret, now, body), body, tolerance, now.Add(10*time.Minute))
+		assert.ErrorIs(t, err, webhooks.ErrStaleTimestamp)
+	})
+
+	t.Run("Timestamp Swapped", func(t *testing.T) {
+		// The timestamp is signed, so moving it forward breaks the signature
+		h := signedHeader(secret, now, body)
+		h.Set(webhooks.HeaderTimestamp, strconv.FormatInt(now.Add(time.Minute).Unix(), 10))
+		err := webhooks.Verify(secret, h, body, tolerance, now)
+		assert.ErrorIs(t, err, webhooks.ErrInvalidSignature)
+	})
+
+	t.Run("One Of Several Signatures", func(t *testing.T) {
+		h := signedHeader(secret, now, body)
+		h.Set(webhooks.HeaderSignature, "v1=deadbeef v0=ignored "+h.Get(webhooks.HeaderSignature))
+		assert.NoError(t, webhooks.Verify(secret, h, body, tolerance, now))
+	})
+
+	t.Run("Missing Headers", func(t *testing.T) {
+		err := webhooks.Verify(secret, http.Header{}, body, tolerance, now)
+		assert.ErrorIs(t, err, webhooks.ErrMissingHeaders)
+	})
+}
+
+func TestNewSecret(t *testing.T) {
+	a, err := webhooks.NewSecret()
+	require.NoError(t, err)
+	b, err := webhooks.NewSecret()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(a, "whsec_"))
+	assert.NotEqual(t, a, b)
+}
+
+func delivery(url string) *models.WebhookDelivery {
+	return &models.WebhookDelivery{
+		EventID:      "0190f0c2-0000-7000-8000-000000000001",
+		EventType:    "user.created",
+		Payload:      []byte(`{"id":"0190f0c2-0000-7000-8000-000000000001","type":"user.created"}`),
+		Subscription: &models.WebhookSubscription{URL: url, Secret: secret},
+	}
+}
+
+func TestSenderSignsRequests(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer receiver.Close()
+
+	d := delivery(receiver.URL + "/hooks")
+	status, err := webhooks.NewSender(time.Second, testutils.NewTestWebhookAddresses()).Send(context.Background(), d)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, status)
+
+	require.NotNil(t, received)
+	assert.Equal(t, http.MethodPost, received.Method)
+	assert.Equal(t, "/hooks", received.URL.Path)
+	assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+	assert.Equal(t, d.EventID, received.Header.Get(webhooks.HeaderID))
+	assert.Equal(t, "user.created", received.Header.Get(webhooks.HeaderEvent))
+	assert.Equal(t, string(d.Payload), string(body))
+	assert.NoError(t, webhooks.Verify(secret, received.Header, body, time.Minute, time.Now()))
+}
+
+func TestSenderFailures(t *testing.T) {
+	t.Run("Error Status", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}))
+		defer receiver.Close()
+
+		status, err := webhooks.NewSender(time.Second, testutils.NewTestWebhookAddresses()).Send(context.Background(), delivery(receiver.URL))
+		assert.Equal(t, http.StatusInternalServerError, status)
+		assert.ErrorContains(t, err, "500")
+	})
+
+	t.Run("Redirect Not Followed", func(t *testing.T) {
+		followed := false
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { followed = true }))
+		defer target.Close()
+		receiver := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+		defer receiver.Close()
+
+		status, err := webhooks.NewSender(time.Second, testutils.NewTestWebhookAddresses()).Send(context.Background(), delivery(receiver.URL))
+		assert.Equal(t, http.StatusTemporaryRedirect, status)
+		assert.Error(t, err)
+		assert.False(t, followed)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}))
+		defer receiver.Close()
+
+		status, err := webhooks.NewSender(50*time.Millisecond, testutils.NewTestWebhookAddresses()).Send(context.Background(), delivery(receiver.URL))
+		assert.Zero(t, status, "No response was received")
+		assert.Error(t, err)
+	})
+}
+
+func TestAddressPolicy(t *testing.T) {
+	publicOnly, err := webhooks.NewAddressPolicy(nil)
+	require.NoError(t, err)
+	for _, addr := range []string{
+		"127.0.0.1", "::1", "10.1.2.3", "172.16.0.1", "192.168.0.1", "169.254.169.254", "fe80::1",
+		"fd00::1", "100.64.0.1", "0.0.0.0", "::", "224.0.0.1", "255.255.255.255", "::ffff:10.0.0.1", "64:ff9b::a00:1",
+	} {
+		assert.False(t, publicOnly.Permits(netip.MustParseAddr(addr)), addr)
+	}
+	for _, addr := range []string{"93.184.215.14", "2606:2800:21f:cb07:6820:80da:af6b:8b2c", "::ffff:93.184.215.14"} {
+		assert.True(t, publicOnly.Permits(netip.MustParseAddr(addr)), addr)
+	}
+
+	dev, err := webhooks.NewAddressPolicy([]string{"127.0.0.1", "10.0.0.0/8"})
+	require.NoError(t, err)
+	assert.True(t, dev.Permits(netip.MustParseAddr("127.0.0.1")))
+	assert.False(t, dev.Permits(netip.MustParseAddr("127.0.0.2")))
+	assert.True(t, dev.Permits(netip.MustParseAddr("10.20.30.40")))
+
+	_, err = webhooks.NewAddressPolicy([]string{"not-a-network"})
+	assert.Error(t, err)
+}
+
+func TestSenderRefusesNonPublicAddresses(t *testing.T) {
+	called := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+	defer receiver.Close()
+
+	publicOnly, err := webhooks.NewAddressPolicy(nil)
+	require.NoError(t, err)
+	// localhost resolves to loopback at dial time, as a rebound name would
+	url := strings.Replace(receiver.URL, "127.0.0.1", "localhost", 1)
+	status, err := webhooks.NewSender(time.Second, publicOnly).Send(context.Background(), delivery(url))
+	assert.Zero(t, status)
+	assert.ErrorIs(t, err, webhooks.ErrForbiddenAddress)
+	assert.False(t, called)
+}